@@ -0,0 +1,2023 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/mindstand/gogm"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxBodySize limits the size of request bodies accepted by the api
+const maxBodySize = 1 << 20
+
+// errBadRequest marks errors caused by the request itself
+var errBadRequest = errors.New("bad request")
+
+// errConflict marks requests that clash with data that already exists
+var errConflict = errors.New("conflict")
+
+// errMethodNotAllowed marks requests using a method the endpoint does not allow for them
+var errMethodNotAllowed = errors.New("method not allowed")
+
+// SessionFactory opens a new session, the api uses one session per request
+type SessionFactory func() (gogm.ISession, error)
+
+// nodeRef points to another node in a response
+type nodeRef struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type departmentJSON struct {
+	UUID     string    `json:"uuid"`
+	Name     string    `json:"name"`
+	Subjects []nodeRef `json:"subjects"`
+	Teachers []nodeRef `json:"teachers"`
+}
+
+type subjectJSON struct {
+	UUID       string    `json:"uuid"`
+	Name       string    `json:"name"`
+	Department *nodeRef  `json:"department"`
+	Teachers   []nodeRef `json:"teachers"`
+	Courses    []nodeRef `json:"courses"`
+}
+
+type teacherJSON struct {
+	UUID       string    `json:"uuid"`
+	Name       string    `json:"name"`
+	Department *nodeRef  `json:"department"`
+	Subjects   []nodeRef `json:"subjects"`
+	Offerings  []nodeRef `json:"offerings"`
+}
+
+type courseJSON struct {
+	UUID          string    `json:"uuid"`
+	Name          string    `json:"name"`
+	Subject       *nodeRef  `json:"subject"`
+	Prerequisites []nodeRef `json:"prerequisites"`
+	RequiredBy    []nodeRef `json:"required_by"`
+	Offerings     []nodeRef `json:"offerings"`
+}
+
+type termJSON struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Offerings []nodeRef `json:"offerings"`
+}
+
+type offeringJSON struct {
+	UUID        string           `json:"uuid"`
+	Name        string           `json:"name"`
+	Section     string           `json:"section"`
+	Capacity    int              `json:"capacity"`
+	Course      *nodeRef         `json:"course"`
+	Term        *nodeRef         `json:"term"`
+	Teacher     *nodeRef         `json:"teacher"`
+	Meetings    []meetingJSON    `json:"meetings"`
+	Enrollments []enrollmentJSON `json:"enrollments"`
+}
+
+type studentJSON struct {
+	UUID        string                 `json:"uuid"`
+	Name        string                 `json:"name"`
+	Grades      map[string]interface{} `json:"grades,omitempty"`
+	Enrollments []enrollmentJSON       `json:"enrollments"`
+}
+
+type roomJSON struct {
+	UUID     string        `json:"uuid"`
+	Name     string        `json:"name"`
+	Meetings []meetingJSON `json:"meetings"`
+}
+
+type meetingJSON struct {
+	UUID      string   `json:"uuid"`
+	Offering  *nodeRef `json:"offering"`
+	Room      *nodeRef `json:"room"`
+	Day       string   `json:"day"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+}
+
+type enrollmentJSON struct {
+	UUID           string     `json:"uuid"`
+	Student        *nodeRef   `json:"student"`
+	Offering       *nodeRef   `json:"offering"`
+	Status         string     `json:"status"`
+	EnrolledDate   time.Time  `json:"enrolled_date"`
+	DroppedDate    *time.Time `json:"dropped_date,omitempty"`
+	WaitlistedDate *time.Time `json:"waitlisted_date,omitempty"`
+	FinalGrade     string     `json:"final_grade,omitempty"`
+	GradedDate     *time.Time `json:"graded_date,omitempty"`
+}
+
+type gradeReportJSON struct {
+	GPA    float64          `json:"gpa"`
+	Grades []enrollmentJSON `json:"grades"`
+}
+
+// nodeInput is the body of create and update requests, grades are only used for students
+type nodeInput struct {
+	Name   string                 `json:"name"`
+	Grades map[string]interface{} `json:"grades"`
+}
+
+// offeringInput is the body of POST /offerings, PUT /offerings/{id} only changes the section and capacity
+type offeringInput struct {
+	CourseID  string `json:"course_id"`
+	TermID    string `json:"term_id"`
+	TeacherID string `json:"teacher_id"`
+	Section   string `json:"section"`
+	Capacity  *int   `json:"capacity"`
+}
+
+// enrollmentInput is the body of POST /students/{id}/enrollments
+type enrollmentInput struct {
+	OfferingID   string     `json:"offering_id"`
+	EnrolledDate *time.Time `json:"enrolled_date"`
+	// Waitlist joins the waitlist of a full offering instead of failing
+	Waitlist bool `json:"waitlist"`
+}
+
+// meetingInput is the body of POST /offerings/{id}/meetings
+type meetingInput struct {
+	RoomID    string `json:"room_id"`
+	Day       string `json:"day"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// gradeInput is the body of PUT /students/{id}/enrollments/{offering id}/grade
+type gradeInput struct {
+	Grade string `json:"grade"`
+}
+
+// linkInput is the body of the PUT requests setting a single relationship
+type linkInput struct {
+	ID string `json:"id"`
+}
+
+// errorJSON is the body of every error response
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// APIServer serves the school graph over http.
+//
+//	GET, POST               /{type}
+//	GET, PUT, DELETE        /{type}/{id}
+//	GET, POST               /offerings, ?term={name}&department={name} to filter
+//	GET, PUT, DELETE        /offerings/{id}
+//	GET, POST               /students/{id}/enrollments
+//	DELETE                  /students/{id}/enrollments/{offering id}, ?withdraw=true to withdraw
+//	PUT, DELETE             /students/{id}/enrollments/{offering id}/grade
+//	GET                     /students/{id}/grades
+//	GET                     /offerings/{id}/enrollments
+//	GET                     /offerings/{id}/grades
+//	GET, POST               /offerings/{id}/meetings
+//	DELETE                  /offerings/{id}/meetings/{meeting id}
+//	PUT, DELETE             /offerings/{id}/teacher
+//	GET, POST               /courses/{id}/prerequisites
+//	DELETE                  /courses/{id}/prerequisites/{prerequisite id}
+//	PUT, DELETE             /courses/{id}/subject
+//	PUT, DELETE             /teachers/{id}/department
+//	PUT, DELETE             /subjects/{id}/department
+//	GET, POST               /graphql
+//
+// where {type} is departments, subjects, teachers, courses, students, rooms or terms
+type APIServer struct {
+	newSession SessionFactory
+	graphql    graphql.Schema
+}
+
+// NewAPIServer creates an APIServer opening its sessions with newSession
+func NewAPIServer(newSession SessionFactory) (*APIServer, error) {
+	schema, err := NewGraphQLSchema(registeredTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIServer{newSession: newSession, graphql: schema}, nil
+}
+
+// apiRequest is one request along with the session it is served with
+type apiRequest struct {
+	w     http.ResponseWriter
+	r     *http.Request
+	sess  gogm.ISession
+	repos *Repositories
+}
+
+// apiHandler serves one route, returning the status and body of a successful response
+type apiHandler func(req *apiRequest, args []string) (int, interface{}, error)
+
+// apiRoute maps the methods of one path shape to their handlers
+type apiRoute map[string]apiHandler
+
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, args := s.route(strings.Split(strings.Trim(r.URL.Path, "/"), "/"))
+	if route == nil {
+		writeJSON(w, http.StatusNotFound, &errorJSON{Error: "no such endpoint"})
+		return
+	}
+
+	handler, ok := route[r.Method]
+	if !ok {
+		var allowed []string
+		for method := range route {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, &errorJSON{Error: "method not allowed"})
+		return
+	}
+
+	sess, err := s.newSession()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer sess.Close()
+
+	status, body, err := handler(&apiRequest{w: w, r: r, sess: sess, repos: NewRepositories(sess)}, args)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	writeJSON(w, status, body)
+}
+
+// route finds the route for the path segments, returning the ids in the path as args
+func (s *APIServer) route(path []string) (apiRoute, []string) {
+	switch len(path) {
+	case 1:
+		switch path[0] {
+		case "departments":
+			return apiRoute{http.MethodGet: listDepartments, http.MethodPost: createDepartment}, nil
+		case "subjects":
+			return apiRoute{http.MethodGet: listSubjects, http.MethodPost: createSubject}, nil
+		case "teachers":
+			return apiRoute{http.MethodGet: listTeachers, http.MethodPost: createTeacher}, nil
+		case "courses":
+			return apiRoute{http.MethodGet: listCourses, http.MethodPost: createCourse}, nil
+		case "students":
+			return apiRoute{http.MethodGet: listStudents, http.MethodPost: createStudent}, nil
+		case "rooms":
+			return apiRoute{http.MethodGet: listRooms, http.MethodPost: createRoom}, nil
+		case "terms":
+			return apiRoute{http.MethodGet: listTerms, http.MethodPost: createTerm}, nil
+		case "offerings":
+			return apiRoute{http.MethodGet: listOfferings, http.MethodPost: createOffering}, nil
+		case "graphql":
+			return apiRoute{http.MethodGet: s.graphqlQuery, http.MethodPost: s.graphqlQuery}, nil
+		}
+	case 2:
+		args := path[1:]
+		switch path[0] {
+		case "departments":
+			return apiRoute{http.MethodGet: getDepartment, http.MethodPut: updateDepartment, http.MethodDelete: deleteDepartment}, args
+		case "subjects":
+			return apiRoute{http.MethodGet: getSubject, http.MethodPut: updateSubject, http.MethodDelete: deleteSubject}, args
+		case "teachers":
+			return apiRoute{http.MethodGet: getTeacher, http.MethodPut: updateTeacher, http.MethodDelete: deleteTeacher}, args
+		case "courses":
+			return apiRoute{http.MethodGet: getCourse, http.MethodPut: updateCourse, http.MethodDelete: deleteCourse}, args
+		case "students":
+			return apiRoute{http.MethodGet: getStudent, http.MethodPut: updateStudent, http.MethodDelete: deleteStudent}, args
+		case "rooms":
+			return apiRoute{http.MethodGet: getRoom, http.MethodPut: updateRoom, http.MethodDelete: deleteRoom}, args
+		case "terms":
+			return apiRoute{http.MethodGet: getTerm, http.MethodPut: updateTerm, http.MethodDelete: deleteTerm}, args
+		case "offerings":
+			return apiRoute{http.MethodGet: getOffering, http.MethodPut: updateOffering, http.MethodDelete: deleteOffering}, args
+		}
+	case 3:
+		args := path[1:2]
+		switch path[0] + "/" + path[2] {
+		case "students/enrollments":
+			return apiRoute{http.MethodGet: listStudentEnrollments, http.MethodPost: enrollStudent}, args
+		case "students/grades":
+			return apiRoute{http.MethodGet: getStudentGrades}, args
+		case "offerings/enrollments":
+			return apiRoute{http.MethodGet: listOfferingEnrollments}, args
+		case "offerings/grades":
+			return apiRoute{http.MethodGet: getOfferingGrades}, args
+		case "offerings/meetings":
+			return apiRoute{http.MethodGet: listMeetings, http.MethodPost: scheduleMeeting}, args
+		case "offerings/teacher":
+			return apiRoute{http.MethodPut: setOfferingTeacher, http.MethodDelete: unsetOfferingTeacher}, args
+		case "courses/prerequisites":
+			return apiRoute{http.MethodGet: listPrerequisites, http.MethodPost: addPrerequisite}, args
+		case "courses/subject":
+			return apiRoute{http.MethodPut: setCourseSubject, http.MethodDelete: unsetCourseSubject}, args
+		case "teachers/department":
+			return apiRoute{http.MethodPut: setTeacherDepartment, http.MethodDelete: unsetTeacherDepartment}, args
+		case "subjects/department":
+			return apiRoute{http.MethodPut: setSubjectDepartment, http.MethodDelete: unsetSubjectDepartment}, args
+		}
+	case 4:
+		if path[0] == "students" && path[2] == "enrollments" {
+			return apiRoute{http.MethodDelete: dropStudent}, []string{path[1], path[3]}
+		}
+		if path[0] == "courses" && path[2] == "prerequisites" {
+			return apiRoute{http.MethodDelete: removePrerequisite}, []string{path[1], path[3]}
+		}
+		if path[0] == "offerings" && path[2] == "meetings" {
+			return apiRoute{http.MethodDelete: unscheduleMeeting}, []string{path[1], path[3]}
+		}
+	case 5:
+		if path[0] == "students" && path[2] == "enrollments" && path[4] == "grade" {
+			return apiRoute{http.MethodPut: gradeStudent, http.MethodDelete: ungradeStudent}, []string{path[1], path[3]}
+		}
+	}
+
+	return nil, nil
+}
+
+// statusFor maps errors from the repositories and the linking functions to http status codes
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest),
+		errors.Is(err, ErrInvalid),
+		errors.Is(err, ErrInvalidGrade),
+		errors.Is(err, ErrInvalidCapacity),
+		errors.Is(err, ErrInvalidMeeting),
+		errors.Is(err, ErrNilTarget),
+		errors.Is(err, ErrNilEdge),
+		errors.Is(err, ErrNoTargets):
+		return http.StatusBadRequest
+	case errors.Is(err, gogm.ErrNotFound),
+		errors.Is(err, ErrNotLinked),
+		errors.Is(err, ErrNotEnrolled):
+		return http.StatusNotFound
+	case errors.Is(err, errConflict),
+		errors.Is(err, ErrAlreadyLinked),
+		errors.Is(err, ErrAlreadyEnrolled),
+		errors.Is(err, ErrOfferingExists),
+		errors.Is(err, ErrInvalidTransition),
+		errors.Is(err, ErrCourseFull),
+		errors.Is(err, ErrPrerequisiteCycle),
+		errors.Is(err, ErrMissingPrerequisites),
+		errors.Is(err, ErrScheduleConflict):
+		return http.StatusConflict
+	case errors.Is(err, errMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError writes err as json, internal errors are logged instead of being sent to the client
+func writeError(w http.ResponseWriter, err error) {
+	status := statusFor(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("api: %v", err)
+		writeJSON(w, status, &errorJSON{Error: http.StatusText(status)})
+		return
+	}
+
+	writeJSON(w, status, &errorJSON{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("api: failed to write response, %v", err)
+	}
+}
+
+// decode reads the json body of the request into v
+func (req *apiRequest) decode(v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(req.w, req.r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err != nil {
+		return fmt.Errorf("%w, invalid json body: %v", errBadRequest, err)
+	}
+
+	return nil
+}
+
+// decodeNode reads a create or update body, every node needs a name
+func (req *apiRequest) decodeNode() (*nodeInput, error) {
+	var in nodeInput
+	err := req.decode(&in)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(in.Name) == "" {
+		return nil, fmt.Errorf("%w, name is required", errBadRequest)
+	}
+
+	return &in, nil
+}
+
+// decodeLink reads the id of the node a single relationship is set to
+func (req *apiRequest) decodeLink() (string, error) {
+	var in linkInput
+	err := req.decode(&in)
+	if err != nil {
+		return "", err
+	}
+
+	if in.ID == "" {
+		return "", fmt.Errorf("%w, id is required", errBadRequest)
+	}
+
+	return in.ID, nil
+}
+
+// transaction runs fn in a transaction, rolling back if it fails
+func (req *apiRequest) transaction(fn func() error) error {
+	err := req.sess.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil {
+		return req.sess.RollbackWithError(err)
+	}
+
+	err = req.sess.Commit()
+	if err != nil {
+		return req.sess.RollbackWithError(err)
+	}
+
+	return nil
+}
+
+// created sets the Location header of a 201 response
+func (req *apiRequest) created(uuid string) {
+	req.w.Header().Set("Location", strings.TrimSuffix(req.r.URL.Path, "/")+"/"+uuid)
+}
+
+func refOf(uuid, name string) nodeRef {
+	return nodeRef{UUID: uuid, Name: name}
+}
+
+func departmentRef(department *Department) *nodeRef {
+	if department == nil {
+		return nil
+	}
+	ref := refOf(department.UUID, department.Name)
+	return &ref
+}
+
+func subjectRef(subject *Subject) *nodeRef {
+	if subject == nil {
+		return nil
+	}
+	ref := refOf(subject.UUID, subject.Name)
+	return &ref
+}
+
+func teacherRef(teacher *Teacher) *nodeRef {
+	if teacher == nil {
+		return nil
+	}
+	ref := refOf(teacher.UUID, teacher.Name)
+	return &ref
+}
+
+func courseRef(course *Course) *nodeRef {
+	if course == nil {
+		return nil
+	}
+	ref := refOf(course.UUID, course.Name)
+	return &ref
+}
+
+func termRef(term *Term) *nodeRef {
+	if term == nil {
+		return nil
+	}
+	ref := refOf(term.UUID, term.Name)
+	return &ref
+}
+
+func offeringRef(offering *CourseOffering) *nodeRef {
+	if offering == nil {
+		return nil
+	}
+	ref := refOf(offering.UUID, offering.Name)
+	return &ref
+}
+
+func roomRef(room *Room) *nodeRef {
+	if room == nil {
+		return nil
+	}
+	ref := refOf(room.UUID, room.Name)
+	return &ref
+}
+
+func studentRef(student *Student) *nodeRef {
+	if student == nil {
+		return nil
+	}
+	ref := refOf(student.UUID, student.Name)
+	return &ref
+}
+
+func toDepartmentJSON(department *Department) *departmentJSON {
+	out := &departmentJSON{UUID: department.UUID, Name: department.Name, Subjects: []nodeRef{}, Teachers: []nodeRef{}}
+	for _, subject := range department.Subjects {
+		out.Subjects = append(out.Subjects, refOf(subject.UUID, subject.Name))
+	}
+	for _, teacher := range department.Teachers {
+		out.Teachers = append(out.Teachers, refOf(teacher.UUID, teacher.Name))
+	}
+	return out
+}
+
+func toSubjectJSON(subject *Subject) *subjectJSON {
+	out := &subjectJSON{UUID: subject.UUID, Name: subject.Name, Department: departmentRef(subject.Department), Teachers: []nodeRef{}, Courses: []nodeRef{}}
+	for _, teacher := range subject.Teachers {
+		out.Teachers = append(out.Teachers, refOf(teacher.UUID, teacher.Name))
+	}
+	for _, course := range subject.Courses {
+		out.Courses = append(out.Courses, refOf(course.UUID, course.Name))
+	}
+	return out
+}
+
+func toTeacherJSON(teacher *Teacher) *teacherJSON {
+	out := &teacherJSON{UUID: teacher.UUID, Name: teacher.Name, Department: departmentRef(teacher.Department), Subjects: []nodeRef{}, Offerings: []nodeRef{}}
+	for _, subject := range teacher.Subjects {
+		out.Subjects = append(out.Subjects, refOf(subject.UUID, subject.Name))
+	}
+	for _, offering := range teacher.Offerings {
+		out.Offerings = append(out.Offerings, refOf(offering.UUID, offering.Name))
+	}
+	return out
+}
+
+func toCourseJSON(course *Course) *courseJSON {
+	out := &courseJSON{
+		UUID:          course.UUID,
+		Name:          course.Name,
+		Subject:       subjectRef(course.Subject),
+		Prerequisites: []nodeRef{},
+		RequiredBy:    []nodeRef{},
+		Offerings:     []nodeRef{},
+	}
+	for _, prerequisite := range course.Prerequisites {
+		out.Prerequisites = append(out.Prerequisites, refOf(prerequisite.UUID, prerequisite.Name))
+	}
+	for _, requiredBy := range course.RequiredBy {
+		out.RequiredBy = append(out.RequiredBy, refOf(requiredBy.UUID, requiredBy.Name))
+	}
+	for _, offering := range course.Offerings {
+		out.Offerings = append(out.Offerings, refOf(offering.UUID, offering.Name))
+	}
+	return out
+}
+
+func toTermJSON(term *Term) *termJSON {
+	out := &termJSON{UUID: term.UUID, Name: term.Name, Offerings: []nodeRef{}}
+	for _, offering := range term.Offerings {
+		out.Offerings = append(out.Offerings, refOf(offering.UUID, offering.Name))
+	}
+	return out
+}
+
+func toOfferingJSON(offering *CourseOffering) *offeringJSON {
+	return &offeringJSON{
+		UUID:        offering.UUID,
+		Name:        offering.Name,
+		Section:     offering.Section,
+		Capacity:    offering.Capacity,
+		Course:      courseRef(offering.Course),
+		Term:        termRef(offering.Term),
+		Teacher:     teacherRef(offering.Teacher),
+		Meetings:    toMeetingsJSON(offering.Meetings),
+		Enrollments: toEnrollmentsJSON(offering.Enrollments),
+	}
+}
+
+func toStudentJSON(student *Student) *studentJSON {
+	return &studentJSON{
+		UUID:        student.UUID,
+		Name:        student.Name,
+		Grades:      student.Grades,
+		Enrollments: toEnrollmentsJSON(student.Enrollments),
+	}
+}
+
+func toRoomJSON(room *Room) *roomJSON {
+	return &roomJSON{UUID: room.UUID, Name: room.Name, Meetings: toMeetingsJSON(room.Meetings)}
+}
+
+func toMeetingsJSON(meetings []*Meeting) []meetingJSON {
+	out := []meetingJSON{}
+	for _, meeting := range meetings {
+		out = append(out, meetingJSON{
+			UUID:      meeting.UUID,
+			Offering:  offeringRef(meeting.Start),
+			Room:      roomRef(meeting.End),
+			Day:       meeting.Day,
+			StartTime: meeting.StartTime,
+			EndTime:   meeting.EndTime,
+		})
+	}
+	return out
+}
+
+func toEnrollmentJSON(enrollment *Enrollment) enrollmentJSON {
+	out := enrollmentJSON{
+		UUID:         enrollment.UUID,
+		Student:      studentRef(enrollment.Start),
+		Offering:     offeringRef(enrollment.End),
+		Status:       enrollment.CurrentStatus(),
+		EnrolledDate: enrollment.EnrolledDate,
+		FinalGrade:   enrollment.FinalGrade,
+	}
+
+	if !enrollment.DroppedDate.IsZero() {
+		out.DroppedDate = &enrollment.DroppedDate
+	}
+
+	if !enrollment.WaitlistedDate.IsZero() {
+		out.WaitlistedDate = &enrollment.WaitlistedDate
+	}
+
+	if !enrollment.GradedDate.IsZero() {
+		out.GradedDate = &enrollment.GradedDate
+	}
+
+	return out
+}
+
+func toEnrollmentsJSON(enrollments []*Enrollment) []enrollmentJSON {
+	out := []enrollmentJSON{}
+	for _, enrollment := range enrollments {
+		out = append(out, toEnrollmentJSON(enrollment))
+	}
+	return out
+}
+
+// departments
+
+func listDepartments(req *apiRequest, _ []string) (int, interface{}, error) {
+	departments, err := req.repos.Departments.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*departmentJSON{}
+	for _, department := range departments {
+		out = append(out, toDepartmentJSON(department))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	department, err := req.repos.Departments.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toDepartmentJSON(department), nil
+}
+
+func createDepartment(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	department := &Department{Name: in.Name}
+	err = req.transaction(func() error {
+		return req.repos.Departments.Save(department)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(department.UUID)
+	return http.StatusCreated, toDepartmentJSON(department), nil
+}
+
+func updateDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var department *Department
+	err = req.transaction(func() error {
+		department, err = req.repos.Departments.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		department.Name = in.Name
+		return req.repos.Departments.Save(department)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toDepartmentJSON(department), nil
+}
+
+func deleteDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		department, err := req.repos.Departments.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Departments.Delete(department)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// subjects
+
+func listSubjects(req *apiRequest, _ []string) (int, interface{}, error) {
+	subjects, err := req.repos.Subjects.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*subjectJSON{}
+	for _, subject := range subjects {
+		out = append(out, toSubjectJSON(subject))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getSubject(req *apiRequest, args []string) (int, interface{}, error) {
+	subject, err := req.repos.Subjects.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toSubjectJSON(subject), nil
+}
+
+func createSubject(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	subject := &Subject{Name: in.Name}
+	err = req.transaction(func() error {
+		return req.repos.Subjects.Save(subject)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(subject.UUID)
+	return http.StatusCreated, toSubjectJSON(subject), nil
+}
+
+func updateSubject(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var subject *Subject
+	err = req.transaction(func() error {
+		subject, err = req.repos.Subjects.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		subject.Name = in.Name
+		return req.repos.Subjects.Save(subject)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toSubjectJSON(subject), nil
+}
+
+func deleteSubject(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		subject, err := req.repos.Subjects.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Subjects.Delete(subject)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// teachers
+
+func listTeachers(req *apiRequest, _ []string) (int, interface{}, error) {
+	teachers, err := req.repos.Teachers.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*teacherJSON{}
+	for _, teacher := range teachers {
+		out = append(out, toTeacherJSON(teacher))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getTeacher(req *apiRequest, args []string) (int, interface{}, error) {
+	teacher, err := req.repos.Teachers.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toTeacherJSON(teacher), nil
+}
+
+// checkTeacherName makes sure no other teacher is called name, teacher names are unique
+func checkTeacherName(req *apiRequest, name, uuid string) error {
+	existing, err := req.repos.Teachers.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.UUID != uuid {
+		return fmt.Errorf("%w, there already is a teacher called %s", errConflict, name)
+	}
+
+	return nil
+}
+
+func createTeacher(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	teacher := &Teacher{Name: in.Name}
+	err = req.transaction(func() error {
+		err := checkTeacherName(req, in.Name, "")
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Teachers.Save(teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(teacher.UUID)
+	return http.StatusCreated, toTeacherJSON(teacher), nil
+}
+
+func updateTeacher(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var teacher *Teacher
+	err = req.transaction(func() error {
+		teacher, err = req.repos.Teachers.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		err = checkTeacherName(req, in.Name, teacher.UUID)
+		if err != nil {
+			return err
+		}
+
+		teacher.Name = in.Name
+		return req.repos.Teachers.Save(teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toTeacherJSON(teacher), nil
+}
+
+func deleteTeacher(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		teacher, err := req.repos.Teachers.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Teachers.Delete(teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// courses
+
+func listCourses(req *apiRequest, _ []string) (int, interface{}, error) {
+	courses, err := req.repos.Courses.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*courseJSON{}
+	for _, course := range courses {
+		out = append(out, toCourseJSON(course))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getCourse(req *apiRequest, args []string) (int, interface{}, error) {
+	course, err := req.repos.Courses.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toCourseJSON(course), nil
+}
+
+func createCourse(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	course := &Course{Name: in.Name}
+	err = req.transaction(func() error {
+		return req.repos.Courses.Save(course)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(course.UUID)
+	return http.StatusCreated, toCourseJSON(course), nil
+}
+
+func updateCourse(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var course *Course
+	err = req.transaction(func() error {
+		course, err = req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		course.Name = in.Name
+		err = req.repos.Courses.Save(course)
+		if err != nil {
+			return err
+		}
+
+		// the offerings are named after the course
+		return NewRegistrar(req.sess).renameOfferings(course.Offerings)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toCourseJSON(course), nil
+}
+
+func deleteCourse(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		course, err := req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Courses.Delete(course)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// students
+
+func listStudents(req *apiRequest, _ []string) (int, interface{}, error) {
+	students, err := req.repos.Students.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*studentJSON{}
+	for _, student := range students {
+		out = append(out, toStudentJSON(student))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	student, err := req.repos.Students.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toStudentJSON(student), nil
+}
+
+// checkStudentName makes sure no other student is called name, student names are unique
+func checkStudentName(req *apiRequest, name, uuid string) error {
+	existing, err := req.repos.Students.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.UUID != uuid {
+		return fmt.Errorf("%w, there already is a student called %s", errConflict, name)
+	}
+
+	return nil
+}
+
+func createStudent(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	student := &Student{Name: in.Name, Grades: in.Grades}
+	err = req.transaction(func() error {
+		err := checkStudentName(req, in.Name, "")
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Students.Save(student)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(student.UUID)
+	return http.StatusCreated, toStudentJSON(student), nil
+}
+
+func updateStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var student *Student
+	err = req.transaction(func() error {
+		student, err = req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		err = checkStudentName(req, in.Name, student.UUID)
+		if err != nil {
+			return err
+		}
+
+		student.Name = in.Name
+		student.Grades = in.Grades
+		return req.repos.Students.Save(student)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toStudentJSON(student), nil
+}
+
+func deleteStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		student, err := req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Students.Delete(student)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// rooms
+
+func listRooms(req *apiRequest, _ []string) (int, interface{}, error) {
+	rooms, err := req.repos.Rooms.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*roomJSON{}
+	for _, room := range rooms {
+		out = append(out, toRoomJSON(room))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getRoom(req *apiRequest, args []string) (int, interface{}, error) {
+	room, err := req.repos.Rooms.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toRoomJSON(room), nil
+}
+
+// checkRoomName makes sure no other room is called name, room names are unique
+func checkRoomName(req *apiRequest, name, uuid string) error {
+	existing, err := req.repos.Rooms.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.UUID != uuid {
+		return fmt.Errorf("%w, there already is a room called %s", errConflict, name)
+	}
+
+	return nil
+}
+
+func createRoom(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	room := &Room{Name: in.Name}
+	err = req.transaction(func() error {
+		err := checkRoomName(req, in.Name, "")
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Rooms.Save(room)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(room.UUID)
+	return http.StatusCreated, toRoomJSON(room), nil
+}
+
+func updateRoom(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var room *Room
+	err = req.transaction(func() error {
+		room, err = req.repos.Rooms.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		err = checkRoomName(req, in.Name, room.UUID)
+		if err != nil {
+			return err
+		}
+
+		room.Name = in.Name
+		return req.repos.Rooms.Save(room)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toRoomJSON(room), nil
+}
+
+func deleteRoom(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		room, err := req.repos.Rooms.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Rooms.Delete(room)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// terms
+
+func listTerms(req *apiRequest, _ []string) (int, interface{}, error) {
+	terms, err := req.repos.Terms.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*termJSON{}
+	for _, term := range terms {
+		out = append(out, toTermJSON(term))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getTerm(req *apiRequest, args []string) (int, interface{}, error) {
+	term, err := req.repos.Terms.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toTermJSON(term), nil
+}
+
+// checkTermName makes sure no other term is called name, term names are unique
+func checkTermName(req *apiRequest, name, uuid string) error {
+	existing, err := req.repos.Terms.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.UUID != uuid {
+		return fmt.Errorf("%w, there already is a term called %s", errConflict, name)
+	}
+
+	return nil
+}
+
+func createTerm(req *apiRequest, _ []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	term := &Term{Name: in.Name}
+	err = req.transaction(func() error {
+		err := checkTermName(req, in.Name, "")
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Terms.Save(term)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(term.UUID)
+	return http.StatusCreated, toTermJSON(term), nil
+}
+
+func updateTerm(req *apiRequest, args []string) (int, interface{}, error) {
+	in, err := req.decodeNode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var term *Term
+	err = req.transaction(func() error {
+		term, err = req.repos.Terms.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		err = checkTermName(req, in.Name, term.UUID)
+		if err != nil {
+			return err
+		}
+
+		term.Name = in.Name
+		err = req.repos.Terms.Save(term)
+		if err != nil {
+			return err
+		}
+
+		// the offerings are named after the term
+		return NewRegistrar(req.sess).renameOfferings(term.Offerings)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toTermJSON(term), nil
+}
+
+func deleteTerm(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		term, err := req.repos.Terms.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Terms.Delete(term)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// offerings
+
+func listOfferings(req *apiRequest, _ []string) (int, interface{}, error) {
+	query := req.r.URL.Query()
+
+	var offerings []*CourseOffering
+	var err error
+	if query.Get("term") != "" || query.Get("department") != "" {
+		offerings, err = req.repos.Offerings.Search(query.Get("term"), query.Get("department"))
+	} else {
+		offerings, err = req.repos.Offerings.List()
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out := []*offeringJSON{}
+	for _, offering := range offerings {
+		out = append(out, toOfferingJSON(offering))
+	}
+	return http.StatusOK, out, nil
+}
+
+func getOffering(req *apiRequest, args []string) (int, interface{}, error) {
+	offering, err := req.repos.Offerings.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toOfferingJSON(offering), nil
+}
+
+func createOffering(req *apiRequest, _ []string) (int, interface{}, error) {
+	var in offeringInput
+	err := req.decode(&in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if in.CourseID == "" || in.TermID == "" {
+		return 0, nil, fmt.Errorf("%w, course_id and term_id are required", errBadRequest)
+	}
+
+	capacity := 0
+	if in.Capacity != nil {
+		capacity = *in.Capacity
+	}
+
+	var offering *CourseOffering
+	err = req.transaction(func() error {
+		course, err := req.repos.Courses.Get(in.CourseID)
+		if err != nil {
+			return err
+		}
+
+		term, err := req.repos.Terms.Get(in.TermID)
+		if err != nil {
+			return err
+		}
+
+		var teacher *Teacher
+		if in.TeacherID != "" {
+			teacher, err = req.repos.Teachers.Get(in.TeacherID)
+			if err != nil {
+				return err
+			}
+		}
+
+		offering, err = NewRegistrar(req.sess).Offer(course, term, in.Section, teacher, capacity)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(offering.UUID)
+	return http.StatusCreated, toOfferingJSON(offering), nil
+}
+
+func updateOffering(req *apiRequest, args []string) (int, interface{}, error) {
+	var in offeringInput
+	err := req.decode(&in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if in.CourseID != "" || in.TermID != "" || in.TeacherID != "" {
+		return 0, nil, fmt.Errorf("%w, only the section and capacity of an offering can be changed", errBadRequest)
+	}
+
+	var offering *CourseOffering
+	err = req.transaction(func() error {
+		offering, err = req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if section := strings.TrimSpace(in.Section); section != "" && section != offering.Section {
+			err = renameSection(req, offering, section)
+			if err != nil {
+				return err
+			}
+		}
+
+		if in.Capacity == nil {
+			return req.repos.Offerings.Save(offering)
+		}
+
+		// more seats enroll students from the waitlist
+		_, err = NewRegistrar(req.sess).SetCapacity(offering, *in.Capacity, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toOfferingJSON(offering), nil
+}
+
+// renameSection moves offering to section, unless its term already has that section of the course
+func renameSection(req *apiRequest, offering *CourseOffering, section string) error {
+	if offering.Course == nil || offering.Term == nil {
+		return fmt.Errorf("%w, %s has no course or term", errConflict, offering.Name)
+	}
+
+	term, err := req.repos.Terms.Get(offering.Term.UUID)
+	if err != nil {
+		return err
+	}
+
+	name := offeringName(offering.Course, offering.Term, section)
+	for _, existing := range term.Offerings {
+		if existing.Name == name {
+			return fmt.Errorf("%w, %s", ErrOfferingExists, name)
+		}
+	}
+
+	offering.Section = section
+	offering.Name = name
+	return nil
+}
+
+func deleteOffering(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		offering, err := req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Offerings.Delete(offering)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// relationships
+
+func listStudentEnrollments(req *apiRequest, args []string) (int, interface{}, error) {
+	student, err := req.repos.Students.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toEnrollmentsJSON(student.Enrollments), nil
+}
+
+func listOfferingEnrollments(req *apiRequest, args []string) (int, interface{}, error) {
+	offering, err := req.repos.Offerings.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toEnrollmentsJSON(offering.Enrollments), nil
+}
+
+func enrollStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	var in enrollmentInput
+	err := req.decode(&in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if in.OfferingID == "" {
+		return 0, nil, fmt.Errorf("%w, offering_id is required", errBadRequest)
+	}
+
+	enrollment := NewEnrollment(time.Now().UTC())
+	if in.EnrolledDate != nil {
+		enrollment.EnrolledDate = in.EnrolledDate.UTC()
+	}
+
+	err = req.transaction(func() error {
+		student, err := req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		offering, err := req.repos.Offerings.Get(in.OfferingID)
+		if err != nil {
+			return err
+		}
+
+		registrar := NewRegistrar(req.sess)
+		registrar.Waitlist = in.Waitlist
+		return registrar.Enroll(student, offering, enrollment)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(in.OfferingID)
+	return http.StatusCreated, toEnrollmentJSON(enrollment), nil
+}
+
+func dropStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	withdraw := req.r.URL.Query().Get("withdraw") == "true"
+
+	var enrollment *Enrollment
+	err := req.transaction(func() error {
+		student, err := req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		offering, err := req.repos.Offerings.Get(args[1])
+		if err != nil {
+			return err
+		}
+
+		enrollment, err = NewRegistrar(req.sess).Drop(student, offering, time.Now().UTC(), withdraw)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toEnrollmentJSON(enrollment), nil
+}
+
+func getStudentGrades(req *apiRequest, args []string) (int, interface{}, error) {
+	student, err := req.repos.Students.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toGradeReportJSON(StudentReport(student)), nil
+}
+
+func getOfferingGrades(req *apiRequest, args []string) (int, interface{}, error) {
+	offering, err := req.repos.Offerings.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toGradeReportJSON(OfferingReport(offering)), nil
+}
+
+func toGradeReportJSON(report *GradeReport) *gradeReportJSON {
+	return &gradeReportJSON{GPA: report.GPA, Grades: toEnrollmentsJSON(report.Grades)}
+}
+
+func gradeStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	var in gradeInput
+	err := req.decode(&in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if in.Grade == "" {
+		return 0, nil, fmt.Errorf("%w, grade is required", errBadRequest)
+	}
+
+	var enrollment *Enrollment
+	err = req.transaction(func() error {
+		student, err := req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		offering, err := req.repos.Offerings.Get(args[1])
+		if err != nil {
+			return err
+		}
+
+		enrollment, err = NewGradeBook(req.sess).Record(student, offering, in.Grade)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toEnrollmentJSON(enrollment), nil
+}
+
+func ungradeStudent(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		student, err := req.repos.Students.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		offering, err := req.repos.Offerings.Get(args[1])
+		if err != nil {
+			return err
+		}
+
+		_, err = NewGradeBook(req.sess).Clear(student, offering)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func listPrerequisites(req *apiRequest, args []string) (int, interface{}, error) {
+	course, err := req.repos.Courses.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toCourseJSON(course).Prerequisites, nil
+}
+
+func addPrerequisite(req *apiRequest, args []string) (int, interface{}, error) {
+	prerequisiteID, err := req.decodeLink()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var course *Course
+	err = req.transaction(func() error {
+		course, err = req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		prerequisite, err := req.repos.Courses.Get(prerequisiteID)
+		if err != nil {
+			return err
+		}
+
+		return NewRegistrar(req.sess).AddPrerequisite(course, prerequisite)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(prerequisiteID)
+	return http.StatusCreated, toCourseJSON(course), nil
+}
+
+func removePrerequisite(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		course, err := req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		prerequisite, err := req.repos.Courses.Get(args[1])
+		if err != nil {
+			return err
+		}
+
+		return NewRegistrar(req.sess).RemovePrerequisite(course, prerequisite)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func listMeetings(req *apiRequest, args []string) (int, interface{}, error) {
+	offering, err := req.repos.Offerings.Get(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toMeetingsJSON(offering.Meetings), nil
+}
+
+func scheduleMeeting(req *apiRequest, args []string) (int, interface{}, error) {
+	var in meetingInput
+	err := req.decode(&in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if in.RoomID == "" {
+		return 0, nil, fmt.Errorf("%w, room_id is required", errBadRequest)
+	}
+
+	meeting, err := NewMeeting(in.Day, in.StartTime, in.EndTime)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = req.transaction(func() error {
+		offering, err := req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		room, err := req.repos.Rooms.Get(in.RoomID)
+		if err != nil {
+			return err
+		}
+
+		return NewRegistrar(req.sess).Schedule(offering, room, meeting)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.created(meeting.UUID)
+	return http.StatusCreated, toMeetingsJSON([]*Meeting{meeting})[0], nil
+}
+
+func unscheduleMeeting(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		offering, err := req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		return NewRegistrar(req.sess).Unschedule(offering, &Meeting{BaseNode: gogm.BaseNode{UUID: args[1]}})
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func setOfferingTeacher(req *apiRequest, args []string) (int, interface{}, error) {
+	teacherID, err := req.decodeLink()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var offering *CourseOffering
+	err = req.transaction(func() error {
+		offering, err = req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		teacher, err := req.repos.Teachers.Get(teacherID)
+		if err != nil {
+			return err
+		}
+
+		return NewRegistrar(req.sess).AssignTeacher(offering, teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toOfferingJSON(offering), nil
+}
+
+func unsetOfferingTeacher(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		offering, err := req.repos.Offerings.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if offering.Teacher == nil {
+			return fmt.Errorf("offering %s has no teacher, %w", offering.Name, ErrNotLinked)
+		}
+
+		err = offering.UnlinkFromTeacherOnFieldTeacher(offering.Teacher)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Offerings.Save(offering)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func setCourseSubject(req *apiRequest, args []string) (int, interface{}, error) {
+	subjectID, err := req.decodeLink()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var course *Course
+	err = req.transaction(func() error {
+		course, err = req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		subject, err := req.repos.Subjects.Get(subjectID)
+		if err != nil {
+			return err
+		}
+
+		err = course.LinkToSubjectOnFieldSubject(subject)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Courses.Save(course)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toCourseJSON(course), nil
+}
+
+func unsetCourseSubject(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		course, err := req.repos.Courses.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if course.Subject == nil {
+			return fmt.Errorf("course %s has no subject, %w", course.Name, ErrNotLinked)
+		}
+
+		err = course.UnlinkFromSubjectOnFieldSubject(course.Subject)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Courses.Save(course)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func setTeacherDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	departmentID, err := req.decodeLink()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var teacher *Teacher
+	err = req.transaction(func() error {
+		teacher, err = req.repos.Teachers.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		department, err := req.repos.Departments.Get(departmentID)
+		if err != nil {
+			return err
+		}
+
+		err = teacher.LinkToDepartmentOnFieldDepartment(department)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Teachers.Save(teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toTeacherJSON(teacher), nil
+}
+
+func unsetTeacherDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		teacher, err := req.repos.Teachers.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if teacher.Department == nil {
+			return fmt.Errorf("teacher %s has no department, %w", teacher.Name, ErrNotLinked)
+		}
+
+		err = teacher.UnlinkFromDepartmentOnFieldDepartment(teacher.Department)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Teachers.Save(teacher)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func setSubjectDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	departmentID, err := req.decodeLink()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var subject *Subject
+	err = req.transaction(func() error {
+		subject, err = req.repos.Subjects.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		department, err := req.repos.Departments.Get(departmentID)
+		if err != nil {
+			return err
+		}
+
+		err = subject.LinkToDepartmentOnFieldDepartment(department)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Subjects.Save(subject)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, toSubjectJSON(subject), nil
+}
+
+func unsetSubjectDepartment(req *apiRequest, args []string) (int, interface{}, error) {
+	err := req.transaction(func() error {
+		subject, err := req.repos.Subjects.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if subject.Department == nil {
+			return fmt.Errorf("subject %s has no department, %w", subject.Name, ErrNotLinked)
+		}
+
+		err = subject.UnlinkFromDepartmentOnFieldDepartment(subject.Department)
+		if err != nil {
+			return err
+		}
+
+		return req.repos.Subjects.Save(subject)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves the api over a seeded in-memory store
+func newTestServer(t *testing.T) (*httptest.Server, *exampleSchool) {
+	t.Helper()
+
+	store := NewMemoryStore()
+	sess, err := store.NewSession(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	school, err := seedSchool(sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := NewAPIServer(func() (gogm.ISession, error) {
+		return store.NewSession(false)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(api), school
+}
+
+func TestStatusFor(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{errBadRequest, http.StatusBadRequest},
+		{ValidationErrors{{Path: "Student.Name", Err: ErrRequired}}, http.StatusBadRequest},
+		{newLinkError("Student", "Enrollments", "ENROLLED", ErrNilTarget), http.StatusBadRequest},
+		{fmt.Errorf("loading: %w", gogm.ErrNotFound), http.StatusNotFound},
+		{newLinkError("Student", "Enrollments", "ENROLLED", ErrNotLinked), http.StatusNotFound},
+		{ErrNotEnrolled, http.StatusNotFound},
+		{errConflict, http.StatusConflict},
+		{ErrCourseFull, http.StatusConflict},
+		{ErrMissingPrerequisites, http.StatusConflict},
+		{newLinkError("Student", "Enrollments", "ENROLLED", ErrScheduleConflict), http.StatusConflict},
+		{errMethodNotAllowed, http.StatusMethodNotAllowed},
+		{errors.New("bolt is down"), http.StatusInternalServerError},
+	} {
+		if got := statusFor(tt.err); got != tt.want {
+			t.Errorf("statusFor(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAPIStatusCodes(t *testing.T) {
+	server, school := newTestServer(t)
+	defer server.Close()
+
+	eric, michael := school.Students["eric"].UUID, school.Students["michael"].UUID
+	cs341_0, cs341_1 := school.Offerings["cs341-0 "+exampleTerm].UUID, school.Offerings["cs341-1 "+exampleTerm].UUID
+	hist347 := school.Offerings["hist347-0 "+exampleTerm].UUID
+	gates, cs341 := school.Rooms["Gates 101"].UUID, school.Courses["cs341"].UUID
+
+	// the requests run in order against the same graph
+	for _, tt := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{"GET", "/students", "", http.StatusOK},
+		{"GET", "/students/" + eric, "", http.StatusOK},
+		{"GET", "/students/nobody", "", http.StatusNotFound},
+		{"GET", "/widgets", "", http.StatusNotFound},
+		{"PATCH", "/students", "", http.StatusMethodNotAllowed},
+		{"POST", "/students", `{"name": "anna"}`, http.StatusCreated},
+		{"POST", "/students", `{"name": "eric"}`, http.StatusConflict},
+		{"POST", "/students", `{"name": "`, http.StatusBadRequest},
+		{"POST", "/students", `{"name": " "}`, http.StatusBadRequest},
+		{"POST", "/students/" + michael + "/enrollments", `{}`, http.StatusBadRequest},
+		{"POST", "/students/" + michael + "/enrollments", `{"offering_id": "` + cs341_0 + `"}`, http.StatusCreated},
+		{"POST", "/students/" + michael + "/enrollments", `{"offering_id": "` + cs341_0 + `"}`, http.StatusConflict},
+		{"POST", "/students/" + eric + "/enrollments", `{"offering_id": "` + cs341_1 + `"}`, http.StatusConflict},
+		{"DELETE", "/students/" + michael + "/enrollments/" + hist347, "", http.StatusOK},
+		{"DELETE", "/students/" + michael + "/enrollments/" + cs341_1, "", http.StatusNotFound},
+		{"PUT", "/students/" + eric + "/enrollments/" + cs341_0 + "/grade", `{"grade": "Z"}`, http.StatusBadRequest},
+		{"PUT", "/students/" + eric + "/enrollments/" + cs341_0 + "/grade", `{"grade": "b+"}`, http.StatusOK},
+		{"POST", "/offerings/" + cs341_1 + "/meetings", `{"room_id": "` + gates + `", "day": "someday", "start_time": "09:00", "end_time": "10:00"}`, http.StatusBadRequest},
+		{"POST", "/offerings/" + cs341_1 + "/meetings", `{"room_id": "` + gates + `", "day": "monday", "start_time": "09:00", "end_time": "10:00"}`, http.StatusConflict},
+		{"POST", "/courses/" + cs341 + "/prerequisites", `{"id": "` + cs341 + `"}`, http.StatusConflict},
+	} {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body errorJSON
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s: got %v %q, want %v", tt.method, tt.path, resp.StatusCode, body.Error, tt.want)
+		}
+	}
+}
@@ -0,0 +1,1224 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrUsage is returned by the cli when a command is unknown or called with the wrong arguments
+var ErrUsage = errors.New("invalid usage")
+
+// cliCommand is one subcommand of the cli
+type cliCommand struct {
+	Name        string
+	Args        string
+	Description string
+
+	run func(c *cli, args []string) error
+}
+
+// cliCommands lists the subcommands in the order they are printed in the usage
+var cliCommands = []*cliCommand{
+	{Name: "example", Description: "run the walkthrough from main.go", run: (*cli).example},
+	{Name: "seed", Description: "create the example school", run: (*cli).seed},
+	{Name: "list", Args: "<type>", Description: "list the nodes of a type", run: (*cli).list},
+	{Name: "get", Args: "<uuid>", Description: "show a node and its relationships", run: (*cli).get},
+	{Name: "offer", Args: "[-section 0] [-teacher name] [-capacity 0] <course> <term>", Description: "offer a section of a course in a term", run: (*cli).offer},
+	{Name: "offerings", Args: "[-term term] [-department name]", Description: "list the offerings of a term or a department", run: (*cli).offerings},
+	{Name: "enroll", Args: "[-term term] [-section s] [-waitlist] <student> <course>", Description: "enroll a student in an offering of a course", run: (*cli).enroll},
+	{Name: "drop", Args: "[-term term] [-section s] [-withdraw] <student> <course>", Description: "drop a student from an offering, the enrollment is kept", run: (*cli).drop},
+	{Name: "capacity", Args: "[-term term] [-section s] <course> <seats>", Description: "set the capacity of an offering, 0 for unlimited", run: (*cli).capacity},
+	{Name: "prerequisite", Args: "[-remove] <course> <prerequisite>", Description: "make a course require another one", run: (*cli).prerequisite},
+	{Name: "schedule", Args: "[-term term] [-section s] [-remove] <course> <room> <day> <start> <end>", Description: "add a weekly meeting of an offering in a room", run: (*cli).schedule},
+	{Name: "grade", Args: "[-term term] [-section s] <student> <course> <grade>", Description: "record the letter grade of a student in an offering", run: (*cli).grade},
+	{Name: "grades", Args: "[-term term] [-section s] <student|course> <name>", Description: "list the grades and the GPA of a student or an offering", run: (*cli).grades},
+	{Name: "delete", Args: "<type> <name>", Description: "delete the node of a type called name", run: (*cli).delete},
+	{Name: "reset", Args: "-yes", Description: "delete everything in the graph", run: (*cli).reset},
+	{Name: "import", Args: "[-dry-run] [-batch 100] <dir>", Description: "import the roster csv files in dir", run: (*cli).importRoster},
+	{Name: "export", Args: "[-format json|ndjson] [-o file]", Description: "write the whole graph as json", run: (*cli).export},
+	{Name: "restore", Args: "<file>", Description: "load a graph written by export into an empty graph", run: (*cli).restore},
+	{Name: "diagram", Args: "[-format dot|mermaid] [-instances] [-seed] [-o file]", Description: "draw the schema, or the nodes in the graph with -instances", run: (*cli).diagram},
+	{Name: "serve", Args: "[-addr :8080]", Description: "serve the rest api", run: (*cli).serve},
+}
+
+// nodeTypes are the names the cli accepts for <type>, plural and singular
+var nodeTypes = map[string]string{
+	"departments": "department",
+	"department":  "department",
+	"subjects":    "subject",
+	"subject":     "subject",
+	"teachers":    "teacher",
+	"teacher":     "teacher",
+	"courses":     "course",
+	"course":      "course",
+	"students":    "student",
+	"student":     "student",
+	"rooms":       "room",
+	"room":        "room",
+	"terms":       "term",
+	"term":        "term",
+	"offerings":   "offering",
+	"offering":    "offering",
+}
+
+// printUsage writes the commands of the cli to out
+func printUsage(out io.Writer) {
+	fmt.Fprintln(out, "usage: gogm-example [flags] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", cmd.Name, cmd.Args, cmd.Description)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "types: department, subject, teacher, course, student, room, term, offering")
+	fmt.Fprintln(out, "the -term and -section flags pick the offering of a course when it has more than one")
+}
+
+// cli runs the subcommands against one session, serve opens a session per request with newSession
+type cli struct {
+	sess       gogm.ISession
+	newSession SessionFactory
+	repos      *Repositories
+	out        io.Writer
+}
+
+// newCLI creates a cli writing its output to out
+func newCLI(sess gogm.ISession, newSession SessionFactory, out io.Writer) *cli {
+	return &cli{
+		sess:       sess,
+		newSession: newSession,
+		repos:      NewRepositories(sess),
+		out:        out,
+	}
+}
+
+// run dispatches args to the subcommand named by args[0]
+func (c *cli) run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w, no command given", ErrUsage)
+	}
+
+	for _, cmd := range cliCommands {
+		if cmd.Name == args[0] {
+			return cmd.run(c, args[1:])
+		}
+	}
+
+	return fmt.Errorf("%w, unknown command %q", ErrUsage, args[0])
+}
+
+// checkArgs makes sure a command got exactly the arguments it needs
+func checkArgs(name string, args []string, want ...string) error {
+	if len(args) == len(want) {
+		return nil
+	}
+
+	if len(want) == 0 {
+		return fmt.Errorf("%w, %s takes no arguments", ErrUsage, name)
+	}
+
+	return fmt.Errorf("%w, %s expects %s", ErrUsage, name, strings.Join(want, " "))
+}
+
+// inTransaction runs fn in a transaction, rolling back if it fails
+func (c *cli) inTransaction(fn func() error) error {
+	err := c.sess.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil {
+		return c.sess.RollbackWithError(err)
+	}
+
+	err = c.sess.Commit()
+	if err != nil {
+		return c.sess.RollbackWithError(err)
+	}
+
+	return nil
+}
+
+func (c *cli) example(args []string) error {
+	err := checkArgs("example", args)
+	if err != nil {
+		return err
+	}
+
+	return runExample(c.sess)
+}
+
+func (c *cli) seed(args []string) error {
+	err := checkArgs("seed", args)
+	if err != nil {
+		return err
+	}
+
+	// teachers and students have unique names, seeding twice would only fail half way
+	departments, err := c.repos.Departments.List()
+	if err != nil {
+		return err
+	}
+
+	if len(departments) != 0 {
+		return errors.New("the graph already has departments, run reset first")
+	}
+
+	school, err := seedSchool(c.sess)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "created %v departments, %v subjects, %v teachers, %v courses, %v offerings, %v students and %v rooms\n",
+		len(school.Departments), len(school.Subjects), len(school.Teachers), len(school.Courses), len(school.Offerings), len(school.Students), len(school.Rooms))
+	return nil
+}
+
+func (c *cli) list(args []string) error {
+	err := checkArgs("list", args, "<type>")
+	if err != nil {
+		return err
+	}
+
+	var nodes []interface{}
+	switch nodeTypes[strings.ToLower(args[0])] {
+	case "department":
+		departments, err := c.repos.Departments.List()
+		if err != nil {
+			return err
+		}
+		for _, department := range departments {
+			nodes = append(nodes, department)
+		}
+	case "subject":
+		subjects, err := c.repos.Subjects.List()
+		if err != nil {
+			return err
+		}
+		for _, subject := range subjects {
+			nodes = append(nodes, subject)
+		}
+	case "teacher":
+		teachers, err := c.repos.Teachers.List()
+		if err != nil {
+			return err
+		}
+		for _, teacher := range teachers {
+			nodes = append(nodes, teacher)
+		}
+	case "course":
+		courses, err := c.repos.Courses.List()
+		if err != nil {
+			return err
+		}
+		for _, course := range courses {
+			nodes = append(nodes, course)
+		}
+	case "student":
+		students, err := c.repos.Students.List()
+		if err != nil {
+			return err
+		}
+		for _, student := range students {
+			nodes = append(nodes, student)
+		}
+	case "room":
+		rooms, err := c.repos.Rooms.List()
+		if err != nil {
+			return err
+		}
+		for _, room := range rooms {
+			nodes = append(nodes, room)
+		}
+	case "term":
+		terms, err := c.repos.Terms.List()
+		if err != nil {
+			return err
+		}
+		for _, term := range terms {
+			nodes = append(nodes, term)
+		}
+	case "offering":
+		offerings, err := c.repos.Offerings.List()
+		if err != nil {
+			return err
+		}
+		for _, offering := range offerings {
+			nodes = append(nodes, offering)
+		}
+	default:
+		return fmt.Errorf("%w, unknown type %q", ErrUsage, args[0])
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodeName(nodes[i]) < nodeName(nodes[j])
+	})
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%s\n", baseNodeOf(reflect.ValueOf(node).Elem()).UUID, nodeName(node))
+	}
+
+	return w.Flush()
+}
+
+func (c *cli) get(args []string) error {
+	err := checkArgs("get", args, "<uuid>")
+	if err != nil {
+		return err
+	}
+
+	uuid := args[0]
+
+	// the uuid does not say which type it belongs to, try them all
+	lookups := []func() (interface{}, error){
+		func() (interface{}, error) { return c.repos.Departments.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Subjects.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Teachers.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Courses.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Students.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Rooms.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Terms.Get(uuid) },
+		func() (interface{}, error) { return c.repos.Offerings.Get(uuid) },
+	}
+
+	for _, lookup := range lookups {
+		node, err := lookup()
+		if errors.Is(err, gogm.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		return describeNode(c.out, node)
+	}
+
+	return fmt.Errorf("nothing with uuid [%s], %w", uuid, gogm.ErrNotFound)
+}
+
+func (c *cli) offer(args []string) error {
+	fs := flag.NewFlagSet("offer", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	section := fs.String("section", defaultSection, "section of the course")
+	teacherName := fs.String("teacher", "", "teacher of the section")
+	capacity := fs.Int("capacity", 0, "seats of the section, 0 for unlimited")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("offer", fs.Args(), "<course>", "<term>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		courses, err := c.repos.Courses.FindByName(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		i, err := onlyOne("course", fs.Arg(0), len(courses))
+		if err != nil {
+			return err
+		}
+
+		// terms are created the first time a course is offered in them
+		term, err := c.repos.Terms.FindByName(fs.Arg(1))
+		if errors.Is(err, gogm.ErrNotFound) {
+			term = &Term{Name: fs.Arg(1)}
+		} else if err != nil {
+			return err
+		}
+
+		var teacher *Teacher
+		if *teacherName != "" {
+			teacher, err = c.repos.Teachers.FindByName(*teacherName)
+			if err != nil {
+				return fmt.Errorf("teacher %s: %w", *teacherName, err)
+			}
+		}
+
+		offering, err := NewRegistrar(c.sess).Offer(courses[i], term, *section, teacher, *capacity)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "offered %s\n", offering.Name)
+		return nil
+	})
+}
+
+func (c *cli) offerings(args []string) error {
+	fs := flag.NewFlagSet("offerings", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term := fs.String("term", "", "only list offerings in this term")
+	department := fs.String("department", "", "only list offerings of courses of this department")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("offerings", fs.Args())
+	if err != nil {
+		return err
+	}
+
+	offerings, err := c.repos.Offerings.Search(*term, *department)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	for _, offering := range offerings {
+		teacher := ""
+		if offering.Teacher != nil {
+			teacher = offering.Teacher.Name
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", offering.UUID, offering.Name, teacher)
+	}
+
+	return w.Flush()
+}
+
+// offeringFlags adds the -term and -section flags that pick an offering of a course to fs
+func offeringFlags(fs *flag.FlagSet) (*string, *string) {
+	term := fs.String("term", "", "term of the offering, needed when the course is offered in several terms")
+	section := fs.String("section", "", "section of the offering, needed when the course has several sections in the term")
+	return term, section
+}
+
+func (c *cli) enroll(args []string) error {
+	fs := flag.NewFlagSet("enroll", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+	waitlist := fs.Bool("waitlist", false, "join the waitlist when the offering is full")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("enroll", fs.Args(), "<student>", "<course>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		student, offering, err := c.findEnrollment(fs.Arg(0), fs.Arg(1), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		registrar := NewRegistrar(c.sess)
+		registrar.Waitlist = *waitlist
+
+		enrollment := NewEnrollment(time.Now().UTC())
+		err = registrar.Enroll(student, offering, enrollment)
+		if err != nil {
+			return err
+		}
+
+		if enrollment.Waitlisted() {
+			fmt.Fprintf(c.out, "put %s on the waitlist of %s\n", student.Name, offering.Name)
+		} else {
+			fmt.Fprintf(c.out, "enrolled %s in %s\n", student.Name, offering.Name)
+		}
+		return nil
+	})
+}
+
+func (c *cli) drop(args []string) error {
+	fs := flag.NewFlagSet("drop", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+	withdraw := fs.Bool("withdraw", false, "mark the enrollment withdrawn instead of dropped")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("drop", fs.Args(), "<student>", "<course>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		student, offering, err := c.findEnrollment(fs.Arg(0), fs.Arg(1), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err := NewRegistrar(c.sess).Drop(student, offering, time.Now().UTC(), *withdraw)
+		if errors.Is(err, ErrNotEnrolled) {
+			return fmt.Errorf("%s is not enrolled in %s", student.Name, offering.Name)
+		} else if err != nil {
+			return err
+		}
+
+		verb := "dropped"
+		if enrollment.Status == EnrollmentWithdrawn {
+			verb = "withdrew"
+		}
+
+		fmt.Fprintf(c.out, "%s %s from %s\n", verb, student.Name, offering.Name)
+		return nil
+	})
+}
+
+func (c *cli) capacity(args []string) error {
+	fs := flag.NewFlagSet("capacity", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("capacity", fs.Args(), "<course>", "<seats>")
+	if err != nil {
+		return err
+	}
+
+	seats, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("%w, seats has to be a number", ErrUsage)
+	}
+
+	return c.inTransaction(func() error {
+		offering, err := c.findOffering(fs.Arg(0), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		promoted, err := NewRegistrar(c.sess).SetCapacity(offering, seats, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		if seats == 0 {
+			fmt.Fprintf(c.out, "%s has unlimited seats\n", offering.Name)
+		} else {
+			fmt.Fprintf(c.out, "%s has %v seats\n", offering.Name, seats)
+		}
+		for _, enrollment := range promoted {
+			fmt.Fprintf(c.out, "enrolled %s from the waitlist\n", enrollment.Start.Name)
+		}
+		return nil
+	})
+}
+
+func (c *cli) prerequisite(args []string) error {
+	fs := flag.NewFlagSet("prerequisite", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	remove := fs.Bool("remove", false, "remove the prerequisite instead")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("prerequisite", fs.Args(), "<course>", "<prerequisite>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		var courses [2]*Course
+		for i, name := range fs.Args() {
+			found, err := c.repos.Courses.FindByName(name)
+			if err != nil {
+				return err
+			}
+
+			j, err := onlyOne("course", name, len(found))
+			if err != nil {
+				return err
+			}
+			courses[i] = found[j]
+		}
+
+		course, prerequisite := courses[0], courses[1]
+		if *remove {
+			err = NewRegistrar(c.sess).RemovePrerequisite(course, prerequisite)
+			if errors.Is(err, ErrNotLinked) {
+				return fmt.Errorf("%s does not require %s", course.Name, prerequisite.Name)
+			} else if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(c.out, "%s no longer requires %s\n", course.Name, prerequisite.Name)
+			return nil
+		}
+
+		err = NewRegistrar(c.sess).AddPrerequisite(course, prerequisite)
+		if errors.Is(err, ErrAlreadyLinked) {
+			return fmt.Errorf("%s already requires %s", course.Name, prerequisite.Name)
+		} else if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "%s requires %s\n", course.Name, prerequisite.Name)
+		return nil
+	})
+}
+
+func (c *cli) schedule(args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+	remove := fs.Bool("remove", false, "remove the meeting instead")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("schedule", fs.Args(), "<course>", "<room>", "<day>", "<start>", "<end>")
+	if err != nil {
+		return err
+	}
+
+	meeting, err := NewMeeting(fs.Arg(2), fs.Arg(3), fs.Arg(4))
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		offering, err := c.findOffering(fs.Arg(0), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		if *remove {
+			for _, existing := range offering.Meetings {
+				if existing.End != nil && existing.End.Name == fs.Arg(1) && existing.String() == meeting.String() {
+					err = NewRegistrar(c.sess).Unschedule(offering, existing)
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.out, "%s no longer meets in %s %s\n", offering.Name, fs.Arg(1), meeting)
+					return nil
+				}
+			}
+
+			return fmt.Errorf("%s does not meet in %s %s", offering.Name, fs.Arg(1), meeting)
+		}
+
+		// rooms are created the first time an offering is scheduled in them
+		room, err := c.repos.Rooms.FindByName(fs.Arg(1))
+		if errors.Is(err, gogm.ErrNotFound) {
+			room = &Room{Name: fs.Arg(1)}
+		} else if err != nil {
+			return err
+		}
+
+		err = NewRegistrar(c.sess).Schedule(offering, room, meeting)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "%s meets in %s %s\n", offering.Name, room.Name, meeting)
+		return nil
+	})
+}
+
+func (c *cli) grade(args []string) error {
+	fs := flag.NewFlagSet("grade", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("grade", fs.Args(), "<student>", "<course>", "<grade>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		student, offering, err := c.findEnrollment(fs.Arg(0), fs.Arg(1), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err := NewGradeBook(c.sess).Record(student, offering, fs.Arg(2))
+		if errors.Is(err, ErrNotEnrolled) {
+			return fmt.Errorf("%s is not enrolled in %s", student.Name, offering.Name)
+		} else if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "graded %s %s in %s\n", student.Name, enrollment.FinalGrade, offering.Name)
+		return nil
+	})
+}
+
+func (c *cli) grades(args []string) error {
+	fs := flag.NewFlagSet("grades", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	term, section := offeringFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("grades", fs.Args(), "<student|course>", "<name>")
+	if err != nil {
+		return err
+	}
+
+	var report *GradeReport
+	var other func(enrollment *Enrollment) string
+
+	switch fs.Arg(0) {
+	case "student":
+		student, err := c.repos.Students.FindByName(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("student %s: %w", fs.Arg(1), err)
+		}
+
+		report = StudentReport(student)
+		other = func(enrollment *Enrollment) string { return enrollment.End.Name }
+	case "course":
+		offering, err := c.findOffering(fs.Arg(1), *term, *section)
+		if err != nil {
+			return err
+		}
+
+		report = OfferingReport(offering)
+		other = func(enrollment *Enrollment) string { return enrollment.Start.Name }
+	default:
+		return fmt.Errorf("%w, grades expects student or course, got %s", ErrUsage, fs.Arg(0))
+	}
+
+	if len(report.Grades) == 0 {
+		fmt.Fprintln(c.out, "no grades")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	for _, enrollment := range report.Grades {
+		points, _ := enrollment.GradePoints()
+		fmt.Fprintf(w, "%s\t%s\t%.1f\n", other(enrollment), enrollment.FinalGrade, points)
+	}
+	fmt.Fprintf(w, "GPA\t\t%.2f\n", report.GPA)
+
+	return w.Flush()
+}
+
+func (c *cli) findEnrollment(studentName, courseName, term, section string) (*Student, *CourseOffering, error) {
+	student, err := c.repos.Students.FindByName(studentName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("student %s: %w", studentName, err)
+	}
+
+	offering, err := c.findOffering(courseName, term, section)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return student, offering, nil
+}
+
+// findOffering finds the offering of the course called courseName in term and section,
+// which can be left empty as long as the course has only one offering that matches
+func (c *cli) findOffering(courseName, term, section string) (*CourseOffering, error) {
+	courses, err := c.repos.Courses.FindByName(courseName)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := onlyOne("course", courseName, len(courses))
+	if err != nil {
+		return nil, err
+	}
+
+	offerings, err := c.repos.Offerings.FindOf(courses[i], term, section)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(offerings) {
+	case 0:
+		return nil, fmt.Errorf("offering of %s: %w", courseName, gogm.ErrNotFound)
+	case 1:
+		return offerings[0], nil
+	default:
+		names := make([]string, 0, len(offerings))
+		for _, offering := range offerings {
+			names = append(names, offering.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%s has %v offerings, pick one with -term and -section: %s", courseName, len(offerings), strings.Join(names, ", "))
+	}
+}
+
+// onlyOne makes sure a lookup by a name that is not unique found exactly one node, returning its index
+func onlyOne(typ, name string, found int) (int, error) {
+	switch found {
+	case 0:
+		return 0, fmt.Errorf("%s %s: %w", typ, name, gogm.ErrNotFound)
+	case 1:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("there are %v of %s %s, use its uuid instead", found, typ, name)
+	}
+}
+
+func (c *cli) delete(args []string) error {
+	err := checkArgs("delete", args, "<type>", "<name>")
+	if err != nil {
+		return err
+	}
+
+	return c.inTransaction(func() error {
+		typ, err := c.deleteByName(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "deleted %s %s\n", typ, args[1])
+		return nil
+	})
+}
+
+// deleteByName deletes the node of typ called name, returning the canonical type name
+func (c *cli) deleteByName(typeName, name string) (string, error) {
+	typ, ok := nodeTypes[strings.ToLower(typeName)]
+	if !ok {
+		return "", fmt.Errorf("%w, unknown type %q", ErrUsage, typeName)
+	}
+
+	switch typ {
+	case "department":
+		departments, err := c.repos.Departments.FindByName(name)
+		if err != nil {
+			return typ, err
+		}
+		i, err := onlyOne(typ, name, len(departments))
+		if err != nil {
+			return typ, err
+		}
+		return typ, c.repos.Departments.Delete(departments[i])
+	case "subject":
+		subjects, err := c.repos.Subjects.FindByName(name)
+		if err != nil {
+			return typ, err
+		}
+		i, err := onlyOne(typ, name, len(subjects))
+		if err != nil {
+			return typ, err
+		}
+		return typ, c.repos.Subjects.Delete(subjects[i])
+	case "teacher":
+		teacher, err := c.repos.Teachers.FindByName(name)
+		if err != nil {
+			return typ, fmt.Errorf("teacher %s: %w", name, err)
+		}
+		return typ, c.repos.Teachers.Delete(teacher)
+	case "course":
+		courses, err := c.repos.Courses.FindByName(name)
+		if err != nil {
+			return typ, err
+		}
+		i, err := onlyOne(typ, name, len(courses))
+		if err != nil {
+			return typ, err
+		}
+		return typ, c.repos.Courses.Delete(courses[i])
+	case "room":
+		room, err := c.repos.Rooms.FindByName(name)
+		if err != nil {
+			return typ, fmt.Errorf("room %s: %w", name, err)
+		}
+		return typ, c.repos.Rooms.Delete(room)
+	case "term":
+		term, err := c.repos.Terms.FindByName(name)
+		if err != nil {
+			return typ, fmt.Errorf("term %s: %w", name, err)
+		}
+		return typ, c.repos.Terms.Delete(term)
+	case "offering":
+		offerings, err := c.repos.Offerings.FindByName(name)
+		if err != nil {
+			return typ, err
+		}
+		i, err := onlyOne(typ, name, len(offerings))
+		if err != nil {
+			return typ, err
+		}
+		return typ, c.repos.Offerings.Delete(offerings[i])
+	default:
+		student, err := c.repos.Students.FindByName(name)
+		if err != nil {
+			return typ, fmt.Errorf("student %s: %w", name, err)
+		}
+		return typ, c.repos.Students.Delete(student)
+	}
+}
+
+func (c *cli) reset(args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	yes := fs.Bool("yes", false, "confirm deleting everything")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	if !*yes {
+		return errors.New("reset deletes every node in the graph, pass -yes to confirm")
+	}
+
+	err = c.sess.PurgeDatabase()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "deleted everything")
+	return nil
+}
+
+func (c *cli) importRoster(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	dryRun := fs.Bool("dry-run", false, "validate the files without saving anything")
+	batch := fs.Int("batch", defaultImportBatchSize, "number of nodes saved per transaction")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("import", fs.Args(), "<dir>")
+	if err != nil {
+		return err
+	}
+
+	importer := NewRosterImporter(c.sess)
+	importer.DryRun = *dryRun
+	importer.BatchSize = *batch
+
+	result, err := importer.ImportDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "imported %v departments, %v subjects, %v teachers, %v courses, %v terms, %v offerings, %v students and %v enrollments",
+		result.Departments, result.Subjects, result.Teachers, result.Courses, result.Terms, result.Offerings, result.Students, result.Enrollments)
+	if *dryRun {
+		fmt.Fprintln(c.out, ", dry run so nothing was saved")
+	} else {
+		fmt.Fprintf(c.out, " in %v transactions\n", result.Batches)
+	}
+
+	return nil
+}
+
+func (c *cli) export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	format := fs.String("format", "json", "json for one document or ndjson for one line per node and relationship")
+	output := fs.String("o", "", "file to write to instead of stdout")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	err = checkArgs("export", fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var write func(s *Snapshot, w io.Writer) error
+	switch *format {
+	case "json":
+		write = (*Snapshot).WriteJSON
+	case "ndjson":
+		write = (*Snapshot).WriteNDJSON
+	default:
+		return fmt.Errorf("%w, unknown format %s", ErrUsage, *format)
+	}
+
+	snapshot, err := ExportGraph(c.sess, registeredTypes...)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(snapshot, c.out)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	err = write(snapshot, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "exported %v nodes and %v relationships to %s\n", len(snapshot.Nodes), len(snapshot.Relationships), *output)
+	return nil
+}
+
+// diagramOptions are the flags of the diagram command
+type diagramOptions struct {
+	format    string
+	instances bool
+	seed      bool
+	output    string
+}
+
+// parseDiagramFlags reads the flags of the diagram command, writing their usage to out
+func parseDiagramFlags(args []string, out io.Writer) (*diagramOptions, error) {
+	opts := &diagramOptions{}
+
+	fs := flag.NewFlagSet("diagram", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.StringVar(&opts.format, "format", "mermaid", "dot for graphviz or mermaid")
+	fs.BoolVar(&opts.instances, "instances", false, "draw the nodes and relationships in the graph instead of the schema")
+	fs.BoolVar(&opts.seed, "seed", false, "create the example school first, only with -memory")
+	fs.StringVar(&opts.output, "o", "", "file to write to instead of stdout")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	return opts, checkArgs("diagram", fs.Args())
+}
+
+// needsGraph tells whether the command in args uses the graph, a diagram of the schema only needs the models
+func needsGraph(args []string) bool {
+	if len(args) == 0 || args[0] != "diagram" {
+		return true
+	}
+
+	// bad flags fail the command before it gets to the graph
+	opts, err := parseDiagramFlags(args[1:], ioutil.Discard)
+	return err == nil && (opts.instances || opts.seed)
+}
+
+func (c *cli) diagram(args []string) error {
+	opts, err := parseDiagramFlags(args, c.out)
+	if err != nil {
+		return err
+	}
+
+	var write func(d *Diagram, w io.Writer) error
+	switch opts.format {
+	case "dot":
+		write = (*Diagram).WriteDOT
+	case "mermaid":
+		write = (*Diagram).WriteMermaid
+	default:
+		return fmt.Errorf("%w, unknown format %s", ErrUsage, opts.format)
+	}
+
+	// seeding is only meant for a throwaway graph, never for the database
+	if opts.seed {
+		if _, ok := c.sess.(*MemorySession); !ok {
+			return fmt.Errorf("%w, diagram -seed only works with -memory", ErrUsage)
+		}
+
+		_, err = seedSchool(c.sess)
+		if err != nil {
+			return err
+		}
+	}
+
+	var diagram *Diagram
+	if opts.instances {
+		snapshot, err := ExportGraph(c.sess, registeredTypes...)
+		if err != nil {
+			return err
+		}
+
+		diagram = InstanceDiagram(snapshot)
+	} else {
+		diagram, err = SchemaDiagram(registeredTypes...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.output == "" {
+		return write(diagram, c.out)
+	}
+
+	file, err := os.Create(opts.output)
+	if err != nil {
+		return err
+	}
+
+	err = write(diagram, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (c *cli) restore(args []string) error {
+	err := checkArgs("restore", args, "<file>")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	snapshot, err := ReadSnapshot(file)
+	if err != nil {
+		return err
+	}
+
+	err = RestoreGraph(c.sess, snapshot, registeredTypes...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "restored %v nodes and %v relationships\n", len(snapshot.Nodes), len(snapshot.Relationships))
+	return nil
+}
+
+func (c *cli) serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	addr := fs.String("addr", ":8080", "address to listen on")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUsage, err)
+	}
+
+	server, err := NewAPIServer(c.newSession)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "serving the api on %s\n", *addr)
+	return http.ListenAndServe(*addr, server)
+}
+
+// nodeName returns the name property of a node, all of the example nodes have one
+func nodeName(node interface{}) string {
+	val := reflect.ValueOf(node)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	name := val.FieldByName("Name")
+	if !name.IsValid() || name.Kind() != reflect.String {
+		return ""
+	}
+
+	return name.String()
+}
+
+// describeNode writes the properties and relationships of a loaded node
+func describeNode(out io.Writer, node interface{}) error {
+	val := reflect.ValueOf(node)
+
+	schema, err := schemaFor(val.Type())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s %s\n", schema.Label, nodeName(node))
+	writeProperties(w, "  ", schema, val.Elem())
+
+	for _, field := range schema.Relationships {
+		arrow := "->"
+		if field.Direction == directionIncoming {
+			arrow = "<-"
+		}
+
+		for _, target := range relationshipTargets(val.Elem(), field) {
+			other := target
+			if field.Edge != nil {
+				edge := target.Interface().(gogm.IEdge)
+				if field.Direction == directionIncoming {
+					other = reflect.ValueOf(edge.GetStartNode())
+				} else {
+					other = reflect.ValueOf(edge.GetEndNode())
+				}
+			}
+
+			fmt.Fprintf(w, "  %s\t%s %s %s (%s)\n", field.FieldName, arrow, field.Relationship, nodeName(other.Interface()), baseNodeOf(other.Elem()).UUID)
+
+			if field.Edge != nil {
+				edgeSchema, err := schemaFor(target.Type())
+				if err != nil {
+					return err
+				}
+				writeProperties(w, "    ", edgeSchema, target.Elem())
+			}
+		}
+	}
+
+	return w.Flush()
+}
+
+// writeProperties writes every property but the name, which is printed in the header, and the graph id
+func writeProperties(w io.Writer, indent string, schema *nodeSchema, val reflect.Value) {
+	for _, prop := range schema.Properties {
+		if prop.Name == "name" || prop.Name == "id" {
+			continue
+		}
+
+		value := val.FieldByIndex(prop.Index)
+		if value.IsZero() {
+			continue
+		}
+
+		if prop.Time {
+			fmt.Fprintf(w, "%s%s\t%s\n", indent, prop.Name, value.Interface().(time.Time).Format(time.RFC3339))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s%s\t%v\n", indent, prop.Name, value.Interface())
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCLI(t *testing.T) {
+	sess := newTestSession(t)
+	var out bytes.Buffer
+	c := newCLI(sess, nil, &out)
+
+	// the commands run one after the other against the same graph
+	for _, tt := range []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{args: nil, wantErr: "invalid usage, no command given"},
+		{args: []string{"frobnicate"}, wantErr: `invalid usage, unknown command "frobnicate"`},
+		{args: []string{"seed", "now"}, wantErr: "invalid usage, seed takes no arguments"},
+		{args: []string{"seed"}, want: "created 3 departments, 3 subjects, 4 teachers, 3 courses, 4 offerings, 4 students and 2 rooms"},
+		{args: []string{"seed"}, wantErr: "run reset first"},
+		{args: []string{"list", "students"}, want: "eric"},
+		{args: []string{"list", "widgets"}, wantErr: `invalid usage, unknown type "widgets"`},
+		{args: []string{"enroll", "michael"}, wantErr: "invalid usage, enroll expects <student> <course>"},
+		{args: []string{"enroll", "-section", "1", "michael", "cs341"}, want: "enrolled michael in cs341-1 " + exampleTerm},
+		{args: []string{"drop", "eric", "phys122"}, want: "dropped eric from phys122-0 " + exampleTerm},
+		{args: []string{"drop", "-withdraw", "nikita", "hist347"}, want: "withdrew nikita from hist347-0 " + exampleTerm},
+		{args: []string{"drop", "steven", "phys122"}, wantErr: "steven is not enrolled in phys122-0 " + exampleTerm},
+		{args: []string{"delete", "student", "steven"}, want: "deleted student steven"},
+		{args: []string{"delete", "student", "steven"}, wantErr: "student steven: no Student found"},
+		{args: []string{"reset"}, wantErr: "pass -yes to confirm"},
+		{args: []string{"reset", "-yes"}, want: "deleted everything"},
+		{args: []string{"list", "students"}, want: ""},
+	} {
+		out.Reset()
+		err := c.run(tt.args)
+
+		switch {
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%q: got error %v, want %q", tt.args, err, tt.wantErr)
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%q: %v", tt.args, err)
+		case !strings.Contains(out.String(), tt.want) || (tt.want == "" && out.Len() != 0):
+			t.Errorf("%q: got output %q, want %q", tt.args, out.String(), tt.want)
+		}
+	}
+}
+
+func TestCLIGet(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	var out bytes.Buffer
+	c := newCLI(sess, nil, &out)
+
+	err := c.run([]string{"get", school.Students["eric"].UUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"eric", "Enrollments", "cs341-0 " + exampleTerm} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("the student is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err = c.run([]string{"get", "no-such-uuid"})
+	if err == nil {
+		t.Error("got a node for an unknown uuid")
+	}
+}
+
+// storedSession hides that a session is in memory, like a session against neo4j
+type storedSession struct {
+	*MemorySession
+}
+
+func TestCLIDiagramSeed(t *testing.T) {
+	var out bytes.Buffer
+
+	err := newCLI(storedSession{newTestSession(t)}, nil, &out).run([]string{"diagram", "-instances", "-seed"})
+	if !errors.Is(err, ErrUsage) {
+		t.Errorf("seeding a database got %v, want ErrUsage", err)
+	}
+
+	sess := newTestSession(t)
+	err = newCLI(sess, nil, &out).run([]string{"diagram", "-instances", "-seed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sess.read().nodes); n != 24 {
+		t.Errorf("seeded %v nodes, want 24", n)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"diagram"}, false},
+		{[]string{"diagram", "-format", "dot", "-o", "schema.dot"}, false},
+		{[]string{"diagram", "-widgets"}, false},
+		{[]string{"diagram", "-instances"}, true},
+		{[]string{"diagram", "-seed"}, true},
+		{[]string{"list", "students"}, true},
+		{nil, true},
+	} {
+		if got := needsGraph(tt.args); got != tt.want {
+			t.Errorf("needsGraph(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// linkgen generates the Link* and Unlink* functions of linking.go and the gogm.IEdge methods of edges.go
+// from the gogm struct tags in models.go. it is run by go generate, see the go:generate line in models.go
+//
+//	go run ./cmd/linkgen [-o linking.go] [-edges edges.go] [-check] models.go
+//
+// -check only compares the generated code with the output files and fails when they are out of date
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+)
+
+func main() {
+	out := flag.String("o", "linking.go", "file to write the linking functions to")
+	edgesOut := flag.String("edges", "edges.go", "file to write the methods of the edges to")
+	check := flag.Bool("check", false, "fail if the output files are not up to date instead of writing them")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: linkgen [-o linking.go] [-edges edges.go] [-check] <models.go>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err := run(flag.Arg(0), *out, *edgesOut, *check)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "linkgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the code for the models in path and writes it to out and edgesOut,
+// or compares it with them when check is set
+func run(path, out, edgesOut string, check bool) error {
+	linking, edges, err := generate(path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []struct {
+		name string
+		src  []byte
+	}{{out, linking}, {edgesOut, edges}} {
+		if !check {
+			err = ioutil.WriteFile(file.name, file.src, 0644)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		current, err := ioutil.ReadFile(file.name)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(current, file.src) {
+			return fmt.Errorf("%s is out of date with %s, run go generate", file.name, path)
+		}
+	}
+
+	return nil
+}
+
+// generate parses the models in path and returns the formatted linking functions and edge methods
+func generate(path string) ([]byte, []byte, error) {
+	rels, edges, err := parseModels(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// every node gets a same* helper, every slice an indexOf* helper,
+	// edge slices are matched by edge and the functions on edges are the only ones using fmt
+	nodeSet, sliceSet := map[string]bool{}, map[string]bool{}
+	usesFmt := false
+	for _, r := range rels {
+		nodeSet[r.Type] = true
+		if r.Edge != "" {
+			sliceSet[r.Edge] = true
+			usesFmt = true
+		} else if r.Many {
+			sliceSet[r.Target] = true
+		}
+	}
+
+	linking, err := render(linkTemplate, map[string]interface{}{
+		"Source":    filepath.Base(path),
+		"UsesFmt":   usesFmt,
+		"Relations": rels,
+		"Nodes":     sortedKeys(nodeSet),
+		"Slices":    sortedKeys(sliceSet),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edgeMethods, err := render(edgeTemplate, map[string]interface{}{
+		"Source": filepath.Base(path),
+		"Edges":  edges,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return linking, edgeMethods, nil
+}
+
+var (
+	// linkTemplate renders linking.go
+	linkTemplate = template.Must(template.New("linking").Parse(linkSource))
+	// edgeTemplate renders edges.go
+	edgeTemplate = template.Must(template.New("edges").Parse(edgeSource))
+)
+
+// render executes tmpl with data and formats the result
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the generated %s code: %w", tmpl.Name(), err)
+	}
+
+	return src, nil
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMatchesCommittedFiles(t *testing.T) {
+	linking, edges, err := generate("../../models.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		file string
+		want []byte
+	}{
+		{"../../linking.go", linking},
+		{"../../edges.go", edges},
+	} {
+		committed, err := ioutil.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(committed, tt.want) {
+			t.Errorf("%s is out of date with models.go, run go generate", filepath.Base(tt.file))
+		}
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "linkgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out, edgesOut := filepath.Join(dir, "linking.go"), filepath.Join(dir, "edges.go")
+
+	err = run("../../models.go", out, edgesOut, true)
+	if err == nil {
+		t.Fatal("check passed without output files")
+	}
+
+	err = run("../../models.go", out, edgesOut, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("../../models.go", out, edgesOut, true)
+	if err != nil {
+		t.Fatalf("check failed right after generating: %v", err)
+	}
+
+	err = ioutil.WriteFile(edgesOut, []byte("package main\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("../../models.go", out, edgesOut, true)
+	if err == nil || !strings.Contains(err.Error(), "edges.go is out of date") {
+		t.Errorf("got %v, want edges.go to be out of date", err)
+	}
+}
+
+// testModels are the declarations every case of TestParseModelsRejects starts from
+const testModels = `package main
+
+type Student struct {
+	Name        string
+	Enrollments []*Enrollment ` + "`gogm:\"direction=outgoing;relationship=ENROLLED\"`" + `
+}
+
+type Course struct {
+	Name        string
+	Enrollments []*Enrollment ` + "`gogm:\"direction=incoming;relationship=ENROLLED\"`" + `
+}
+
+type Enrollment struct {
+	Start *Student
+	End   *Course
+}
+`
+
+func TestParseModels(t *testing.T) {
+	path := writeModels(t, testModels)
+	defer os.Remove(path)
+
+	rels, edges, err := parseModels(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(edges) != 1 || *edges[0] != (edge{Name: "Enrollment", Start: "Student", End: "Course"}) {
+		t.Errorf("got edges %+v, want Enrollment from Student to Course", edges)
+	}
+
+	want := []relation{
+		{Type: "Course", Field: "Enrollments", Relationship: "ENROLLED", Direction: "incoming", Many: true,
+			Target: "Student", Edge: "Enrollment", OtherField: "Enrollments", OtherMany: true},
+		{Type: "Student", Field: "Enrollments", Relationship: "ENROLLED", Direction: "outgoing", Many: true,
+			Target: "Course", Edge: "Enrollment", OtherField: "Enrollments", OtherMany: true},
+	}
+	if len(rels) != len(want) {
+		t.Fatalf("got %v relations, want %v", len(rels), len(want))
+	}
+	for i := range want {
+		if *rels[i] != want[i] {
+			t.Errorf("relation %v: got %+v, want %+v", i, *rels[i], want[i])
+		}
+	}
+}
+
+func TestParseModelsRejects(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		models string
+		want   string
+	}{
+		{
+			name: "no incoming counterpart",
+			models: testModels + `
+type Teacher struct {
+	Courses []*Course ` + "`gogm:\"direction=outgoing;relationship=TEACHES\"`" + `
+}
+`,
+			want: "Teacher.Courses: outgoing TEACHES has no matching incoming field on Course",
+		},
+		{
+			name: "more than one counterpart",
+			models: `package main
+
+type Teacher struct {
+	Course *Course ` + "`gogm:\"direction=outgoing;relationship=TEACHES\"`" + `
+}
+
+type Course struct {
+	Teacher   *Teacher ` + "`gogm:\"direction=incoming;relationship=TEACHES\"`" + `
+	Assistant *Teacher ` + "`gogm:\"direction=incoming;relationship=TEACHES\"`" + `
+}
+`,
+			want: "Teacher.Course: outgoing TEACHES matches more than one field, Course.Teacher, Course.Assistant",
+		},
+		{
+			name: "direction both",
+			models: `package main
+
+type Student struct {
+	Friends []*Student ` + "`gogm:\"direction=both;relationship=FRIENDS\"`" + `
+}
+`,
+			want: `Student.Friends: direction of FRIENDS must be incoming or outgoing, found "both"`,
+		},
+		{
+			name: "edge field that is not a slice",
+			models: `package main
+
+type Student struct {
+	Enrollment *Enrollment ` + "`gogm:\"direction=outgoing;relationship=ENROLLED\"`" + `
+}
+
+type Course struct {
+	Enrollments []*Enrollment ` + "`gogm:\"direction=incoming;relationship=ENROLLED\"`" + `
+}
+
+type Enrollment struct {
+	Start *Student
+	End   *Course
+}
+`,
+			want: "Student.Enrollment: fields of the edge Enrollment must be slices",
+		},
+		{
+			name: "edge in the wrong direction",
+			models: `package main
+
+type Student struct {
+	Enrollments []*Enrollment ` + "`gogm:\"direction=incoming;relationship=ENROLLED\"`" + `
+}
+
+type Course struct {
+	Enrollments []*Enrollment ` + "`gogm:\"direction=outgoing;relationship=ENROLLED\"`" + `
+}
+
+type Enrollment struct {
+	Start *Student
+	End   *Course
+}
+`,
+			want: "Student.Enrollments: incoming Enrollment edges end at Course, not Student",
+		},
+		{
+			name: "undeclared target",
+			models: `package main
+
+type Student struct {
+	Advisor *Teacher ` + "`gogm:\"direction=outgoing;relationship=ADVISED_BY\"`" + `
+}
+`,
+			want: "Student.Advisor: Teacher is not declared in",
+		},
+		{
+			name: "undeclared edge end",
+			models: `package main
+
+type Student struct {
+	Name string
+}
+
+type Enrollment struct {
+	Start *Student
+	End   *Course
+}
+`,
+			want: "Enrollment: Course is not declared in",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeModels(t, tt.models)
+			defer os.Remove(path)
+
+			_, _, err := parseModels(path)
+			if err == nil {
+				t.Fatal("parseModels accepted the models")
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// writeModels writes src to a temporary file and returns its path
+func writeModels(t *testing.T, src string) string {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "models*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// relation is a relationship field of a node and the field on the other end of the relationship
+type relation struct {
+	// Type and Field are the node type and the field holding the relationship
+	Type  string
+	Field string
+
+	Relationship string
+	Direction    string
+
+	// Many is set for slice fields, Target is the node type on the other end
+	Many   bool
+	Target string
+
+	// Edge is the edge type of relationships with properties like Enrollment
+	Edge string
+
+	// OtherField is the field of Target holding the same relationship in the opposite direction
+	OtherField string
+	OtherMany  bool
+}
+
+// edge is a struct with Start and End node fields, a relationship with properties like Enrollment
+type edge struct {
+	Name  string
+	Start string
+	End   string
+}
+
+// structField is a field of a struct declared in the models
+type structField struct {
+	Name string
+	Tag  string
+	Type ast.Expr
+}
+
+// problems collects everything wrong with the models so they are reported at once
+type problems []string
+
+func (p problems) Error() string {
+	return strings.Join(p, "\n")
+}
+
+// parseModels reads the relationship fields of the structs declared in path and pairs them up.
+// relations are ordered by node type and then by the order of the fields in the struct,
+// edges are in the order they are declared
+func parseModels(path string) ([]*relation, []*edge, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	structs := map[string][]structField{}
+	var order []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			var fields []structField
+			for _, f := range st.Fields.List {
+				tag := ""
+				if f.Tag != nil {
+					tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`")).Get("gogm")
+				}
+				for _, name := range f.Names {
+					fields = append(fields, structField{Name: name.Name, Tag: tag, Type: f.Type})
+				}
+			}
+
+			structs[ts.Name.Name] = fields
+			order = append(order, ts.Name.Name)
+		}
+	}
+
+	// edges are the structs with untagged Start and End pointers to nodes, like Enrollment
+	var errs problems
+	var edgeList []*edge
+	edges := map[string][2]string{}
+	for _, name := range order {
+		e := &edge{Name: name}
+		for _, f := range structs[name] {
+			if f.Tag != "" {
+				continue
+			}
+			switch f.Name {
+			case "Start":
+				e.Start = typeName(f.Type)
+			case "End":
+				e.End = typeName(f.Type)
+			}
+		}
+		if e.Start == "" || e.End == "" {
+			continue
+		}
+
+		for _, node := range []string{e.Start, e.End} {
+			if _, ok := structs[node]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: %s is not declared in %s", name, node, path))
+			}
+		}
+
+		edges[name] = [2]string{e.Start, e.End}
+		edgeList = append(edgeList, e)
+	}
+
+	var rels []*relation
+	for _, name := range order {
+		if _, ok := edges[name]; ok {
+			continue
+		}
+
+		for _, f := range structs[name] {
+			params := parseTag(f.Tag)
+			relationship, ok := params["relationship"]
+			if !ok {
+				continue
+			}
+
+			r := &relation{Type: name, Field: f.Name, Relationship: relationship, Direction: params["direction"]}
+			if r.Direction != "incoming" && r.Direction != "outgoing" {
+				errs = append(errs, fmt.Sprintf("%s.%s: direction of %s must be incoming or outgoing, found %q", name, f.Name, relationship, r.Direction))
+				continue
+			}
+
+			typ := f.Type
+			if arr, ok := typ.(*ast.ArrayType); ok {
+				r.Many = true
+				typ = arr.Elt
+			}
+
+			target := typeName(typ)
+			if target == "" {
+				errs = append(errs, fmt.Sprintf("%s.%s: relationship fields must be pointers to a type of the models", name, f.Name))
+				continue
+			}
+
+			if ends, ok := edges[target]; ok {
+				if !r.Many {
+					errs = append(errs, fmt.Sprintf("%s.%s: fields of the edge %s must be slices", name, f.Name, target))
+					continue
+				}
+
+				// the node is the end of incoming edges and the start of outgoing ones
+				r.Edge = target
+				if r.Direction == "incoming" {
+					r.Target = ends[0]
+					target = ends[1]
+				} else {
+					r.Target = ends[1]
+					target = ends[0]
+				}
+
+				if target != name {
+					errs = append(errs, fmt.Sprintf("%s.%s: %s %s edges %s %s, not %s", name, f.Name, r.Direction, r.Edge, edgeEnd(r.Direction), target, name))
+					continue
+				}
+			} else {
+				r.Target = target
+			}
+
+			if _, ok := structs[r.Target]; !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: %s is not declared in %s", name, f.Name, r.Target, path))
+				continue
+			}
+
+			rels = append(rels, r)
+		}
+	}
+
+	for _, r := range rels {
+		var matches []*relation
+		for _, other := range rels {
+			if other != r && other.Type == r.Target && other.Target == r.Type && other.Relationship == r.Relationship &&
+				other.Edge == r.Edge && other.Direction == opposite(r.Direction) {
+				matches = append(matches, other)
+			}
+		}
+
+		switch len(matches) {
+		case 1:
+			r.OtherField = matches[0].Field
+			r.OtherMany = matches[0].Many
+		case 0:
+			errs = append(errs, fmt.Sprintf("%s.%s: %s %s has no matching %s field on %s", r.Type, r.Field, r.Direction, r.Relationship, opposite(r.Direction), r.Target))
+		default:
+			var fields []string
+			for _, match := range matches {
+				fields = append(fields, match.Type+"."+match.Field)
+			}
+			errs = append(errs, fmt.Sprintf("%s.%s: %s %s matches more than one field, %s", r.Type, r.Field, r.Direction, r.Relationship, strings.Join(fields, ", ")))
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, nil, errs
+	}
+
+	sort.SliceStable(rels, func(i, j int) bool { return rels[i].Type < rels[j].Type })
+	return rels, edgeList, nil
+}
+
+// typeName returns the name of the type expr points to, or "" if it is not a pointer to a type of this package
+func typeName(expr ast.Expr) string {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return ""
+	}
+
+	id, ok := star.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+
+	return id.Name
+}
+
+// edgeEnd describes which end of an edge the node of a field in direction is
+func edgeEnd(direction string) string {
+	if direction == "incoming" {
+		return "end at"
+	}
+	return "start at"
+}
+
+// opposite returns the direction the other end of a relationship in direction has
+func opposite(direction string) string {
+	if direction == "incoming" {
+		return "outgoing"
+	}
+	return "incoming"
+}
+
+// parseTag splits a gogm struct tag into its parameters
+func parseTag(tag string) map[string]string {
+	params := map[string]string{}
+	if tag == "" {
+		return params
+	}
+
+	for _, part := range strings.Split(tag, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		} else {
+			params[kv[0]] = ""
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,472 @@
+package main
+
+// linkSource is the template of linking.go. every relationship field gets Link and Unlink functions,
+// fields of edges with properties take the edge, single fields replace the node they were linked to before
+// and slice fields link several targets at once
+const linkSource = `// Code generated by linkgen from {{.Source}}. DO NOT EDIT.
+
+package main
+{{if .UsesFmt}}
+import (
+	"fmt"
+)
+{{end}}
+{{- range $r := .Relations}}
+{{- if $r.Edge}}
+// LinkTo{{$r.Target}}OnField{{$r.Field}} links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}.
+// note this uses the special edge {{$r.Edge}}
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}(target *{{$r.Target}}, edge *{{$r.Edge}}) error {
+	return l.LinkTo{{$r.Target}}OnField{{$r.Field}}With(nil, target, edge)
+}
+
+// LinkTo{{$r.Target}}OnField{{$r.Field}}With links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}} using opts.
+// the nodes count as linked if edge is already on {{$r.Type}}.{{$r.Field}} or {{$r.Type}} has any {{$r.Edge}} to target
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}With(opts *LinkOptions, target *{{$r.Target}}, edge *{{$r.Edge}}) error {
+	if target == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilTarget)
+	}
+
+	if edge == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilEdge)
+	}
+
+	for _, existing := range l.{{$r.Field}} {
+		if existing == edge {
+			_, err := opts.onDuplicate()
+			return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", err)
+		}
+
+		obj := existing.{{if eq $r.Direction "incoming"}}GetStartNode{{else}}GetEndNode{{end}}()
+		checkObj, ok := obj.(*{{$r.Target}})
+		if !ok {
+			return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, expected [*{{$r.Target}}] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if same{{$r.Target}}(checkObj, target) {
+			if skip, err := opts.onDuplicate(); skip {
+				return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", err)
+			}
+			break
+		}
+	}
+
+	err := checkLink(l, "{{$r.Field}}", target, "{{$r.OtherField}}", edge)
+	if err != nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", err)
+	}
+
+	err = edge.SetStartNode({{if eq $r.Direction "incoming"}}target{{else}}l{{end}})
+	if err != nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	err = edge.SetEndNode({{if eq $r.Direction "incoming"}}l{{else}}target{{end}})
+	if err != nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	if l.{{$r.Field}} == nil {
+		l.{{$r.Field}} = make([]*{{$r.Edge}}, 1, 1)
+		l.{{$r.Field}}[0] = edge
+	} else {
+		l.{{$r.Field}} = append(l.{{$r.Field}}, edge)
+	}
+
+	if target.{{$r.OtherField}} == nil {
+		target.{{$r.OtherField}} = make([]*{{$r.Edge}}, 1, 1)
+		target.{{$r.OtherField}}[0] = edge
+	} else {
+		target.{{$r.OtherField}} = append(target.{{$r.OtherField}}, edge)
+	}
+
+	return nil
+}
+
+// UnlinkFrom{{$r.Target}}OnField{{$r.Field}} unlinks {{$r.Type}} from {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}.
+// also note this uses the special edge {{$r.Edge}}, only the first edge to target is removed
+func (l *{{$r.Type}}) UnlinkFrom{{$r.Target}}OnField{{$r.Field}}(target *{{$r.Target}}) error {
+	if target == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilTarget)
+	}
+
+	// match the node by pointer first so unsaved nodes without a uuid are told apart
+	i := -1
+	for j, unlinkTarget := range l.{{$r.Field}} {
+		obj := unlinkTarget.{{if eq $r.Direction "incoming"}}GetStartNode{{else}}GetEndNode{{end}}()
+		checkObj, ok := obj.(*{{$r.Target}})
+		if !ok {
+			return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, expected [*{{$r.Target}}] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if checkObj == target {
+			i = j
+			break
+		}
+
+		if i == -1 && same{{$r.Target}}(checkObj, target) {
+			i = j
+		}
+	}
+
+	if i == -1 {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNotLinked)
+	}
+
+	return l.UnlinkFrom{{$r.Target}}OnField{{$r.Field}}ByEdge(l.{{$r.Field}}[i])
+}
+
+// UnlinkFrom{{$r.Target}}OnField{{$r.Field}}ByEdge removes edge from {{$r.Type}}.{{$r.Field}} and from {{$r.Target}}.{{$r.OtherField}} of the {{$r.Target}} it connects to.
+// use this to remove one specific edge when there are several between the same nodes
+func (l *{{$r.Type}}) UnlinkFrom{{$r.Target}}OnField{{$r.Field}}ByEdge(edge *{{$r.Edge}}) error {
+	if edge == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilEdge)
+	}
+
+	i := indexOf{{$r.Edge}}(l.{{$r.Field}}, edge)
+	if i == -1 {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNotLinked)
+	}
+
+	// edge may only match by uuid, work with the one that is actually linked
+	edge = l.{{$r.Field}}[i]
+
+	obj := edge.{{if eq $r.Direction "incoming"}}GetStartNode{{else}}GetEndNode{{end}}()
+	target, ok := obj.(*{{$r.Target}})
+	if !ok {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, expected [*{{$r.Target}}] found [%T]", ErrEdgeTypeMismatch, obj))
+	}
+
+	a := &l.{{$r.Field}}
+	(*a)[i] = (*a)[len(*a)-1]
+	(*a)[len(*a)-1] = nil
+	*a = (*a)[:len(*a)-1]
+
+	if target == nil {
+		return nil
+	}
+
+	if j := indexOf{{$r.Edge}}(target.{{$r.OtherField}}, edge); j != -1 {
+		a := &target.{{$r.OtherField}}
+		(*a)[j] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// UnlinkFrom{{$r.Target}}OnField{{$r.Field}}Where removes every {{$r.Edge}} between {{$r.Type}} and target that match accepts.
+// a nil match accepts every edge
+func (l *{{$r.Type}}) UnlinkFrom{{$r.Target}}OnField{{$r.Field}}Where(target *{{$r.Target}}, match func(edge *{{$r.Edge}}) bool) error {
+	if target == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilTarget)
+	}
+
+	var edges []*{{$r.Edge}}
+	for _, unlinkTarget := range l.{{$r.Field}} {
+		obj := unlinkTarget.{{if eq $r.Direction "incoming"}}GetStartNode{{else}}GetEndNode{{end}}()
+		checkObj, ok := obj.(*{{$r.Target}})
+		if !ok {
+			return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", fmt.Errorf("%w, expected [*{{$r.Target}}] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if same{{$r.Target}}(checkObj, target) && (match == nil || match(unlinkTarget)) {
+			edges = append(edges, unlinkTarget)
+		}
+	}
+
+	if len(edges) == 0 {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNotLinked)
+	}
+
+	for _, edge := range edges {
+		err := l.UnlinkFrom{{$r.Target}}OnField{{$r.Field}}ByEdge(edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAllFrom{{$r.Target}}OnField{{$r.Field}} removes every {{$r.Edge}} between {{$r.Type}} and target
+func (l *{{$r.Type}}) UnlinkAllFrom{{$r.Target}}OnField{{$r.Field}}(target *{{$r.Target}}) error {
+	return l.UnlinkFrom{{$r.Target}}OnField{{$r.Field}}Where(target, nil)
+}
+{{else if not $r.Many}}
+//LinkTo{{$r.Target}}OnField{{$r.Field}} links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}.
+//the {{$r.Type}} is removed from {{$r.OtherField}} of the {{$r.Target}} it was linked to before
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}(target *{{$r.Target}}) error {
+	return l.LinkTo{{$r.Target}}OnField{{$r.Field}}With(nil, target)
+}
+
+//LinkTo{{$r.Target}}OnField{{$r.Field}}With links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}} using opts
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}With(opts *LinkOptions, target *{{$r.Target}}) error {
+	if target == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilTarget)
+	}
+
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if same{{$r.Target}}(l.{{$r.Field}}, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", err)
+	}
+
+	if err := checkLink(l, "{{$r.Field}}", target, "{{$r.OtherField}}", nil); err != nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", err)
+	}
+
+	if previous := l.{{$r.Field}}; previous != nil && !same{{$r.Target}}(previous, target) {
+		if i := indexOf{{$r.Type}}(previous.{{$r.OtherField}}, l); i != -1 {
+			a := &previous.{{$r.OtherField}}
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
+
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "{{$r.OtherField}}"})
+	}
+
+	l.{{$r.Field}} = target
+
+	if target.{{$r.OtherField}} == nil {
+		target.{{$r.OtherField}} = make([]*{{$r.Type}}, 1, 1)
+		target.{{$r.OtherField}}[0] = l
+	} else {
+		target.{{$r.OtherField}} = append(target.{{$r.OtherField}}, l)
+	}
+
+	return nil
+}
+
+//UnlinkFrom{{$r.Target}}OnField{{$r.Field}} unlinks {{$r.Type}} from {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}
+func (l *{{$r.Type}}) UnlinkFrom{{$r.Target}}OnField{{$r.Field}}(target *{{$r.Target}}) error {
+	if target == nil {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNilTarget)
+	}
+
+	if !same{{$r.Target}}(l.{{$r.Field}}, target) {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNotLinked)
+	}
+
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.{{$r.Field}}
+	l.{{$r.Field}} = nil
+
+	if i := indexOf{{$r.Type}}(target.{{$r.OtherField}}, l); i != -1 {
+		a := &target.{{$r.OtherField}}
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+{{else}}
+// LinkTo{{$r.Target}}OnField{{$r.Field}} links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}
+{{- if not $r.OtherMany}}.
+// targets are removed from {{$r.Field}} of the {{$r.Type}} they were linked to before
+{{- end}}
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}(targets ...*{{$r.Target}}) error {
+	return l.LinkTo{{$r.Target}}OnField{{$r.Field}}With(nil, targets...)
+}
+
+// LinkTo{{$r.Target}}OnField{{$r.Field}}With links {{$r.Type}} to {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}} using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *{{$r.Type}}) LinkTo{{$r.Target}}OnField{{$r.Field}}With(opts *LinkOptions, targets ...*{{$r.Target}}) error {
+	if len(targets) == 0 {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, ErrNilTarget)
+		}
+
+		if indexOf{{$r.Target}}(l.{{$r.Field}}, target) != -1 || indexOf{{$r.Target}}(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, err)
+			}
+		}
+
+		if err := checkLink(l, "{{$r.Field}}", target, "{{$r.OtherField}}", nil); err != nil {
+			return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, err)
+		}
+
+		if err := checkPendingLink(l, "{{$r.Field}}", target, pending); err != nil {
+			return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOf{{$r.Target}}(l.{{$r.Field}}, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
+{{if not $r.OtherMany}}
+		if previous := target.{{$r.OtherField}}; previous != nil && !same{{$r.Type}}(previous, l) {
+			if i := indexOf{{$r.Target}}(previous.{{$r.Field}}, target); i != -1 {
+				a := &previous.{{$r.Field}}
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "{{$r.Field}}"})
+		}
+{{end}}
+		if l.{{$r.Field}} == nil {
+			l.{{$r.Field}} = make([]*{{$r.Target}}, 1, 1)
+			l.{{$r.Field}}[0] = target
+		} else {
+			l.{{$r.Field}} = append(l.{{$r.Field}}, target)
+		}
+{{if $r.OtherMany}}
+		if target.{{$r.OtherField}} == nil {
+			target.{{$r.OtherField}} = make([]*{{$r.Type}}, 1, 1)
+			target.{{$r.OtherField}}[0] = l
+		} else {
+			target.{{$r.OtherField}} = append(target.{{$r.OtherField}}, l)
+		}
+{{- else}}
+		target.{{$r.OtherField}} = l
+{{- end}}
+	}
+
+	return nil
+}
+
+//UnlinkFrom{{$r.Target}}OnField{{$r.Field}} unlinks {{$r.Type}} from {{$r.Target}} on the fields {{$r.Type}}.{{$r.Field}} and {{$r.Target}}.{{$r.OtherField}}.
+//either every target is unlinked or, if one of them is rejected, none are
+func (l *{{$r.Type}}) UnlinkFrom{{$r.Target}}OnField{{$r.Field}}(targets ...*{{$r.Target}}) error {
+	if len(targets) == 0 {
+		return newLinkError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, ErrNilTarget)
+		}
+
+		if indexOf{{$r.Target}}(l.{{$r.Field}}, target) == -1 || indexOf{{$r.Target}}(targets[:i], target) != -1 {
+			return newLinkTargetError("{{$r.Type}}", "{{$r.Field}}", "{{$r.Relationship}}", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOf{{$r.Target}}(l.{{$r.Field}}, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.{{$r.Field}}[i]
+
+		a := &l.{{$r.Field}}
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+{{if $r.OtherMany}}
+		if j := indexOf{{$r.Type}}(target.{{$r.OtherField}}, l); j != -1 {
+			a := &target.{{$r.OtherField}}
+			(*a)[j] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
+{{- else}}
+		if same{{$r.Type}}(target.{{$r.OtherField}}, l) {
+			target.{{$r.OtherField}} = nil
+		}
+{{- end}}
+	}
+
+	return nil
+}
+{{end}}
+{{- end}}
+{{- range $n := .Nodes}}
+// same{{$n}} checks if a and b are the same {{$n}}, matching by pointer first and uuid second
+func same{{$n}}(a, b *{{$n}}) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+{{end}}
+{{- range $n := .Slices}}
+// indexOf{{$n}} returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOf{{$n}}(nodes []*{{$n}}, node *{{$n}}) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+{{end}}`
+
+// edgeSource is the template of edges.go, the gogm.IEdge methods of every struct with Start and End node fields
+const edgeSource = `// Code generated by linkgen from {{.Source}}. DO NOT EDIT.
+
+package main
+{{if .Edges}}
+import (
+	"fmt"
+	"reflect"
+)
+{{end}}
+{{- range $e := .Edges}}
+// GetStartNode returns the {{$e.Start}} the {{$e.Name}} starts at
+func (e *{{$e.Name}}) GetStartNode() interface{} {
+	return e.Start
+}
+
+// GetStartNodeType returns the type of the start node, *{{$e.Start}}
+func (e *{{$e.Name}}) GetStartNodeType() reflect.Type {
+	return reflect.TypeOf(&{{$e.Start}}{})
+}
+
+// SetStartNode sets the start node, v has to be a *{{$e.Start}}
+func (e *{{$e.Name}}) SetStartNode(v interface{}) error {
+	node, ok := v.(*{{$e.Start}})
+	if !ok {
+		return fmt.Errorf("unable to convert to [*{{$e.Start}}] from [%T]", v)
+	}
+
+	e.Start = node
+	return nil
+}
+
+// GetEndNode returns the {{$e.End}} the {{$e.Name}} ends at
+func (e *{{$e.Name}}) GetEndNode() interface{} {
+	return e.End
+}
+
+// GetEndNodeType returns the type of the end node, *{{$e.End}}
+func (e *{{$e.Name}}) GetEndNodeType() reflect.Type {
+	return reflect.TypeOf(&{{$e.End}}{})
+}
+
+// SetEndNode sets the end node, v has to be a *{{$e.End}}
+func (e *{{$e.Name}}) SetEndNode(v interface{}) error {
+	node, ok := v.(*{{$e.End}})
+	if !ok {
+		return fmt.Errorf("unable to convert to [*{{$e.End}}] from [%T]", v)
+	}
+
+	e.End = node
+	return nil
+}
+{{end}}`
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// environment variables read by ConfigLoader
+const (
+	envConfigFile    = "GOGM_CONFIG"
+	envHost          = "GOGM_HOST"
+	envPort          = "GOGM_PORT"
+	envIsCluster     = "GOGM_IS_CLUSTER"
+	envUsername      = "GOGM_USERNAME"
+	envPassword      = "GOGM_PASSWORD"
+	envPoolSize      = "GOGM_POOL_SIZE"
+	envIndexStrategy = "GOGM_INDEX_STRATEGY"
+)
+
+// redactedPassword replaces the password when a config is printed
+const redactedPassword = "********"
+
+// DefaultConfig returns the config for the single node neo4j in docker-compose.yaml
+func DefaultConfig() *gogm.Config {
+	return &gogm.Config{
+		Host:          "0.0.0.0",
+		Port:          7687,
+		IsCluster:     false,
+		Username:      "neo4j",
+		Password:      "password",
+		PoolSize:      50,
+		IndexStrategy: gogm.ASSERT_INDEX,
+	}
+}
+
+// ConfigLoader builds a gogm.Config from, in order of precedence:
+// command line flags, environment variables, a YAML or JSON file and DefaultConfig.
+// a source only overrides the values it actually sets
+type ConfigLoader struct {
+	// LookupEnv reads environment variables, defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+
+	fs *flag.FlagSet
+
+	file          string
+	host          string
+	port          int
+	isCluster     bool
+	username      string
+	password      string
+	poolSize      int
+	indexStrategy string
+}
+
+// NewConfigLoader registers the config flags on fs, call Load after fs has been parsed
+func NewConfigLoader(fs *flag.FlagSet) *ConfigLoader {
+	c := &ConfigLoader{
+		LookupEnv: os.LookupEnv,
+		fs:        fs,
+	}
+
+	fs.StringVar(&c.file, "config", "", "YAML or JSON file with the gogm config (env "+envConfigFile+")")
+	fs.StringVar(&c.host, "host", "", "neo4j host (env "+envHost+")")
+	fs.IntVar(&c.port, "port", 0, "neo4j bolt port (env "+envPort+")")
+	fs.BoolVar(&c.isCluster, "cluster", false, "connect to a casual cluster (env "+envIsCluster+")")
+	fs.StringVar(&c.username, "username", "", "neo4j username (env "+envUsername+")")
+	fs.StringVar(&c.password, "password", "", "neo4j password (env "+envPassword+")")
+	fs.IntVar(&c.poolSize, "pool-size", 0, "size of the connection pool (env "+envPoolSize+")")
+	fs.StringVar(&c.indexStrategy, "index-strategy", "", "assert, validate or ignore (env "+envIndexStrategy+")")
+
+	return c
+}
+
+// Load builds and validates the config
+func (c *ConfigLoader) Load() (*gogm.Config, error) {
+	conf := DefaultConfig()
+
+	set := map[string]bool{}
+	c.fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	file := c.file
+	if !set["config"] {
+		file, _ = c.LookupEnv(envConfigFile)
+	}
+
+	if file != "" {
+		err := loadConfigFile(conf, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := c.applyEnv(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if set["host"] {
+		conf.Host = c.host
+	}
+	if set["port"] {
+		conf.Port = c.port
+	}
+	if set["cluster"] {
+		conf.IsCluster = c.isCluster
+	}
+	if set["username"] {
+		conf.Username = c.username
+	}
+	if set["password"] {
+		conf.Password = c.password
+	}
+	if set["pool-size"] {
+		conf.PoolSize = c.poolSize
+	}
+	if set["index-strategy"] {
+		conf.IndexStrategy, err = parseIndexStrategy(c.indexStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("flag -index-strategy: %w", err)
+		}
+	}
+
+	err = ValidateConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// applyEnv overrides conf with the environment variables that are set
+func (c *ConfigLoader) applyEnv(conf *gogm.Config) error {
+	if v, ok := c.LookupEnv(envHost); ok {
+		conf.Host = v
+	}
+
+	if v, ok := c.LookupEnv(envPort); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envPort, err)
+		}
+		conf.Port = port
+	}
+
+	if v, ok := c.LookupEnv(envIsCluster); ok {
+		isCluster, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envIsCluster, err)
+		}
+		conf.IsCluster = isCluster
+	}
+
+	if v, ok := c.LookupEnv(envUsername); ok {
+		conf.Username = v
+	}
+
+	if v, ok := c.LookupEnv(envPassword); ok {
+		conf.Password = v
+	}
+
+	if v, ok := c.LookupEnv(envPoolSize); ok {
+		poolSize, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envPoolSize, err)
+		}
+		conf.PoolSize = poolSize
+	}
+
+	if v, ok := c.LookupEnv(envIndexStrategy); ok {
+		strategy, err := parseIndexStrategy(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envIndexStrategy, err)
+		}
+		conf.IndexStrategy = strategy
+	}
+
+	return nil
+}
+
+// loadConfigFile overrides conf with the keys set in the file, the format is picked by the extension.
+// the keys are the yaml/json tags of gogm.Config, unknown keys are an error
+func loadConfigFile(conf *gogm.Config, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file, %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(conf)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, conf)
+	default:
+		return fmt.Errorf("config file %s must end in .json, .yaml or .yml", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// parseIndexStrategy accepts the name of a strategy or its number
+func parseIndexStrategy(s string) (gogm.IndexStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "assert", "assert_index", "0":
+		return gogm.ASSERT_INDEX, nil
+	case "validate", "validate_index", "1":
+		return gogm.VALIDATE_INDEX, nil
+	case "ignore", "ignore_index", "2":
+		return gogm.IGNORE_INDEX, nil
+	default:
+		return 0, fmt.Errorf("unknown index strategy %q, expected assert, validate or ignore", s)
+	}
+}
+
+// ConfigError lists every problem found by ValidateConfig
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateConfig checks that everything gogm needs to connect is set
+func ValidateConfig(conf *gogm.Config) error {
+	if conf == nil {
+		return &ConfigError{Problems: []string{"config is nil"}}
+	}
+
+	var problems []string
+
+	if conf.Host == "" {
+		problems = append(problems, "host is required")
+	}
+	if conf.Port <= 0 || conf.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("port %v is not between 1 and 65535", conf.Port))
+	}
+	if conf.Username == "" {
+		problems = append(problems, "username is required")
+	}
+	if conf.Password == "" {
+		problems = append(problems, "password is required")
+	}
+	if conf.PoolSize <= 0 {
+		problems = append(problems, fmt.Sprintf("pool_size %v must be greater than 0", conf.PoolSize))
+	}
+	switch conf.IndexStrategy {
+	case gogm.ASSERT_INDEX, gogm.VALIDATE_INDEX, gogm.IGNORE_INDEX:
+	default:
+		problems = append(problems, fmt.Sprintf("index_strategy %v is unknown", conf.IndexStrategy))
+	}
+
+	if len(problems) != 0 {
+		return &ConfigError{Problems: problems}
+	}
+
+	return nil
+}
+
+// FormatConfig renders conf as YAML with the password redacted, so it is safe to print or log
+func FormatConfig(conf *gogm.Config) string {
+	redacted := *conf
+	if redacted.Password != "" {
+		redacted.Password = redactedPassword
+	}
+
+	out, err := yaml.Marshal(&redacted)
+	if err != nil {
+		// gogm.Config only holds plain values, this does not happen
+		return err.Error()
+	}
+
+	return string(out)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/mindstand/gogm"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig runs a ConfigLoader on the command line args with env as the environment
+func loadTestConfig(t *testing.T, args []string, env map[string]string) (*gogm.Config, error) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewConfigLoader(fs)
+	loader.LookupEnv = func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loader.Load()
+}
+
+// writeTestFile writes content to name in a new temporary directory and returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "gogm-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	yamlFile := writeTestFile(t, "gogm.yaml", "host: file-host\nport: 7001\nusername: file-user\npool_size: 5\n")
+	defer os.RemoveAll(filepath.Dir(yamlFile))
+	jsonFile := writeTestFile(t, "gogm.json", `{"host": "json-host", "index_strategy": 2}`)
+	defer os.RemoveAll(filepath.Dir(jsonFile))
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(conf *gogm.Config)
+	}{
+		{
+			name: "defaults",
+			want: func(conf *gogm.Config) {},
+		},
+		{
+			name: "yaml file from the environment",
+			env:  map[string]string{envConfigFile: yamlFile},
+			want: func(conf *gogm.Config) {
+				conf.Host, conf.Port, conf.Username, conf.PoolSize = "file-host", 7001, "file-user", 5
+			},
+		},
+		{
+			name: "json file from a flag",
+			args: []string{"-config", jsonFile},
+			env:  map[string]string{envConfigFile: yamlFile},
+			want: func(conf *gogm.Config) {
+				conf.Host, conf.IndexStrategy = "json-host", gogm.IGNORE_INDEX
+			},
+		},
+		{
+			name: "environment over file",
+			env:  map[string]string{envConfigFile: yamlFile, envHost: "env-host", envIsCluster: "true", envIndexStrategy: "validate"},
+			want: func(conf *gogm.Config) {
+				conf.Host, conf.Port, conf.Username, conf.PoolSize = "env-host", 7001, "file-user", 5
+				conf.IsCluster, conf.IndexStrategy = true, gogm.VALIDATE_INDEX
+			},
+		},
+		{
+			name: "flags over environment",
+			args: []string{"-config", yamlFile, "-host", "flag-host", "-port", "7002", "-cluster=false"},
+			env:  map[string]string{envHost: "env-host", envPort: "7003", envIsCluster: "true", envPassword: "secret"},
+			want: func(conf *gogm.Config) {
+				conf.Host, conf.Port, conf.Username, conf.PoolSize = "flag-host", 7002, "file-user", 5
+				conf.Password = "secret"
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := loadTestConfig(t, tt.args, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := DefaultConfig()
+			tt.want(want)
+			if !reflect.DeepEqual(conf, want) {
+				t.Errorf("got %+v, want %+v", conf, want)
+			}
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	unknownKey := writeTestFile(t, "gogm.yaml", "host: file-host\nhots: typo\n")
+	defer os.RemoveAll(filepath.Dir(unknownKey))
+	badExtension := writeTestFile(t, "gogm.toml", "host = \"file-host\"\n")
+	defer os.RemoveAll(filepath.Dir(badExtension))
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"bad port", nil, map[string]string{envPort: "bolt"}, envPort},
+		{"bad cluster", nil, map[string]string{envIsCluster: "maybe"}, envIsCluster},
+		{"bad index strategy flag", []string{"-index-strategy", "sometimes"}, nil, "flag -index-strategy"},
+		{"unknown key", []string{"-config", unknownKey}, nil, "hots"},
+		{"bad extension", []string{"-config", badExtension}, nil, "must end in .json, .yaml or .yml"},
+		{"missing file", []string{"-config", "missing.yaml"}, nil, "failed to read config file"},
+		{"invalid values", []string{"-host", "", "-port", "70000"}, nil, "invalid config: host is required; port 70000 is not between 1 and 65535"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tt.args, tt.env)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	err := ValidateConfig(DefaultConfig())
+	if err != nil {
+		t.Errorf("the default config is invalid: %v", err)
+	}
+
+	err = ValidateConfig(&gogm.Config{IndexStrategy: 7})
+	var confErr *ConfigError
+	if !errors.As(err, &confErr) {
+		t.Fatalf("got %v, want a *ConfigError", err)
+	}
+
+	want := []string{
+		"host is required",
+		"port 0 is not between 1 and 65535",
+		"username is required",
+		"password is required",
+		"pool_size 0 must be greater than 0",
+		"index_strategy 7 is unknown",
+	}
+	if !reflect.DeepEqual(confErr.Problems, want) {
+		t.Errorf("got %q, want %q", confErr.Problems, want)
+	}
+}
+
+func TestFormatConfigRedactsPassword(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Password = "hunter2"
+
+	out := FormatConfig(conf)
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("the password is printed:\n%s", out)
+	}
+	if !strings.Contains(out, "password: '"+redactedPassword+"'") {
+		t.Errorf("the password is not redacted:\n%s", out)
+	}
+	if conf.Password != "hunter2" {
+		t.Error("formatting changed the config")
+	}
+
+	conf.Password = ""
+	if out := FormatConfig(conf); strings.Contains(out, redactedPassword) {
+		t.Errorf("an empty password is shown as set:\n%s", out)
+	}
+}
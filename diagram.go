@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Diagram is a graph of boxes and arrows that can be written as Graphviz DOT or Mermaid
+type Diagram struct {
+	// Name is the name of the DOT digraph, it must not be a DOT keyword like graph
+	Name  string
+	Nodes []*DiagramNode
+	Links []*DiagramLink
+}
+
+// DiagramNode is a box, Lines are written below the title
+type DiagramNode struct {
+	ID    string
+	Title string
+	Lines []string
+}
+
+// DiagramLink is an arrow from one box to another, Lines are written below the label
+type DiagramLink struct {
+	From  string
+	To    string
+	Label string
+	Lines []string
+	// Undirected links are drawn without an arrow head, for relationships with direction both or none
+	Undirected bool
+}
+
+// SchemaDiagram draws the node types of types, passed as pointers like to gogm.Init, with their properties.
+// every relationship is drawn once in its direction, labeled with its cardinality and the properties of its edge
+func SchemaDiagram(types ...interface{}) (*Diagram, error) {
+	diagram := &Diagram{Name: "schema"}
+
+	for _, typ := range types {
+		schema, err := schemaFor(reflect.TypeOf(typ))
+		if err != nil {
+			return nil, err
+		}
+
+		if schema.IsEdge {
+			continue
+		}
+
+		diagram.Nodes = append(diagram.Nodes, &DiagramNode{
+			ID:    schema.Label,
+			Title: schema.Label,
+			Lines: diagramProperties(schema),
+		})
+
+		for _, rel := range schema.Relationships {
+			target, err := schemaFor(rel.Target)
+			if err != nil {
+				return nil, err
+			}
+
+			// incoming fields are drawn from the other end, undirected ones from the end with the lower label
+			undirected := rel.Direction == directionBoth || rel.Direction == directionNone
+			if rel.Direction == directionIncoming || (undirected && target.Label < schema.Label) {
+				continue
+			}
+
+			// the counterpart says how many of this node the target can have
+			many := false
+			if other := target.relationshipTo(rel.Relationship, oppositeDirection(rel.Direction), schema.Type); other != nil {
+				many = other.Many
+			}
+
+			link := &DiagramLink{
+				From:       schema.Label,
+				To:         target.Label,
+				Label:      rel.Relationship + " " + cardinality(many, rel.Many),
+				Undirected: undirected,
+			}
+
+			if rel.Edge != nil {
+				edge, err := schemaFor(rel.Edge)
+				if err != nil {
+					return nil, err
+				}
+
+				link.Lines = append([]string{edge.Label}, diagramProperties(edge)...)
+			}
+
+			diagram.Links = append(diagram.Links, link)
+		}
+	}
+
+	return diagram, nil
+}
+
+// diagramProperties lists the stored properties of a node or edge type, the graph id and uuid are left out
+func diagramProperties(schema *nodeSchema) []string {
+	var lines []string
+	for _, prop := range schema.Properties {
+		if prop.Name == "id" || prop.Name == "uuid" {
+			continue
+		}
+
+		if prop.Unique {
+			lines = append(lines, prop.Name+" (unique)")
+		} else {
+			lines = append(lines, prop.Name)
+		}
+	}
+
+	return lines
+}
+
+// cardinality writes how many starts and ends a relationship has, like 1:n
+func cardinality(manyStarts, manyEnds bool) string {
+	switch {
+	case manyStarts && manyEnds:
+		return "n:m"
+	case manyStarts:
+		return "n:1"
+	case manyEnds:
+		return "1:n"
+	default:
+		return "1:1"
+	}
+}
+
+// InstanceDiagram draws the nodes and relationships of a snapshot, nodes are titled with their label and name
+// and relationships with edges list the properties of the edge
+func InstanceDiagram(snapshot *Snapshot) *Diagram {
+	diagram := &Diagram{Name: "school"}
+
+	ids := map[string]string{}
+	for i, node := range snapshot.Nodes {
+		ids[node.UUID] = fmt.Sprintf("n%v", i)
+
+		title := node.Type
+		if name, ok := node.Properties["name"]; ok {
+			title += " " + fmt.Sprint(name)
+		}
+
+		diagram.Nodes = append(diagram.Nodes, &DiagramNode{
+			ID:    ids[node.UUID],
+			Title: title,
+			Lines: instanceProperties(node.Properties, "name"),
+		})
+	}
+
+	for _, rel := range snapshot.Relationships {
+		from, to := ids[rel.Start], ids[rel.End]
+		if from == "" || to == "" {
+			continue
+		}
+
+		diagram.Links = append(diagram.Links, &DiagramLink{
+			From:  from,
+			To:    to,
+			Label: rel.Type,
+			Lines: instanceProperties(rel.Properties),
+		})
+	}
+
+	return diagram
+}
+
+// instanceProperties writes properties as "name: value" lines ordered by name, times are written as dates
+func instanceProperties(props map[string]interface{}, skip ...string) []string {
+	var lines []string
+	for name, value := range props {
+		skipped := false
+		for _, s := range skip {
+			skipped = skipped || s == name
+		}
+		if skipped {
+			continue
+		}
+
+		if t, ok := value.(time.Time); ok {
+			value = t.Format("2006-01-02")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %v", name, value))
+	}
+
+	sort.Strings(lines)
+	return lines
+}
+
+// WriteDOT writes the diagram as a Graphviz digraph, render it with `dot -Tsvg`
+func (d *Diagram) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", d.Name)
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, node := range d.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s];\n", node.ID, dotLabel(node.Title, node.Lines))
+	}
+
+	for _, link := range d.Links {
+		attrs := "label=" + dotLabel(link.Label, link.Lines)
+		if link.Undirected {
+			attrs += ", dir=none"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", link.From, link.To, attrs)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotLabel quotes a title and its lines as a DOT string, one line each
+func dotLabel(title string, lines []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	parts := []string{escape.Replace(title)}
+	for _, line := range lines {
+		parts = append(parts, escape.Replace(line))
+	}
+
+	return `"` + strings.Join(parts, `\n`) + `"`
+}
+
+// WriteMermaid writes the diagram as a Mermaid flowchart, it renders in markdown files on github
+func (d *Diagram) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	for _, node := range d.Nodes {
+		fmt.Fprintf(&b, "  %s[%s]\n", node.ID, mermaidLabel(node.Title, node.Lines))
+	}
+
+	for _, link := range d.Links {
+		arrow := "-->"
+		if link.Undirected {
+			arrow = "---"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", link.From, arrow, mermaidLabel(link.Label, link.Lines), link.To)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidLabel quotes a title and its lines as a Mermaid string, one line each
+func mermaidLabel(title string, lines []string) string {
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ")
+
+	parts := []string{escape.Replace(title)}
+	for _, line := range lines {
+		parts = append(parts, escape.Replace(line))
+	}
+
+	return `"` + strings.Join(parts, "<br/>") + `"`
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagramOutput(t *testing.T) {
+	schema, err := SchemaDiagram(&schemaTestParent{}, &schemaTestChild{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the relationship to the node missing from the snapshot is left out
+	instance := InstanceDiagram(&Snapshot{
+		Nodes: []*SnapshotNode{
+			{Type: "Student", UUID: "s", Properties: map[string]interface{}{"name": `eric "the" <red>`}},
+			{Type: "CourseOffering", UUID: "o", Properties: map[string]interface{}{"name": "cs341-0 Fall 2026", "capacity": 30}},
+		},
+		Relationships: []*SnapshotRelationship{
+			{Type: "ENROLLED", Start: "s", End: "o", Edge: "Enrollment", Properties: map[string]interface{}{
+				"status":        EnrollmentEnrolled,
+				"enrolled_date": testDate.Add(10 * time.Hour),
+			}},
+			{Type: "MEETS_IN", Start: "o", End: "r"},
+		},
+	})
+
+	for _, tt := range []struct {
+		name    string
+		diagram *Diagram
+		write   func(d *Diagram, buf *bytes.Buffer) error
+		want    string
+	}{
+		{
+			name:    "schema dot",
+			diagram: schema,
+			write:   func(d *Diagram, buf *bytes.Buffer) error { return d.WriteDOT(buf) },
+			want: `digraph schema {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+  schemaTestParent [label="schemaTestParent\nname"];
+  schemaTestChild [label="schemaTestChild"];
+  schemaTestParent -> schemaTestChild [label="PARENT_OF 1:n"];
+}
+`,
+		},
+		{
+			name:    "schema mermaid",
+			diagram: schema,
+			write:   func(d *Diagram, buf *bytes.Buffer) error { return d.WriteMermaid(buf) },
+			want: `flowchart LR
+  schemaTestParent["schemaTestParent<br/>name"]
+  schemaTestChild["schemaTestChild"]
+  schemaTestParent -->|"PARENT_OF 1:n"| schemaTestChild
+`,
+		},
+		{
+			name:    "instance dot",
+			diagram: instance,
+			write:   func(d *Diagram, buf *bytes.Buffer) error { return d.WriteDOT(buf) },
+			want: `digraph school {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+  n0 [label="Student eric \"the\" <red>"];
+  n1 [label="CourseOffering cs341-0 Fall 2026\ncapacity: 30"];
+  n0 -> n1 [label="ENROLLED\nenrolled_date: 2026-09-01\nstatus: enrolled"];
+}
+`,
+		},
+		{
+			name:    "instance mermaid",
+			diagram: instance,
+			write:   func(d *Diagram, buf *bytes.Buffer) error { return d.WriteMermaid(buf) },
+			want: `flowchart LR
+  n0["Student eric #quot;the#quot; #lt;red#gt;"]
+  n1["CourseOffering cs341-0 Fall 2026<br/>capacity: 30"]
+  n0 -->|"ENROLLED<br/>enrolled_date: 2026-09-01<br/>status: enrolled"| n1
+`,
+		},
+		{
+			name:    "undirected",
+			diagram: &Diagram{Name: "d", Nodes: []*DiagramNode{{ID: "a", Title: "a"}}, Links: []*DiagramLink{{From: "a", To: "a", Label: "KNOWS", Undirected: true}}},
+			write:   func(d *Diagram, buf *bytes.Buffer) error { return d.WriteMermaid(buf) },
+			want:    "flowchart LR\n  a[\"a\"]\n  a ---|\"KNOWS\"| a\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.write(tt.diagram, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaDiagramOfTheModels(t *testing.T) {
+	diagram, err := SchemaDiagram(registeredTypes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// edges are drawn on the relationships using them, not as boxes
+	if len(diagram.Nodes) != 8 {
+		t.Errorf("got %v boxes, want one for each of the 8 node types", len(diagram.Nodes))
+	}
+
+	// every relationship is drawn once, from its outgoing end
+	links := map[string]*DiagramLink{}
+	for _, link := range diagram.Links {
+		key := link.From + " " + link.Label + " " + link.To
+		if links[key] != nil {
+			t.Errorf("%s is drawn twice", key)
+		}
+		links[key] = link
+	}
+
+	for _, want := range []string{
+		"Department CURRICULUM 1:n Subject",
+		"Teacher TEACHES_CLASS 1:n CourseOffering",
+		"Course REQUIRES n:m Course",
+		"Student ENROLLED n:m CourseOffering",
+		"CourseOffering MEETS_IN n:m Room",
+	} {
+		if links[want] == nil {
+			t.Errorf("%s is missing", want)
+		}
+	}
+
+	if enrolled := links["Student ENROLLED n:m CourseOffering"]; enrolled != nil && strings.Join(enrolled.Lines[:3], " ") != "Enrollment status enrolled_date" {
+		t.Errorf("ENROLLED lists %q, want the Enrollment edge and its properties", enrolled.Lines)
+	}
+}
@@ -0,0 +1,92 @@
+// Code generated by linkgen from models.go. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetStartNode returns the Student the Enrollment starts at
+func (e *Enrollment) GetStartNode() interface{} {
+	return e.Start
+}
+
+// GetStartNodeType returns the type of the start node, *Student
+func (e *Enrollment) GetStartNodeType() reflect.Type {
+	return reflect.TypeOf(&Student{})
+}
+
+// SetStartNode sets the start node, v has to be a *Student
+func (e *Enrollment) SetStartNode(v interface{}) error {
+	node, ok := v.(*Student)
+	if !ok {
+		return fmt.Errorf("unable to convert to [*Student] from [%T]", v)
+	}
+
+	e.Start = node
+	return nil
+}
+
+// GetEndNode returns the CourseOffering the Enrollment ends at
+func (e *Enrollment) GetEndNode() interface{} {
+	return e.End
+}
+
+// GetEndNodeType returns the type of the end node, *CourseOffering
+func (e *Enrollment) GetEndNodeType() reflect.Type {
+	return reflect.TypeOf(&CourseOffering{})
+}
+
+// SetEndNode sets the end node, v has to be a *CourseOffering
+func (e *Enrollment) SetEndNode(v interface{}) error {
+	node, ok := v.(*CourseOffering)
+	if !ok {
+		return fmt.Errorf("unable to convert to [*CourseOffering] from [%T]", v)
+	}
+
+	e.End = node
+	return nil
+}
+
+// GetStartNode returns the CourseOffering the Meeting starts at
+func (e *Meeting) GetStartNode() interface{} {
+	return e.Start
+}
+
+// GetStartNodeType returns the type of the start node, *CourseOffering
+func (e *Meeting) GetStartNodeType() reflect.Type {
+	return reflect.TypeOf(&CourseOffering{})
+}
+
+// SetStartNode sets the start node, v has to be a *CourseOffering
+func (e *Meeting) SetStartNode(v interface{}) error {
+	node, ok := v.(*CourseOffering)
+	if !ok {
+		return fmt.Errorf("unable to convert to [*CourseOffering] from [%T]", v)
+	}
+
+	e.Start = node
+	return nil
+}
+
+// GetEndNode returns the Room the Meeting ends at
+func (e *Meeting) GetEndNode() interface{} {
+	return e.End
+}
+
+// GetEndNodeType returns the type of the end node, *Room
+func (e *Meeting) GetEndNodeType() reflect.Type {
+	return reflect.TypeOf(&Room{})
+}
+
+// SetEndNode sets the end node, v has to be a *Room
+func (e *Meeting) SetEndNode(v interface{}) error {
+	node, ok := v.(*Room)
+	if !ok {
+		return fmt.Errorf("unable to convert to [*Room] from [%T]", v)
+	}
+
+	e.End = node
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/mindstand/gogm"
+	"reflect"
+	"testing"
+)
+
+func TestEdgeMethods(t *testing.T) {
+	student, offering, room := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0 Fall 2026"}, &Room{Name: "Gates 101"}
+
+	for _, tt := range []struct {
+		edge       gogm.IEdge
+		start, end interface{}
+		// wrongStart and wrongEnd are nodes of the wrong type for each end
+		wrongStart, wrongEnd interface{}
+	}{
+		{&Enrollment{}, student, offering, offering, student},
+		{&Meeting{}, offering, room, room, offering},
+	} {
+		name := reflect.TypeOf(tt.edge).Elem().Name()
+
+		if got, want := tt.edge.GetStartNodeType(), reflect.TypeOf(tt.start); got != want {
+			t.Errorf("%s starts at %v, want %v", name, got, want)
+		}
+		if got, want := tt.edge.GetEndNodeType(), reflect.TypeOf(tt.end); got != want {
+			t.Errorf("%s ends at %v, want %v", name, got, want)
+		}
+
+		for _, err := range []error{tt.edge.SetStartNode(tt.start), tt.edge.SetEndNode(tt.end)} {
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+		if tt.edge.GetStartNode() != tt.start || tt.edge.GetEndNode() != tt.end {
+			t.Errorf("%s does not return the nodes it was given", name)
+		}
+
+		// a node of the wrong type is refused and leaves the edge as it was
+		for _, err := range []error{tt.edge.SetStartNode(tt.wrongStart), tt.edge.SetEndNode(tt.wrongEnd), tt.edge.SetStartNode(nil)} {
+			if err == nil {
+				t.Errorf("%s accepts a node of the wrong type", name)
+			}
+		}
+		if tt.edge.GetStartNode() != tt.start || tt.edge.GetEndNode() != tt.end {
+			t.Errorf("%s changed after refusing a node", name)
+		}
+	}
+
+	err := (&Enrollment{}).SetStartNode(room)
+	if want := "unable to convert to [*Student] from [*main.Room]"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"sort"
+	"time"
+)
+
+// statuses of an Enrollment, only enrolled and waitlisted enrollments can change their status
+const (
+	EnrollmentEnrolled   = "enrolled"
+	EnrollmentWaitlisted = "waitlisted"
+	EnrollmentDropped    = "dropped"
+	EnrollmentCompleted  = "completed"
+	EnrollmentWithdrawn  = "withdrawn"
+)
+
+var (
+	// ErrAlreadyEnrolled is returned when enrolling a student in a course it is currently enrolled in for the term
+	ErrAlreadyEnrolled = errors.New("student is already enrolled in the course")
+	// ErrInvalidTransition is returned when an enrollment can not move to the requested status
+	ErrInvalidTransition = errors.New("invalid enrollment status change")
+	// ErrCourseFull is returned when enrolling in an offering at capacity without joining its waitlist
+	ErrCourseFull = errors.New("course is full")
+	// ErrInvalidCapacity is returned for negative offering capacities
+	ErrInvalidCapacity = errors.New("capacity can not be negative")
+)
+
+// NewEnrollment creates an enrollment starting at enrolled
+func NewEnrollment(enrolled time.Time) *Enrollment {
+	return &Enrollment{
+		Status:       EnrollmentEnrolled,
+		EnrolledDate: enrolled,
+	}
+}
+
+// CurrentStatus returns the status of the enrollment, enrollments saved before there were statuses count as enrolled
+func (e *Enrollment) CurrentStatus() string {
+	if e.Status == "" {
+		return EnrollmentEnrolled
+	}
+
+	return e.Status
+}
+
+// Active is true while the student is enrolled
+func (e *Enrollment) Active() bool {
+	return e.CurrentStatus() == EnrollmentEnrolled
+}
+
+// Waitlisted is true while the student waits for a seat
+func (e *Enrollment) Waitlisted() bool {
+	return e.CurrentStatus() == EnrollmentWaitlisted
+}
+
+// Drop marks the enrollment dropped at date, a waitlisted student leaves the waitlist
+func (e *Enrollment) Drop(date time.Time) error {
+	return e.leave(EnrollmentDropped, date)
+}
+
+// Withdraw marks the enrollment withdrawn at date, unlike a drop a withdrawal stays on the record
+func (e *Enrollment) Withdraw(date time.Time) error {
+	return e.leave(EnrollmentWithdrawn, date)
+}
+
+func (e *Enrollment) leave(status string, date time.Time) error {
+	if !e.Active() && !e.Waitlisted() {
+		return fmt.Errorf("%w, can not change %s to %s", ErrInvalidTransition, e.CurrentStatus(), status)
+	}
+
+	e.Status = status
+	e.DroppedDate = date
+	return nil
+}
+
+// complete marks the enrollment completed, it can be completed again to change its grade
+func (e *Enrollment) complete() error {
+	status := e.CurrentStatus()
+	if status != EnrollmentEnrolled && status != EnrollmentCompleted {
+		return fmt.Errorf("%w, can not change %s to %s", ErrInvalidTransition, status, EnrollmentCompleted)
+	}
+
+	e.Status = EnrollmentCompleted
+	return nil
+}
+
+// reopen moves a completed enrollment back to enrolled
+func (e *Enrollment) reopen() error {
+	status := e.CurrentStatus()
+	if status != EnrollmentEnrolled && status != EnrollmentCompleted {
+		return fmt.Errorf("%w, can not change %s to %s", ErrInvalidTransition, status, EnrollmentEnrolled)
+	}
+
+	e.Status = EnrollmentEnrolled
+	return nil
+}
+
+// Registrar enrolls students in course offerings and drops them again, the enrollments are kept with their status.
+// Enroll and Drop have to be called in a transaction, they lock an offering with a capacity before
+// counting its seats so concurrent enrollments can not oversubscribe it
+type Registrar struct {
+	sess      gogm.ISession
+	students  *StudentRepo
+	teachers  *TeacherRepo
+	courses   *CourseRepo
+	offerings *OfferingRepo
+
+	// Waitlist puts students on the waitlist of a full course instead of failing with ErrCourseFull
+	Waitlist bool
+}
+
+// NewRegistrar creates a Registrar saving through sess
+func NewRegistrar(sess gogm.ISession) *Registrar {
+	return &Registrar{
+		sess:      sess,
+		students:  NewStudentRepo(sess),
+		teachers:  NewTeacherRepo(sess),
+		courses:   NewCourseRepo(sess),
+		offerings: NewOfferingRepo(sess),
+	}
+}
+
+// Enroll enrolls the student in offering, both have to be loaded with their relationships.
+// the student has to have passed every prerequisite of the course, can not take another section of it
+// in the same term and can not take another offering at the same time.
+// a student that dropped, withdrew from or completed the offering before gets a new enrollment.
+// when the offering is full the enrollment is waitlisted if Waitlist is set
+func (r *Registrar) Enroll(student *Student, offering *CourseOffering, enrollment *Enrollment) error {
+	if enrollment == nil {
+		return ErrNilEdge
+	}
+
+	if current := latestEnrollment(student, offering); current != nil {
+		if current.Active() {
+			return fmt.Errorf("%w, %s is enrolled in %s", ErrAlreadyEnrolled, student.Name, offering.Name)
+		} else if current.Waitlisted() {
+			return fmt.Errorf("%w, %s is on the waitlist of %s", ErrAlreadyEnrolled, student.Name, offering.Name)
+		}
+	}
+
+	// the offerings of the student are loaded with their course, term and meetings for the checks below
+	err := r.loadStudentSchedule(student)
+	if err != nil {
+		return err
+	}
+
+	// the prerequisites are checked by the CheckLink of the student when it is linked below
+	if offering.Course != nil {
+		course, err := r.courses.Get(offering.Course.UUID)
+		if err != nil {
+			return err
+		}
+
+		offering.Course.Prerequisites = course.Prerequisites
+	}
+
+	if other := otherSection(student, offering); other != nil {
+		return fmt.Errorf("%w, %s takes %s", ErrAlreadyEnrolled, student.Name, other.Name)
+	}
+
+	enrollment.Status = EnrollmentEnrolled
+
+	if offering.Capacity > 0 {
+		locked, err := r.lockOffering(offering)
+		if err != nil {
+			return err
+		}
+
+		if enrolledCount(locked) >= locked.Capacity {
+			if !r.Waitlist {
+				return fmt.Errorf("%w, %s has %v seats", ErrCourseFull, offering.Name, locked.Capacity)
+			}
+
+			enrollment.Status = EnrollmentWaitlisted
+			enrollment.WaitlistedDate = enrollment.EnrolledDate
+			enrollment.EnrolledDate = time.Time{}
+		}
+	}
+
+	err = student.LinkToCourseOfferingOnFieldEnrollmentsWith(&LinkOptions{Duplicates: AllowDuplicates}, offering, enrollment)
+	if err != nil {
+		return err
+	}
+
+	return r.students.Save(student)
+}
+
+// Drop drops the student from offering at date, withdraw marks it withdrawn instead.
+// the seat that opens up goes to the student that has been on the waitlist the longest
+func (r *Registrar) Drop(student *Student, offering *CourseOffering, date time.Time, withdraw bool) (*Enrollment, error) {
+	enrollment := latestEnrollment(student, offering)
+	if enrollment == nil {
+		return nil, fmt.Errorf("%w, no enrollment of %s in %s", ErrNotEnrolled, student.Name, offering.Name)
+	}
+
+	freesSeat := enrollment.Active()
+
+	var err error
+	if withdraw {
+		err = enrollment.Withdraw(date)
+	} else {
+		err = enrollment.Drop(date)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.students.Save(student)
+	if err != nil {
+		return nil, err
+	}
+
+	if freesSeat && offering.Capacity > 0 {
+		_, err = r.promote(offering, date)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return enrollment, nil
+}
+
+// SetCapacity changes the capacity of offering, waitlisted students are enrolled if seats open up.
+// returns the promoted enrollments
+func (r *Registrar) SetCapacity(offering *CourseOffering, capacity int, date time.Time) ([]*Enrollment, error) {
+	if capacity < 0 {
+		return nil, fmt.Errorf("%w, got %v", ErrInvalidCapacity, capacity)
+	}
+
+	offering.Capacity = capacity
+	err := r.offerings.Save(offering)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.promote(offering, date)
+}
+
+// promote enrolls waitlisted students in the order they joined the waitlist until offering is full again.
+// returns the promoted enrollments
+func (r *Registrar) promote(offering *CourseOffering, date time.Time) ([]*Enrollment, error) {
+	locked, err := r.lockOffering(offering)
+	if err != nil {
+		return nil, err
+	}
+
+	waitlist := waitlistOf(locked)
+	if locked.Capacity > 0 {
+		free := locked.Capacity - enrolledCount(locked)
+		if free < 0 {
+			free = 0
+		}
+
+		if free < len(waitlist) {
+			waitlist = waitlist[:free]
+		}
+	}
+
+	if len(waitlist) == 0 {
+		return nil, nil
+	}
+
+	for _, enrollment := range waitlist {
+		enrollment.Status = EnrollmentEnrolled
+		enrollment.EnrolledDate = date
+	}
+
+	// the offering is loaded with its enrollments, saving it writes the new statuses
+	err = r.offerings.Save(locked)
+	if err != nil {
+		return nil, err
+	}
+
+	return waitlist, nil
+}
+
+// lockOffering writes offering before reloading it with its enrollments. in neo4j the write takes a lock
+// on the offering node that is held until the transaction ends, so the enrollments read afterwards can
+// not change until then
+func (r *Registrar) lockOffering(offering *CourseOffering) (*CourseOffering, error) {
+	var fresh CourseOffering
+	err := r.sess.LoadDepth(&fresh, offering.UUID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.sess.SaveDepth(&fresh, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.offerings.Get(offering.UUID)
+}
+
+// enrolledCount counts the students taking up a seat in offering
+func enrolledCount(offering *CourseOffering) int {
+	count := 0
+	for _, enrollment := range offering.Enrollments {
+		if enrollment.Active() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// waitlistOf returns the waitlisted enrollments of offering in the order they joined the waitlist
+func waitlistOf(offering *CourseOffering) []*Enrollment {
+	var waitlist []*Enrollment
+	for _, enrollment := range offering.Enrollments {
+		if enrollment.Waitlisted() {
+			waitlist = append(waitlist, enrollment)
+		}
+	}
+
+	sort.SliceStable(waitlist, func(i, j int) bool {
+		if !waitlist[i].WaitlistedDate.Equal(waitlist[j].WaitlistedDate) {
+			return waitlist[i].WaitlistedDate.Before(waitlist[j].WaitlistedDate)
+		}
+		return waitlist[i].UUID < waitlist[j].UUID
+	})
+
+	return waitlist
+}
+
+// latestEnrollment is the most recent enrollment of student in offering, nil if there is none
+func latestEnrollment(student *Student, offering *CourseOffering) *Enrollment {
+	if student == nil || offering == nil {
+		return nil
+	}
+
+	var latest *Enrollment
+	for _, enrollment := range student.Enrollments {
+		if enrollment == nil || !sameCourseOffering(enrollment.End, offering) {
+			continue
+		}
+
+		if latest == nil || enrollment.requestedDate().After(latest.requestedDate()) {
+			latest = enrollment
+		}
+	}
+
+	return latest
+}
+
+// requestedDate is when the student asked to be enrolled, which is when it joined the waitlist for waitlisted enrollments
+func (e *Enrollment) requestedDate() time.Time {
+	if !e.WaitlistedDate.IsZero() {
+		return e.WaitlistedDate
+	}
+
+	return e.EnrolledDate
+}
+
+// otherSection is another section of the course of offering in the same term that student is enrolled or
+// waitlisted in, nil if there is none. the offerings of the student have to be loaded with their course and term
+func otherSection(student *Student, offering *CourseOffering) *CourseOffering {
+	if offering.Course == nil || offering.Term == nil {
+		return nil
+	}
+
+	for _, enrollment := range student.Enrollments {
+		taken := enrollment.End
+		if taken == nil || sameCourseOffering(taken, offering) || !takesSeat(enrollment) {
+			continue
+		}
+
+		if sameCourse(taken.Course, offering.Course) && sameTerm(taken.Term, offering.Term) {
+			return taken
+		}
+	}
+
+	return nil
+}
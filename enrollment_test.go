@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrollmentTransitions(t *testing.T) {
+	actions := map[string]func(e *Enrollment) error{
+		"drop":     func(e *Enrollment) error { return e.Drop(testDate) },
+		"withdraw": func(e *Enrollment) error { return e.Withdraw(testDate) },
+		"complete": (*Enrollment).complete,
+		"reopen":   (*Enrollment).reopen,
+	}
+
+	for _, tt := range []struct {
+		from   string
+		action string
+		want   string
+	}{
+		// enrollments saved before there were statuses count as enrolled
+		{"", "drop", EnrollmentDropped},
+		{EnrollmentEnrolled, "drop", EnrollmentDropped},
+		{EnrollmentEnrolled, "withdraw", EnrollmentWithdrawn},
+		{EnrollmentEnrolled, "complete", EnrollmentCompleted},
+		{EnrollmentEnrolled, "reopen", EnrollmentEnrolled},
+		{EnrollmentWaitlisted, "drop", EnrollmentDropped},
+		{EnrollmentWaitlisted, "withdraw", EnrollmentWithdrawn},
+		{EnrollmentWaitlisted, "complete", ""},
+		{EnrollmentWaitlisted, "reopen", ""},
+		{EnrollmentCompleted, "complete", EnrollmentCompleted},
+		{EnrollmentCompleted, "reopen", EnrollmentEnrolled},
+		{EnrollmentCompleted, "drop", ""},
+		{EnrollmentDropped, "drop", ""},
+		{EnrollmentDropped, "withdraw", ""},
+		{EnrollmentDropped, "complete", ""},
+		{EnrollmentWithdrawn, "reopen", ""},
+	} {
+		e := &Enrollment{Status: tt.from}
+		err := actions[tt.action](e)
+
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidTransition) || e.Status != tt.from {
+				t.Errorf("%s a %q enrollment got %v and status %q, want ErrInvalidTransition", tt.action, tt.from, err, e.Status)
+			}
+			continue
+		}
+
+		if err != nil || e.Status != tt.want {
+			t.Errorf("%s a %q enrollment got %v and status %q, want %q", tt.action, tt.from, err, e.Status, tt.want)
+		}
+		if (tt.action == "drop" || tt.action == "withdraw") && !e.DroppedDate.Equal(testDate) {
+			t.Errorf("%s a %q enrollment did not set the drop date", tt.action, tt.from)
+		}
+	}
+}
+
+func TestRegistrarDropAndEnrollAgain(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+
+	phys, err := repos.Offerings.Get(school.Offerings["phys122-0 "+exampleTerm].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs341_1, err := repos.Offerings.Get(school.Offerings["cs341-1 "+exampleTerm].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eric, err := repos.Students.Get(school.Students["eric"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the seeded enrollments start now, so enrolling again has to be later to be the latest one
+	later := time.Now().UTC().Add(time.Hour)
+
+	// the steps run in order on eric and phys122
+	for _, tt := range []struct {
+		name string
+		step func() error
+		want error
+		// status is the status of the latest enrollment after the step
+		status string
+	}{
+		{"drop", func() error { _, err := registrar.Drop(eric, phys, testDate, false); return err }, nil, EnrollmentDropped},
+		{"drop again", func() error { _, err := registrar.Drop(eric, phys, testDate, false); return err }, ErrInvalidTransition, EnrollmentDropped},
+		{"enroll again", func() error { return registrar.Enroll(eric, phys, NewEnrollment(later)) }, nil, EnrollmentEnrolled},
+		{"enroll twice", func() error { return registrar.Enroll(eric, phys, NewEnrollment(later.Add(time.Hour))) }, ErrAlreadyEnrolled, EnrollmentEnrolled},
+		{"withdraw", func() error { _, err := registrar.Drop(eric, phys, later, true); return err }, nil, EnrollmentWithdrawn},
+		{"nil enrollment", func() error { return registrar.Enroll(eric, phys, nil) }, ErrNilEdge, EnrollmentWithdrawn},
+	} {
+		err := tt.step()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+
+		if got := latestEnrollment(eric, phys).CurrentStatus(); got != tt.status {
+			t.Errorf("%s: the latest enrollment is %s, want %s", tt.name, got, tt.status)
+		}
+	}
+
+	// both enrollments are kept
+	eric, err = repos.Students.Get(school.Students["eric"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses []string
+	for _, enrollment := range eric.Enrollments {
+		if sameCourseOffering(enrollment.End, phys) {
+			statuses = append(statuses, enrollment.CurrentStatus())
+		}
+	}
+	if len(statuses) != 2 {
+		t.Errorf("got enrollments %q in phys122, want the dropped and the withdrawn one", statuses)
+	}
+
+	// another section of a course the student takes
+	err = registrar.Enroll(eric, cs341_1, NewEnrollment(later))
+	if !errors.Is(err, ErrAlreadyEnrolled) {
+		t.Errorf("enrolling in a second section got %v, want ErrAlreadyEnrolled", err)
+	}
+
+	// students never enrolled can not drop
+	michael, err := repos.Students.Get(school.Students["michael"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = registrar.Drop(michael, cs341_1, testDate, false)
+	if !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("dropping a course that was never taken got %v, want ErrNotEnrolled", err)
+	}
+}
+
+func TestCapacityAndWaitlist(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+
+	for _, name := range []string{"anna", "beth"} {
+		err := repos.Students.Save(&Student{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// students and the offering are loaded again before every step, promotions are saved through the offering
+	student := func(name string) *Student {
+		found, err := repos.Students.FindByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	offering := func() *CourseOffering {
+		found, err := repos.Offerings.Get(school.Offerings["cs341-1 "+exampleTerm].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	enroll := func(name string, waitlist bool, date time.Time) func() error {
+		return func() error {
+			registrar.Waitlist = waitlist
+			return registrar.Enroll(student(name), offering(), NewEnrollment(date))
+		}
+	}
+
+	// cs341-1 starts with nikita, the steps run in order
+	for _, tt := range []struct {
+		name string
+		step func() error
+		want error
+		// enrolled and waitlisted are the students in cs341-1 after the step, the waitlist in order
+		enrolled, waitlisted string
+	}{
+		{"limit to one seat", func() error { _, err := registrar.SetCapacity(offering(), 1, testDate); return err }, nil, "nikita", ""},
+		{"negative capacity", func() error { _, err := registrar.SetCapacity(offering(), -1, testDate); return err }, ErrInvalidCapacity, "nikita", ""},
+		{"full", enroll("michael", false, testDate), ErrCourseFull, "nikita", ""},
+		{"waitlist", enroll("michael", true, testDate.Add(2*time.Hour)), nil, "nikita", "michael"},
+		{"waitlist in order of joining", enroll("anna", true, testDate.Add(time.Hour)), nil, "nikita", "anna michael"},
+		{"already on the waitlist", enroll("anna", true, testDate.Add(3*time.Hour)), ErrAlreadyEnrolled, "nikita", "anna michael"},
+		{"join last", enroll("beth", true, testDate.Add(3*time.Hour)), nil, "nikita", "anna michael beth"},
+		{"leaving the waitlist frees no seat", func() error { _, err := registrar.Drop(student("michael"), offering(), testDate, false); return err }, nil, "nikita", "anna beth"},
+		{"a drop promotes the first", func() error { _, err := registrar.Drop(student("nikita"), offering(), testDate, false); return err }, nil, "anna", "beth"},
+		{"more seats promote the rest", func() error { _, err := registrar.SetCapacity(offering(), 3, testDate); return err }, nil, "anna beth", ""},
+		{"free seats", enroll("michael", false, testDate.Add(4*time.Hour)), nil, "anna beth michael", ""},
+	} {
+		err := tt.step()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+
+		current := offering()
+		var enrolled []string
+		for _, enrollment := range current.Enrollments {
+			if enrollment.Active() {
+				enrolled = append(enrolled, enrollment.Start.Name)
+			}
+		}
+		var waitlisted []string
+		for _, enrollment := range waitlistOf(current) {
+			waitlisted = append(waitlisted, enrollment.Start.Name)
+		}
+
+		sort.Strings(enrolled)
+		if got := strings.Join(enrolled, " "); got != tt.enrolled {
+			t.Errorf("%s: got enrolled %q, want %q", tt.name, got, tt.enrolled)
+		}
+		if got := strings.Join(waitlisted, " "); got != tt.waitlisted {
+			t.Errorf("%s: got waitlist %q, want %q", tt.name, got, tt.waitlisted)
+		}
+	}
+
+	// promoted students are enrolled from the day their seat opened up
+	anna := latestEnrollment(student("anna"), offering())
+	if !anna.EnrolledDate.Equal(testDate) || !anna.WaitlistedDate.Equal(testDate.Add(time.Hour)) {
+		t.Errorf("anna was waitlisted %v and enrolled %v", anna.WaitlistedDate, anna.EnrolledDate)
+	}
+}
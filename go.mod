@@ -2,4 +2,10 @@ module github.com/mindstand/gogm-example
 
 go 1.13
 
-require github.com/mindstand/gogm v0.0.0-20191218144119-286fec0548e1
+require (
+	github.com/google/uuid v1.1.1
+	github.com/graphql-go/graphql v0.8.1
+	github.com/mindstand/go-cypherdsl v0.0.0-20191030200322-ed2619be6449
+	github.com/mindstand/gogm v0.0.0-20191218144119-286fec0548e1
+	gopkg.in/yaml.v2 v2.2.8
+)
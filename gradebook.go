@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidGrade is returned when a grade is not one of the letter grades
+	ErrInvalidGrade = errors.New("invalid letter grade")
+	// ErrNotEnrolled is returned when grading a student in an offering the student is not enrolled in
+	ErrNotEnrolled = errors.New("student is not enrolled in the course")
+)
+
+// letterGrades are the accepted grades from best to worst
+var letterGrades = []string{"A+", "A", "A-", "B+", "B", "B-", "C+", "C", "C-", "D+", "D", "D-", "F"}
+
+// gradePoints is what each letter grade is worth on a 4.0 scale
+var gradePoints = map[string]float64{
+	"A+": 4.0, "A": 4.0, "A-": 3.7,
+	"B+": 3.3, "B": 3.0, "B-": 2.7,
+	"C+": 2.3, "C": 2.0, "C-": 1.7,
+	"D+": 1.3, "D": 1.0, "D-": 0.7,
+	"F": 0,
+}
+
+// ParseGrade normalizes a letter grade like " b+ " to "B+"
+func ParseGrade(grade string) (string, error) {
+	letter := strings.ToUpper(strings.TrimSpace(grade))
+	if _, ok := gradePoints[letter]; !ok {
+		return "", fmt.Errorf("%w %q, expected one of %s", ErrInvalidGrade, grade, strings.Join(letterGrades, " "))
+	}
+
+	return letter, nil
+}
+
+// GradePoints returns the points the final grade of the enrollment is worth, false if it is not graded yet
+func (e *Enrollment) GradePoints() (float64, bool) {
+	points, ok := gradePoints[e.FinalGrade]
+	return points, ok
+}
+
+// GradeReport lists the graded enrollments of a student or a course offering
+type GradeReport struct {
+	// Grades are the graded enrollments, ordered by offering name for students and by student name for offerings
+	Grades []*Enrollment
+	// GPA is the mean of the grade points of Grades rounded to two decimals, 0 if nothing is graded
+	GPA float64
+}
+
+// StudentReport is the cumulative report of a student loaded with its enrollments
+func StudentReport(student *Student) *GradeReport {
+	report := newGradeReport(student.Enrollments)
+	sort.SliceStable(report.Grades, func(i, j int) bool {
+		return report.Grades[i].End.Name < report.Grades[j].End.Name
+	})
+
+	return report
+}
+
+// OfferingReport is the report of an offering loaded with its enrollments, the GPA is over every graded student
+func OfferingReport(offering *CourseOffering) *GradeReport {
+	report := newGradeReport(offering.Enrollments)
+	sort.SliceStable(report.Grades, func(i, j int) bool {
+		return report.Grades[i].Start.Name < report.Grades[j].Start.Name
+	})
+
+	return report
+}
+
+func newGradeReport(enrollments []*Enrollment) *GradeReport {
+	report := &GradeReport{Grades: []*Enrollment{}}
+
+	total := 0.0
+	for _, enrollment := range enrollments {
+		points, ok := enrollment.GradePoints()
+		if !ok {
+			continue
+		}
+
+		total += points
+		report.Grades = append(report.Grades, enrollment)
+	}
+
+	if len(report.Grades) != 0 {
+		report.GPA = math.Round(total/float64(len(report.Grades))*100) / 100
+	}
+
+	return report
+}
+
+// GradeBook records grades on the enrollments of students
+type GradeBook struct {
+	students *StudentRepo
+}
+
+// NewGradeBook creates a GradeBook saving through sess
+func NewGradeBook(sess gogm.ISession) *GradeBook {
+	return &GradeBook{students: NewStudentRepo(sess)}
+}
+
+// Record sets the final grade of the student in offering and completes the enrollment,
+// the student has to be loaded with its enrollments
+func (g *GradeBook) Record(student *Student, offering *CourseOffering, grade string) (*Enrollment, error) {
+	letter, err := ParseGrade(grade)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := enrollmentIn(student, offering)
+	if err != nil {
+		return nil, err
+	}
+
+	err = enrollment.complete()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment.FinalGrade = letter
+	enrollment.GradedDate = time.Now().UTC()
+
+	err = g.students.Save(student)
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// Clear removes the final grade of the student in offering, the enrollment is enrolled again
+func (g *GradeBook) Clear(student *Student, offering *CourseOffering) (*Enrollment, error) {
+	enrollment, err := enrollmentIn(student, offering)
+	if err != nil {
+		return nil, err
+	}
+
+	err = enrollment.reopen()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment.FinalGrade = ""
+	enrollment.GradedDate = time.Time{}
+
+	err = g.students.Save(student)
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// enrollmentIn finds the latest enrollment of student in offering
+func enrollmentIn(student *Student, offering *CourseOffering) (*Enrollment, error) {
+	if student == nil || offering == nil {
+		return nil, ErrNilTarget
+	}
+
+	enrollment := latestEnrollment(student, offering)
+	if enrollment == nil {
+		return nil, fmt.Errorf("%w, no enrollment of %s in %s", ErrNotEnrolled, student.Name, offering.Name)
+	}
+
+	return enrollment, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseGrade(t *testing.T) {
+	for _, tt := range []struct {
+		grade   string
+		want    string
+		wantErr bool
+	}{
+		{grade: "A", want: "A"},
+		{grade: " b+ ", want: "B+"},
+		{grade: "d-", want: "D-"},
+		{grade: "f", want: "F"},
+		{grade: "E", wantErr: true},
+		{grade: "F-", wantErr: true},
+		{grade: "", wantErr: true},
+	} {
+		got, err := ParseGrade(tt.grade)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidGrade) {
+				t.Errorf("ParseGrade(%q) got %v, want ErrInvalidGrade", tt.grade, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("ParseGrade(%q) = %q, %v, want %q", tt.grade, got, err, tt.want)
+		}
+	}
+}
+
+func TestGradeReports(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		grades []string
+		want   float64
+	}{
+		{"nothing graded", []string{"", "", ""}, 0},
+		{"ungraded enrollments do not count", []string{"A", "", "B"}, 3.5},
+		{"rounded to two decimals", []string{"A", "B+", "C-"}, 3},
+		{"failing grades count", []string{"A-", "F", "D+"}, 1.67},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			student := &Student{Name: "eric"}
+			for i, grade := range tt.grades {
+				offering := &CourseOffering{Name: []string{"phys122-0", "cs341-0", "hist347-0"}[i]}
+				student.Enrollments = append(student.Enrollments, &Enrollment{Start: student, End: offering, FinalGrade: grade})
+			}
+
+			report := StudentReport(student)
+			if report.GPA != tt.want {
+				t.Errorf("got gpa %v, want %v", report.GPA, tt.want)
+			}
+
+			for i, enrollment := range report.Grades {
+				if i > 0 && enrollment.End.Name < report.Grades[i-1].End.Name {
+					t.Errorf("the grades are not ordered by offering: %v before %v", report.Grades[i-1].End.Name, enrollment.End.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestGradeBook(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	book := NewGradeBook(sess)
+
+	eric, err := repos.Students.Get(school.Students["eric"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offering := func(name string) *CourseOffering {
+		return school.Offerings[name+" "+exampleTerm]
+	}
+
+	for _, tt := range []struct {
+		offering string
+		grade    string
+		want     error
+	}{
+		{"cs341-0", "a", nil},
+		{"hist347-0", "b+", nil},
+		{"phys122-0", "c", nil},
+		{"phys122-0", "b", nil},
+		{"cs341-1", "A", ErrNotEnrolled},
+		{"hist347-0", "Z", ErrInvalidGrade},
+	} {
+		_, err := book.Record(eric, offering(tt.offering), tt.grade)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Record(%s, %q) got %v, want %v", tt.offering, tt.grade, err, tt.want)
+		}
+	}
+
+	// the grades are saved
+	eric, err = repos.Students.Get(school.Students["eric"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := StudentReport(eric)
+	var got []string
+	for _, enrollment := range report.Grades {
+		if enrollment.CurrentStatus() != EnrollmentCompleted || enrollment.GradedDate.IsZero() {
+			t.Errorf("%s is %s, graded %v", enrollment.End.Name, enrollment.CurrentStatus(), enrollment.GradedDate)
+		}
+		got = append(got, enrollment.End.Name+" "+enrollment.FinalGrade)
+	}
+
+	want := []string{"cs341-0 Fall 2026 A", "hist347-0 Fall 2026 B+", "phys122-0 Fall 2026 B"}
+	if !reflect.DeepEqual(got, want) || report.GPA != 3.43 {
+		t.Errorf("got %q with gpa %v, want %q with gpa 3.43", got, report.GPA, want)
+	}
+
+	enrollment, err := book.Clear(eric, offering("phys122-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enrollment.Active() || enrollment.FinalGrade != "" || !enrollment.GradedDate.IsZero() {
+		t.Errorf("the cleared enrollment is %s with grade %q", enrollment.CurrentStatus(), enrollment.FinalGrade)
+	}
+	if report := StudentReport(eric); len(report.Grades) != 2 || report.GPA != 3.65 {
+		t.Errorf("got %v grades with gpa %v after clearing one, want 2 with gpa 3.65", len(report.Grades), report.GPA)
+	}
+
+	// dropped enrollments can not be graded
+	_, err = NewRegistrar(sess).Drop(eric, offering("phys122-0"), testDate, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = book.Record(eric, offering("phys122-0"), "A")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("grading a dropped enrollment got %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestOfferingReport(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	book := NewGradeBook(sess)
+	hist := school.Offerings["hist347-0 "+exampleTerm]
+
+	for name, grade := range map[string]string{"steven": "C", "nikita": "A", "eric": "B-"} {
+		student, err := repos.Students.Get(school.Students[name].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = book.Record(student, hist, grade)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hist, err := repos.Offerings.Get(hist.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := OfferingReport(hist)
+	var got []string
+	for _, enrollment := range report.Grades {
+		got = append(got, enrollment.Start.Name)
+	}
+
+	if want := []string{"eric", "nikita", "steven"}; !reflect.DeepEqual(got, want) || report.GPA != 2.9 {
+		t.Errorf("got %q with gpa %v, want %q with gpa 2.9", got, report.GPA, want)
+	}
+}
@@ -0,0 +1,1056 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/mindstand/gogm"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// graphqlJSON is the scalar used for properties stored as maps, it is passed through as plain json
+var graphqlJSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "arbitrary json, used for map properties",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return valueAST.GetValue()
+	},
+})
+
+// graphqlLoaderKey is the context key of the *graphqlLoader serving a request
+type graphqlLoaderKey struct{}
+
+// graphqlEdge is one entry of a connection, the special edge along with the node on the other side
+type graphqlEdge struct {
+	Edge reflect.Value
+	Node reflect.Value
+}
+
+// graphqlLoader loads nodes for the resolvers of one request.
+// nodes on the edge of a load do not have their relationships, so they are loaded again
+// the first time one of their relationship fields is resolved
+type graphqlLoader struct {
+	sess gogm.ISession
+
+	mu sync.Mutex
+	// full holds the nodes that were loaded with their relationships by uuid
+	full map[string]reflect.Value
+}
+
+func newGraphQLLoader(sess gogm.ISession) *graphqlLoader {
+	return &graphqlLoader{
+		sess: sess,
+		full: map[string]reflect.Value{},
+	}
+}
+
+func loaderFrom(ctx context.Context) (*graphqlLoader, error) {
+	loader, ok := ctx.Value(graphqlLoaderKey{}).(*graphqlLoader)
+	if !ok {
+		return nil, errors.New("graphql context is missing its loader")
+	}
+
+	return loader, nil
+}
+
+// remember marks nodes as loaded with their relationships
+func (l *graphqlLoader) remember(nodes ...reflect.Value) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, node := range nodes {
+		l.full[baseNodeOf(node).UUID] = node
+	}
+}
+
+// forget drops every remembered node, used after a mutation changed the graph
+func (l *graphqlLoader) forget() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.full = map[string]reflect.Value{}
+}
+
+// relationships returns node loaded with its relationships
+func (l *graphqlLoader) relationships(node reflect.Value) (reflect.Value, error) {
+	uuid := baseNodeOf(node).UUID
+
+	l.mu.Lock()
+	full, ok := l.full[uuid]
+	l.mu.Unlock()
+	if ok {
+		return full, nil
+	}
+
+	full, err := l.load(node.Type().Elem(), uuid)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return full, nil
+}
+
+// load loads the node of type t with uuid, t is the struct type
+func (l *graphqlLoader) load(t reflect.Type, uuid string) (reflect.Value, error) {
+	node := reflect.New(t)
+	err := l.sess.LoadDepth(node.Interface(), uuid, 1)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	l.remember(node)
+	return node, nil
+}
+
+// loadAll loads every node of type t, only the ones called name if name is not empty
+func (l *graphqlLoader) loadAll(t reflect.Type, name string) ([]reflect.Value, error) {
+	nodes := reflect.New(reflect.SliceOf(reflect.PtrTo(t)))
+
+	var err error
+	if name == "" {
+		err = l.sess.LoadAllDepth(nodes.Interface(), 1)
+	} else {
+		filter, params := nameFilter(name)
+		err = l.sess.LoadAllDepthFilter(nodes.Interface(), 1, filter, params)
+	}
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	var out []reflect.Value
+	for i := 0; i < nodes.Elem().Len(); i++ {
+		out = append(out, nodes.Elem().Index(i))
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return nodeName(out[i].Interface()) < nodeName(out[j].Interface())
+	})
+
+	l.remember(out...)
+	return out, nil
+}
+
+// graphqlBuilder derives graphql types from the gogm struct tags
+type graphqlBuilder struct {
+	objects     map[reflect.Type]*graphql.Object
+	connections map[string]*graphql.Object
+
+	// err is set by the field thunks, which have no way to return an error
+	err error
+}
+
+func (b *graphqlBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// NewGraphQLSchema builds a graphql schema for the node and edge types, passed as pointers like to gogm.Init.
+// every node gets a query by uuid and a query listing all of them, relationship fields follow
+// the direction in their tags and special edges are exposed as connections
+func NewGraphQLSchema(types ...interface{}) (graphql.Schema, error) {
+	b := &graphqlBuilder{
+		objects:     map[reflect.Type]*graphql.Object{},
+		connections: map[string]*graphql.Object{},
+	}
+
+	var nodes []*nodeSchema
+	for _, typ := range types {
+		schema, err := schemaFor(reflect.TypeOf(typ))
+		if err != nil {
+			return graphql.Schema{}, err
+		}
+
+		// objects are created up front so the field thunks can refer to all of them
+		b.objects[schema.Type] = graphql.NewObject(graphql.ObjectConfig{
+			Name:   schema.Label,
+			Fields: b.fieldsThunk(schema),
+		})
+
+		if !schema.IsEdge {
+			nodes = append(nodes, schema)
+		}
+	}
+
+	query := graphql.Fields{}
+	for _, schema := range nodes {
+		query[lowerFirst(schema.Label)] = b.getField(schema)
+		query[lowerFirst(schema.Label)+"s"] = b.listField(schema)
+	}
+
+	offerings, err := b.offeringsField()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	query["offerings"] = offerings
+
+	mutation, err := b.enrollmentMutations()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+
+	prerequisites, err := b.prerequisiteMutations()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	for name, field := range prerequisites {
+		mutation[name] = field
+	}
+
+	schedule, err := b.scheduleMutations()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	for name, field := range schedule {
+		mutation[name] = field
+	}
+
+	offer, err := b.offerMutation()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	mutation["offer"] = offer
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: query}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: mutation}),
+	})
+	if b.err != nil {
+		return graphql.Schema{}, b.err
+	}
+
+	return schema, err
+}
+
+func (b *graphqlBuilder) object(t reflect.Type) (*graphql.Object, error) {
+	obj, ok := b.objects[t]
+	if !ok {
+		return nil, fmt.Errorf("type [%s] is used in a relationship but was not registered", t)
+	}
+
+	return obj, nil
+}
+
+func (b *graphqlBuilder) fieldsThunk(schema *nodeSchema) graphql.FieldsThunk {
+	return func() graphql.Fields {
+		fields := graphql.Fields{}
+
+		for _, prop := range schema.Properties {
+			if prop.Name == "id" {
+				continue
+			}
+			fields[prop.Name] = propertyGraphQLField(schema, prop)
+		}
+
+		if schema.IsEdge {
+			// an edge points to the nodes it connects
+			for _, end := range []struct {
+				typ   reflect.Type
+				index []int
+			}{{schema.Start, schema.StartIndex}, {schema.End, schema.EndIndex}} {
+				field, err := b.endpointField(end.typ, end.index)
+				if err != nil {
+					b.fail(err)
+					continue
+				}
+				fields[lowerFirst(end.typ.Name())] = field
+			}
+			return fields
+		}
+
+		for _, rel := range schema.Relationships {
+			field, err := b.relationshipField(schema, rel)
+			if err != nil {
+				b.fail(err)
+				continue
+			}
+			fields[toSnakeCase(rel.FieldName)] = field
+		}
+
+		return fields
+	}
+}
+
+// propertyGraphQLField maps a property to a scalar field
+func propertyGraphQLField(schema *nodeSchema, prop *propertyField) *graphql.Field {
+	fieldType := schema.Type.FieldByIndex(prop.Index).Type
+
+	var typ graphql.Output
+	switch {
+	case prop.Name == "uuid":
+		typ = graphql.NewNonNull(graphql.ID)
+	case prop.Time:
+		typ = graphql.DateTime
+	case prop.Properties:
+		typ = graphqlJSON
+	default:
+		switch fieldType.Kind() {
+		case reflect.String:
+			typ = graphql.String
+		case reflect.Bool:
+			typ = graphql.Boolean
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			typ = graphql.Int
+		case reflect.Float32, reflect.Float64:
+			typ = graphql.Float
+		default:
+			typ = graphqlJSON
+		}
+	}
+
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			val := reflect.ValueOf(p.Source)
+			if edge, ok := p.Source.(*graphqlEdge); ok {
+				val = edge.Edge
+			}
+
+			value := val.Elem().FieldByIndex(prop.Index)
+			if prop.Time && value.Interface().(time.Time).IsZero() {
+				return nil, nil
+			}
+
+			return value.Interface(), nil
+		},
+	}
+}
+
+// endpointField resolves the start or end node of an edge
+func (b *graphqlBuilder) endpointField(t reflect.Type, index []int) (*graphql.Field, error) {
+	obj, err := b.object(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphql.Field{
+		Type: obj,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			node := reflect.ValueOf(p.Source).Elem().FieldByIndex(index)
+			if node.IsNil() {
+				return nil, nil
+			}
+
+			return node.Interface(), nil
+		},
+	}, nil
+}
+
+// relationshipField resolves a relationship field, reloading the node if it was loaded without its relationships
+func (b *graphqlBuilder) relationshipField(schema *nodeSchema, rel *relationshipField) (*graphql.Field, error) {
+	target, err := b.object(rel.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	arrow := fmt.Sprintf("(:%s)-[:%s]->(:%s)", schema.Label, rel.Relationship, rel.Target.Name())
+	if rel.Direction == directionIncoming {
+		arrow = fmt.Sprintf("(:%s)<-[:%s]-(:%s)", schema.Label, rel.Relationship, rel.Target.Name())
+	}
+
+	resolveTargets := func(p graphql.ResolveParams) ([]reflect.Value, error) {
+		loader, err := loaderFrom(p.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := loader.relationships(reflect.ValueOf(p.Source))
+		if err != nil {
+			return nil, err
+		}
+
+		return relationshipTargets(node.Elem(), rel), nil
+	}
+
+	if rel.Edge != nil {
+		conn, err := b.connection(schema, rel, target)
+		if err != nil {
+			return nil, err
+		}
+
+		return &graphql.Field{
+			Type:        graphql.NewNonNull(conn),
+			Description: arrow,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				edges, err := resolveTargets(p)
+				if err != nil {
+					return nil, err
+				}
+
+				out := []*graphqlEdge{}
+				for _, edge := range edges {
+					iedge := edge.Interface().(gogm.IEdge)
+					var other interface{}
+					if rel.Direction == directionIncoming {
+						other = iedge.GetStartNode()
+					} else {
+						other = iedge.GetEndNode()
+					}
+					out = append(out, &graphqlEdge{Edge: edge, Node: reflect.ValueOf(other)})
+				}
+
+				return out, nil
+			},
+		}, nil
+	}
+
+	if !rel.Many {
+		return &graphql.Field{
+			Type:        target,
+			Description: arrow,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				targets, err := resolveTargets(p)
+				if err != nil || len(targets) == 0 {
+					return nil, err
+				}
+
+				return targets[0].Interface(), nil
+			},
+		}, nil
+	}
+
+	return &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(target))),
+		Description: arrow,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			targets, err := resolveTargets(p)
+			if err != nil {
+				return nil, err
+			}
+
+			out := []interface{}{}
+			for _, t := range targets {
+				out = append(out, t.Interface())
+			}
+
+			return out, nil
+		},
+	}, nil
+}
+
+// connection builds the connection type of a relationship stored on a special edge,
+// its edges hold the edge properties and the node on the other side
+func (b *graphqlBuilder) connection(schema *nodeSchema, rel *relationshipField, target *graphql.Object) (*graphql.Object, error) {
+	name := schema.Label + rel.FieldName
+	if conn, ok := b.connections[name]; ok {
+		return conn, nil
+	}
+
+	edgeSchema, err := schemaFor(rel.Edge)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeFields := graphql.Fields{
+		"node": &graphql.Field{
+			Type: graphql.NewNonNull(target),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*graphqlEdge).Node.Interface(), nil
+			},
+		},
+	}
+	for _, prop := range edgeSchema.Properties {
+		if prop.Name == "id" {
+			continue
+		}
+		edgeFields[prop.Name] = propertyGraphQLField(edgeSchema, prop)
+	}
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   name + "Edge",
+		Fields: edgeFields,
+	})
+
+	conn := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"totalCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(p.Source.([]*graphqlEdge)), nil
+				},
+			},
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	b.connections[name] = conn
+	return conn, nil
+}
+
+// getField is the query loading one node by uuid
+func (b *graphqlBuilder) getField(schema *nodeSchema) *graphql.Field {
+	return &graphql.Field{
+		Type: b.objects[schema.Type],
+		Args: graphql.FieldConfigArgument{
+			"uuid": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			loader, err := loaderFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+
+			node, err := loader.load(schema.Type, p.Args["uuid"].(string))
+			if errors.Is(err, gogm.ErrNotFound) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+
+			return node.Interface(), nil
+		},
+	}
+}
+
+// listField is the query loading every node of a type, optionally only the ones with a name
+func (b *graphqlBuilder) listField(schema *nodeSchema) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(b.objects[schema.Type]))),
+		Args: graphql.FieldConfigArgument{
+			"name": &graphql.ArgumentConfig{Type: graphql.String},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			loader, err := loaderFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+
+			name, _ := p.Args["name"].(string)
+			nodes, err := loader.loadAll(schema.Type, name)
+			if err != nil {
+				return nil, err
+			}
+
+			out := []interface{}{}
+			for _, node := range nodes {
+				out = append(out, node.Interface())
+			}
+
+			return out, nil
+		},
+	}
+}
+
+// offeringsField is the query for the offerings of a term and a department, like OfferingRepo.Search
+func (b *graphqlBuilder) offeringsField() (*graphql.Field, error) {
+	offering, err := b.object(reflect.TypeOf(CourseOffering{}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(offering))),
+		Description: "offerings in a term of the courses of a department, ordered by name",
+		Args: graphql.FieldConfigArgument{
+			"term":       &graphql.ArgumentConfig{Type: graphql.String, Description: "name of the term"},
+			"department": &graphql.ArgumentConfig{Type: graphql.String, Description: "name of the department"},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			loader, err := loaderFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+
+			term, _ := p.Args["term"].(string)
+			department, _ := p.Args["department"].(string)
+			offerings, err := NewOfferingRepo(loader.sess).Search(term, department)
+			if err != nil {
+				return nil, err
+			}
+
+			out := []interface{}{}
+			for _, offering := range offerings {
+				loader.remember(reflect.ValueOf(offering))
+				out = append(out, offering)
+			}
+
+			return out, nil
+		},
+	}, nil
+}
+
+// enrollmentMutations are the enroll, drop and grade mutations, built on the Registrar and the GradeBook
+func (b *graphqlBuilder) enrollmentMutations() (graphql.Fields, error) {
+	enrollment, err := b.object(reflect.TypeOf(Enrollment{}))
+	if err != nil {
+		return nil, err
+	}
+
+	args := graphql.FieldConfigArgument{
+		"student":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the student"},
+		"offering": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course offering"},
+	}
+
+	enrollArgs := graphql.FieldConfigArgument{
+		"enrolled_date": &graphql.ArgumentConfig{Type: graphql.DateTime, Description: "defaults to now"},
+		"waitlist":      &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "joins the waitlist when the offering is full"},
+	}
+	dropArgs := graphql.FieldConfigArgument{
+		"withdraw": &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "marks the enrollment withdrawn instead of dropped"},
+	}
+	gradeArgs := graphql.FieldConfigArgument{
+		"grade": &graphql.ArgumentConfig{Type: graphql.String, Description: "letter grade, clears the grade when null"},
+	}
+	for name, arg := range args {
+		enrollArgs[name] = arg
+		dropArgs[name] = arg
+		gradeArgs[name] = arg
+	}
+
+	return graphql.Fields{
+		"enroll": &graphql.Field{
+			Type:        graphql.NewNonNull(enrollment),
+			Description: "enrolls a student in a course offering",
+			Args:        enrollArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				loader, err := loaderFrom(p.Context)
+				if err != nil {
+					return nil, err
+				}
+
+				edge := NewEnrollment(time.Now().UTC())
+				if date, ok := p.Args["enrolled_date"].(time.Time); ok {
+					edge.EnrolledDate = date.UTC()
+				}
+
+				err = graphqlTransaction(loader, func(repos *Repositories) error {
+					student, err := repos.Students.Get(p.Args["student"].(string))
+					if err != nil {
+						return err
+					}
+
+					offering, err := repos.Offerings.Get(p.Args["offering"].(string))
+					if err != nil {
+						return err
+					}
+
+					registrar := NewRegistrar(loader.sess)
+					registrar.Waitlist, _ = p.Args["waitlist"].(bool)
+					return registrar.Enroll(student, offering, edge)
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loader.remember(reflect.ValueOf(edge.Start), reflect.ValueOf(edge.End))
+				return edge, nil
+			},
+		},
+		"drop": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "drops a student from a course offering, the enrollment is kept with its status",
+			Args:        dropArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				loader, err := loaderFrom(p.Context)
+				if err != nil {
+					return nil, err
+				}
+
+				err = graphqlTransaction(loader, func(repos *Repositories) error {
+					student, err := repos.Students.Get(p.Args["student"].(string))
+					if err != nil {
+						return err
+					}
+
+					offering, err := repos.Offerings.Get(p.Args["offering"].(string))
+					if err != nil {
+						return err
+					}
+
+					withdraw, _ := p.Args["withdraw"].(bool)
+					_, err = NewRegistrar(loader.sess).Drop(student, offering, time.Now().UTC(), withdraw)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				return true, nil
+			},
+		},
+		"grade": &graphql.Field{
+			Type:        graphql.NewNonNull(enrollment),
+			Description: "records the final grade of a student in a course offering",
+			Args:        gradeArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				loader, err := loaderFrom(p.Context)
+				if err != nil {
+					return nil, err
+				}
+
+				var edge *Enrollment
+				err = graphqlTransaction(loader, func(repos *Repositories) error {
+					student, err := repos.Students.Get(p.Args["student"].(string))
+					if err != nil {
+						return err
+					}
+
+					offering, err := repos.Offerings.Get(p.Args["offering"].(string))
+					if err != nil {
+						return err
+					}
+
+					grades := NewGradeBook(loader.sess)
+					if grade, ok := p.Args["grade"].(string); ok {
+						edge, err = grades.Record(student, offering, grade)
+					} else {
+						edge, err = grades.Clear(student, offering)
+					}
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loader.remember(reflect.ValueOf(edge.Start))
+				return edge, nil
+			},
+		},
+	}, nil
+}
+
+// prerequisiteMutations add and remove prerequisites of courses through the Registrar
+func (b *graphqlBuilder) prerequisiteMutations() (graphql.Fields, error) {
+	course, err := b.object(reflect.TypeOf(Course{}))
+	if err != nil {
+		return nil, err
+	}
+
+	args := graphql.FieldConfigArgument{
+		"course":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course"},
+		"prerequisite": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course it requires"},
+	}
+
+	mutate := func(change func(registrar *Registrar, course, prerequisite *Course) error) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			loader, err := loaderFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+
+			var changed *Course
+			err = graphqlTransaction(loader, func(repos *Repositories) error {
+				changed, err = repos.Courses.Get(p.Args["course"].(string))
+				if err != nil {
+					return err
+				}
+
+				prerequisite, err := repos.Courses.Get(p.Args["prerequisite"].(string))
+				if err != nil {
+					return err
+				}
+
+				return change(NewRegistrar(loader.sess), changed, prerequisite)
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			loader.remember(reflect.ValueOf(changed))
+			return changed, nil
+		}
+	}
+
+	return graphql.Fields{
+		"add_prerequisite": &graphql.Field{
+			Type:        graphql.NewNonNull(course),
+			Description: "makes a course require another one, fails if the prerequisites would form a cycle",
+			Args:        args,
+			Resolve:     mutate((*Registrar).AddPrerequisite),
+		},
+		"remove_prerequisite": &graphql.Field{
+			Type:        graphql.NewNonNull(course),
+			Description: "makes a course no longer require another one",
+			Args:        args,
+			Resolve:     mutate((*Registrar).RemovePrerequisite),
+		},
+	}, nil
+}
+
+// scheduleMutations add and remove meetings of course offerings through the Registrar
+func (b *graphqlBuilder) scheduleMutations() (graphql.Fields, error) {
+	meeting, err := b.object(reflect.TypeOf(Meeting{}))
+	if err != nil {
+		return nil, err
+	}
+
+	return graphql.Fields{
+		"schedule": &graphql.Field{
+			Type:        graphql.NewNonNull(meeting),
+			Description: "adds a weekly meeting of a course offering in a room, fails if the room or the teacher is busy then",
+			Args: graphql.FieldConfigArgument{
+				"offering":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course offering"},
+				"room":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the room"},
+				"day":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "weekday like monday"},
+				"start_time": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "24 hour clock time like 09:30"},
+				"end_time":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "24 hour clock time like 10:45"},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				loader, err := loaderFrom(p.Context)
+				if err != nil {
+					return nil, err
+				}
+
+				edge, err := NewMeeting(p.Args["day"].(string), p.Args["start_time"].(string), p.Args["end_time"].(string))
+				if err != nil {
+					return nil, err
+				}
+
+				err = graphqlTransaction(loader, func(repos *Repositories) error {
+					offering, err := repos.Offerings.Get(p.Args["offering"].(string))
+					if err != nil {
+						return err
+					}
+
+					room, err := repos.Rooms.Get(p.Args["room"].(string))
+					if err != nil {
+						return err
+					}
+
+					return NewRegistrar(loader.sess).Schedule(offering, room, edge)
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loader.remember(reflect.ValueOf(edge.Start), reflect.ValueOf(edge.End))
+				return edge, nil
+			},
+		},
+		"unschedule": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "removes a meeting of a course offering",
+			Args: graphql.FieldConfigArgument{
+				"offering": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course offering"},
+				"meeting":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the meeting"},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				loader, err := loaderFrom(p.Context)
+				if err != nil {
+					return nil, err
+				}
+
+				err = graphqlTransaction(loader, func(repos *Repositories) error {
+					offering, err := repos.Offerings.Get(p.Args["offering"].(string))
+					if err != nil {
+						return err
+					}
+
+					return NewRegistrar(loader.sess).Unschedule(offering, &Meeting{BaseNode: gogm.BaseNode{UUID: p.Args["meeting"].(string)}})
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				return true, nil
+			},
+		},
+	}, nil
+}
+
+// offerMutation offers a section of a course in a term through the Registrar
+func (b *graphqlBuilder) offerMutation() (*graphql.Field, error) {
+	offering, err := b.object(reflect.TypeOf(CourseOffering{}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphql.Field{
+		Type:        graphql.NewNonNull(offering),
+		Description: "offers a section of a course in a term, fails if the term already has that section",
+		Args: graphql.FieldConfigArgument{
+			"course":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the course"},
+			"term":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID), Description: "uuid of the term"},
+			"section":  &graphql.ArgumentConfig{Type: graphql.String, Description: "defaults to " + defaultSection},
+			"teacher":  &graphql.ArgumentConfig{Type: graphql.ID, Description: "uuid of the teacher"},
+			"capacity": &graphql.ArgumentConfig{Type: graphql.Int, Description: "seats, 0 or null for unlimited"},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			loader, err := loaderFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+
+			var offered *CourseOffering
+			err = graphqlTransaction(loader, func(repos *Repositories) error {
+				course, err := repos.Courses.Get(p.Args["course"].(string))
+				if err != nil {
+					return err
+				}
+
+				term, err := repos.Terms.Get(p.Args["term"].(string))
+				if err != nil {
+					return err
+				}
+
+				var teacher *Teacher
+				if uuid, ok := p.Args["teacher"].(string); ok {
+					teacher, err = repos.Teachers.Get(uuid)
+					if err != nil {
+						return err
+					}
+				}
+
+				section, _ := p.Args["section"].(string)
+				capacity, _ := p.Args["capacity"].(int)
+				offered, err = NewRegistrar(loader.sess).Offer(course, term, section, teacher, capacity)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			loader.remember(reflect.ValueOf(offered))
+			return offered, nil
+		},
+	}, nil
+}
+
+// graphqlTransaction runs a mutation in a transaction, nodes loaded before it are forgotten
+func graphqlTransaction(loader *graphqlLoader, fn func(repos *Repositories) error) error {
+	loader.forget()
+
+	err := loader.sess.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(NewRepositories(loader.sess))
+	if err != nil {
+		return loader.sess.RollbackWithError(err)
+	}
+
+	err = loader.sess.Commit()
+	if err != nil {
+		return loader.sess.RollbackWithError(err)
+	}
+
+	return nil
+}
+
+// graphqlRequest is the body of a graphql request
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ExecuteGraphQL runs a graphql request against sess
+func ExecuteGraphQL(ctx context.Context, schema graphql.Schema, sess gogm.ISession, query, operationName string, variables map[string]interface{}) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  query,
+		OperationName:  operationName,
+		VariableValues: variables,
+		Context:        context.WithValue(ctx, graphqlLoaderKey{}, newGraphQLLoader(sess)),
+	})
+}
+
+// graphqlOperationType returns the type of the operation a request runs, empty when the query can not be
+// parsed or has no operation by that name, graphql reports those itself
+func graphqlOperationType(query, operationName string) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return ""
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		if operation, ok := def.(*ast.OperationDefinition); ok {
+			operations = append(operations, operation)
+		}
+	}
+
+	for _, operation := range operations {
+		if operationName == "" && len(operations) == 1 || operation.Name != nil && operation.Name.Value == operationName {
+			return operation.Operation
+		}
+	}
+
+	return ""
+}
+
+// graphqlQuery serves /graphql, GET takes the query in the url and POST as a json body.
+// GET only runs queries, so a link can never change the school.
+func (s *APIServer) graphqlQuery(req *apiRequest, _ []string) (int, interface{}, error) {
+	var in graphqlRequest
+	if req.r.Method == http.MethodGet {
+		query := req.r.URL.Query()
+		in.Query = query.Get("query")
+		in.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			err := json.Unmarshal([]byte(vars), &in.Variables)
+			if err != nil {
+				return 0, nil, fmt.Errorf("%w, invalid variables: %v", errBadRequest, err)
+			}
+		}
+	} else {
+		err := json.NewDecoder(http.MaxBytesReader(req.w, req.r.Body, maxBodySize)).Decode(&in)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w, invalid json body: %v", errBadRequest, err)
+		}
+	}
+
+	if strings.TrimSpace(in.Query) == "" {
+		return 0, nil, fmt.Errorf("%w, query is required", errBadRequest)
+	}
+
+	if req.r.Method == http.MethodGet {
+		if operation := graphqlOperationType(in.Query, in.OperationName); operation != "" && operation != ast.OperationTypeQuery {
+			req.w.Header().Set("Allow", http.MethodPost)
+			return 0, nil, fmt.Errorf("%w, a %s has to be sent with POST", errMethodNotAllowed, operation)
+		}
+	}
+
+	// graphql reports errors in the result, the status is ok as long as the request could be read
+	return http.StatusOK, ExecuteGraphQL(req.r.Context(), s.graphql, req.sess, in.Query, in.OperationName, in.Variables), nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// toSnakeCase turns a go field name like EnrolledDate into enrolled_date
+func toSnakeCase(s string) string {
+	var out []rune
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
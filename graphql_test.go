@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLConnections(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	schema, err := NewGraphQLSchema(registeredTypes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "edges carry the properties of the edge and the node",
+			query: `{ teachers(name: "Crosby") { offerings { name enrollments { totalCount edges { status node { name } } } } } }`,
+			want: `{"teachers": [{"offerings": [{"name": "hist347-0 Fall 2026", "enrollments": {"totalCount": 4, "edges": [
+				{"status": "enrolled", "node": {"name": "eric"}},
+				{"status": "enrolled", "node": {"name": "nikita"}},
+				{"status": "enrolled", "node": {"name": "steven"}},
+				{"status": "enrolled", "node": {"name": "michael"}}]}}]}]}`,
+		},
+		{
+			name:  "the start of an edge sees the end",
+			query: `{ student(uuid: "` + school.Students["michael"].UUID + `") { enrollments { totalCount edges { node { name course { name } } } } } }`,
+			want: `{"student": {"enrollments": {"totalCount": 2, "edges": [
+				{"node": {"name": "hist347-0 Fall 2026", "course": {"name": "hist347"}}},
+				{"node": {"name": "phys122-0 Fall 2026", "course": {"name": "phys122"}}}]}}}`,
+		},
+		{
+			name:  "outgoing edges",
+			query: `{ offerings(term: "Fall 2026", department: "Physics") { meetings { totalCount edges { day start_time end_time node { name } } } } }`,
+			want: `{"offerings": [{"meetings": {"totalCount": 1, "edges": [
+				{"day": "friday", "start_time": "10:00", "end_time": "12:00", "node": {"name": "Baker 210"}}]}}]}`,
+		},
+		{
+			name:  "missing node",
+			query: `{ student(uuid: "nobody") { name } }`,
+			want:  `{"student": null}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExecuteGraphQL(context.Background(), schema, sess, tt.query, "", nil)
+			if result.HasErrors() {
+				t.Fatalf("%v", result.Errors)
+			}
+
+			got, err := json.Marshal(result.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotData, wantData interface{}
+			json.Unmarshal(got, &gotData)
+			err = json.Unmarshal([]byte(tt.want), &wantData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotData, wantData) {
+				t.Errorf("got %s", got)
+			}
+		})
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+	schema, err := NewGraphQLSchema(registeredTypes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{`{ students(name: "eric") { nme } }`, `Cannot query field "nme" on type "Student"`},
+		{`{ student { name } }`, `argument "uuid" of type "ID!" is required`},
+		{`mutation { enroll(student: "nobody", offering: "nothing") { status } }`, "no Student with uuid [nobody]"},
+	} {
+		result := ExecuteGraphQL(context.Background(), schema, sess, tt.query, "", nil)
+		if !result.HasErrors() || !strings.Contains(result.Errors[0].Message, tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.query, result.Errors, tt.want)
+		}
+	}
+}
+
+func TestGraphQLOverHTTP(t *testing.T) {
+	server, school := newTestServer(t)
+	defer server.Close()
+
+	args := fmt.Sprintf(`student: %q, offering: %q`, school.Students["eric"].UUID, school.Offerings["cs341-0 "+exampleTerm].UUID)
+	drop := `mutation { drop(` + args + `) }`
+	named := `query students { students { name } } mutation dropEric { drop(` + args + `) }`
+
+	// the requests run in order, eric is only dropped by the POST, which fails if a GET dropped him first
+	for _, tt := range []struct {
+		method, query, operationName string
+		want                         int
+	}{
+		{"GET", `{ students { name } }`, "", http.StatusOK},
+		{"GET", drop, "", http.StatusMethodNotAllowed},
+		{"GET", named, "students", http.StatusOK},
+		{"GET", named, "dropEric", http.StatusMethodNotAllowed},
+		{"GET", `{ students { nme } }`, "", http.StatusOK},
+		{"POST", drop, "", http.StatusOK},
+	} {
+		var resp *http.Response
+		var err error
+		if tt.method == http.MethodGet {
+			resp, err = http.Get(server.URL + "/graphql?" + url.Values{"query": {tt.query}, "operationName": {tt.operationName}}.Encode())
+		} else {
+			body, _ := json.Marshal(map[string]string{"query": tt.query, "operationName": tt.operationName})
+			resp, err = http.Post(server.URL+"/graphql", "application/json", bytes.NewReader(body))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s %s: got %v, want %v", tt.method, tt.query, tt.operationName, resp.StatusCode, tt.want)
+		}
+		if resp.StatusCode == http.StatusMethodNotAllowed && resp.Header.Get("Allow") != http.MethodPost {
+			t.Errorf("%s %s: allows %q, want POST", tt.method, tt.query, resp.Header.Get("Allow"))
+		}
+		if tt.method == http.MethodPost && len(result.Errors) != 0 {
+			t.Errorf("%s %s: %s", tt.method, tt.query, result.Errors[0].Message)
+		}
+	}
+}
@@ -0,0 +1,822 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultImportBatchSize is the number of nodes saved per transaction when RosterImporter.BatchSize is not set
+const defaultImportBatchSize = 100
+
+// the files read by RosterImporter and their columns, a missing file imports nothing.
+// columns in brackets are optional
+//
+//	departments.csv  name
+//	subjects.csv     name, department
+//	teachers.csv     name, [department], [subjects]     subjects are separated by ;
+//	courses.csv      name, subject
+//	terms.csv        name
+//	offerings.csv    course, term, [section], [teacher], [capacity]   section defaults to 0, capacity to 0, unlimited
+//	students.csv     name
+//	enrollments.csv  student, course, term, [section], [enrolled_date]   RFC 3339 or 2006-01-02, defaults to now
+//
+// the section of an enrollment can be left out when the course has only one section in the term
+const (
+	departmentsFile = "departments.csv"
+	subjectsFile    = "subjects.csv"
+	teachersFile    = "teachers.csv"
+	coursesFile     = "courses.csv"
+	termsFile       = "terms.csv"
+	offeringsFile   = "offerings.csv"
+	studentsFile    = "students.csv"
+	enrollmentsFile = "enrollments.csv"
+)
+
+// ImportError is a problem with one row of a roster file
+type ImportError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%s:%v: %s", e.File, e.Line, e.Err.Error())
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportErrors lists every problem found in a roster, nothing is saved when there are any
+type ImportErrors []*ImportError
+
+func (e ImportErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return fmt.Sprintf("%v problems in the roster:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// ImportResult counts what an import created
+type ImportResult struct {
+	Departments int
+	Subjects    int
+	Teachers    int
+	Courses     int
+	Terms       int
+	Offerings   int
+	Students    int
+	Enrollments int
+
+	// Batches is the number of committed transactions, always 0 for a dry run
+	Batches int
+}
+
+// RosterImporter builds the school graph from csv files that reference each other by name.
+// names have to be unique per type, both in the files and in the graph, since they are used as references.
+// offerings are referenced by their course, term and section instead.
+// references are looked up in the files first and in the graph second
+type RosterImporter struct {
+	// DryRun reads, validates and links everything without saving it
+	DryRun bool
+	// BatchSize is the number of nodes saved per transaction, defaults to defaultImportBatchSize
+	BatchSize int
+
+	sess  gogm.ISession
+	repos *Repositories
+}
+
+// NewRosterImporter creates a RosterImporter saving to sess
+func NewRosterImporter(sess gogm.ISession) *RosterImporter {
+	return &RosterImporter{
+		sess:  sess,
+		repos: NewRepositories(sess),
+	}
+}
+
+// roster is the state of one import
+type roster struct {
+	repos     *Repositories
+	registrar *Registrar
+
+	departments map[string]*Department
+	subjects    map[string]*Subject
+	teachers    map[string]*Teacher
+	courses     map[string]*Course
+	terms       map[string]*Term
+	students    map[string]*Student
+	// offerings are keyed by their name, which is made from the course, section and term
+	offerings map[string]*CourseOffering
+
+	// save lists the nodes to save in order, created nodes and existing nodes that got new relationships
+	save  []interface{}
+	saved map[interface{}]bool
+
+	result ImportResult
+	errs   ImportErrors
+}
+
+// ImportDir imports the roster files in dir
+func (im *RosterImporter) ImportDir(dir string) (*ImportResult, error) {
+	r := &roster{
+		repos:       im.repos,
+		registrar:   NewRegistrar(im.sess),
+		departments: map[string]*Department{},
+		subjects:    map[string]*Subject{},
+		teachers:    map[string]*Teacher{},
+		courses:     map[string]*Course{},
+		terms:       map[string]*Term{},
+		students:    map[string]*Student{},
+		offerings:   map[string]*CourseOffering{},
+		saved:       map[interface{}]bool{},
+	}
+
+	steps := []struct {
+		file     string
+		required []string
+		optional []string
+		row      func(row *csvRow) error
+	}{
+		{departmentsFile, []string{"name"}, nil, r.department},
+		{subjectsFile, []string{"name", "department"}, nil, r.subject},
+		{teachersFile, []string{"name"}, []string{"department", "subjects"}, r.teacher},
+		{coursesFile, []string{"name", "subject"}, nil, r.course},
+		{termsFile, []string{"name"}, nil, r.term},
+		{offeringsFile, []string{"course", "term"}, []string{"section", "teacher", "capacity"}, r.offering},
+		{studentsFile, []string{"name"}, nil, r.student},
+		{enrollmentsFile, []string{"student", "course", "term"}, []string{"section", "enrolled_date"}, r.enrollment},
+	}
+
+	for _, step := range steps {
+		rows, err := readCSV(filepath.Join(dir, step.file), step.required, step.optional)
+		if err != nil {
+			var importErr *ImportError
+			if errors.As(err, &importErr) {
+				r.errs = append(r.errs, importErr)
+				continue
+			}
+			return nil, err
+		}
+
+		for _, row := range rows {
+			err = step.row(row)
+			if err != nil {
+				r.errs = append(r.errs, &ImportError{File: step.file, Line: row.line, Err: err})
+			}
+		}
+	}
+
+	if len(r.errs) != 0 {
+		return nil, r.errs
+	}
+
+	if im.DryRun {
+		return &r.result, nil
+	}
+
+	err := im.saveBatches(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.result, nil
+}
+
+// saveBatches saves every node to a depth of one, so each relationship is written by one of its ends
+func (im *RosterImporter) saveBatches(r *roster) error {
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	for start := 0; start < len(r.save); start += batchSize {
+		end := start + batchSize
+		if end > len(r.save) {
+			end = len(r.save)
+		}
+
+		err := im.sess.Begin()
+		if err != nil {
+			return err
+		}
+
+		for _, node := range r.save[start:end] {
+			err = SaveValidated(im.sess, node, 1)
+			if err != nil {
+				return fmt.Errorf("batch %v failed after %v batches were committed, %w", r.result.Batches+1, r.result.Batches, im.sess.RollbackWithError(err))
+			}
+		}
+
+		err = im.sess.Commit()
+		if err != nil {
+			return fmt.Errorf("batch %v failed after %v batches were committed, %w", r.result.Batches+1, r.result.Batches, im.sess.RollbackWithError(err))
+		}
+
+		r.result.Batches++
+	}
+
+	return nil
+}
+
+// csvRow is one record of a roster file by column name
+type csvRow struct {
+	line   int
+	values map[string]string
+}
+
+func (row *csvRow) get(column string) string {
+	return strings.TrimSpace(row.values[column])
+}
+
+// readCSV reads a roster file with a header row, returns no rows if the file does not exist.
+// problems with the file itself are returned as *ImportError
+func readCSV(path string, required, optional []string) ([]*csvRow, error) {
+	file := filepath.Base(path)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, &ImportError{File: file, Line: 1, Err: err}
+	}
+
+	known := map[string]bool{}
+	for _, column := range append(append([]string{}, required...), optional...) {
+		known[column] = true
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !known[column] {
+			return nil, &ImportError{File: file, Line: 1, Err: fmt.Errorf("unknown column %q", column)}
+		}
+		columns[column] = i
+	}
+
+	for _, column := range required {
+		if _, ok := columns[column]; !ok {
+			return nil, &ImportError{File: file, Line: 1, Err: fmt.Errorf("missing column %q", column)}
+		}
+	}
+
+	var rows []*csvRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, &ImportError{File: file, Line: line, Err: err}
+		}
+
+		row := &csvRow{line: line, values: map[string]string{}}
+		for column, i := range columns {
+			if i >= len(record) {
+				continue
+			}
+
+			// line numbers are counted per record, so values may not span lines
+			if strings.ContainsAny(record[i], "\r\n") {
+				return nil, &ImportError{File: file, Line: line, Err: fmt.Errorf("value of %q spans several lines", column)}
+			}
+
+			row.values[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// touch queues node for saving, once
+func (r *roster) touch(nodes ...interface{}) {
+	for _, node := range nodes {
+		if !r.saved[node] {
+			r.saved[node] = true
+			r.save = append(r.save, node)
+		}
+	}
+}
+
+// newName checks the name of a node to create, it must not be taken in the files or the graph
+func newName(typ, name string, inFiles bool, inGraph func(name string) (bool, error)) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	if inFiles {
+		return fmt.Errorf("%s %s is listed twice", typ, name)
+	}
+
+	exists, err := inGraph(name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("%s %s already exists", typ, name)
+	}
+
+	return nil
+}
+
+func (r *roster) department(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("department", name, r.departments[name] != nil, func(name string) (bool, error) {
+		found, err := r.repos.Departments.FindByName(name)
+		return len(found) != 0, err
+	})
+	if err != nil {
+		return err
+	}
+
+	department := &Department{Name: name}
+	r.departments[name] = department
+	r.touch(department)
+	r.result.Departments++
+	return nil
+}
+
+func (r *roster) subject(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("subject", name, r.subjects[name] != nil, func(name string) (bool, error) {
+		found, err := r.repos.Subjects.FindByName(name)
+		return len(found) != 0, err
+	})
+	if err != nil {
+		return err
+	}
+
+	department, err := r.findDepartment(row.get("department"))
+	if err != nil {
+		return err
+	}
+
+	subject := &Subject{Name: name}
+	err = subject.LinkToDepartmentOnFieldDepartment(department)
+	if err != nil {
+		return err
+	}
+
+	r.subjects[name] = subject
+	r.touch(subject)
+	r.result.Subjects++
+	return nil
+}
+
+func (r *roster) teacher(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("teacher", name, r.teachers[name] != nil, func(name string) (bool, error) {
+		_, err := r.repos.Teachers.FindByName(name)
+		if errors.Is(err, gogm.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	teacher := &Teacher{Name: name}
+
+	if departmentName := row.get("department"); departmentName != "" {
+		department, err := r.findDepartment(departmentName)
+		if err != nil {
+			return err
+		}
+
+		err = teacher.LinkToDepartmentOnFieldDepartment(department)
+		if err != nil {
+			return err
+		}
+	}
+
+	var subjects []*Subject
+	for _, subjectName := range strings.Split(row.get("subjects"), ";") {
+		subjectName = strings.TrimSpace(subjectName)
+		if subjectName == "" {
+			continue
+		}
+
+		subject, err := r.findSubject(subjectName)
+		if err != nil {
+			return err
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) != 0 {
+		err = teacher.LinkToSubjectOnFieldSubjectsWith(&LinkOptions{Duplicates: FailOnDuplicate}, subjects...)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.teachers[name] = teacher
+	r.touch(teacher)
+	r.result.Teachers++
+	return nil
+}
+
+func (r *roster) course(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("course", name, r.courses[name] != nil, func(name string) (bool, error) {
+		found, err := r.repos.Courses.FindByName(name)
+		return len(found) != 0, err
+	})
+	if err != nil {
+		return err
+	}
+
+	subject, err := r.findSubject(row.get("subject"))
+	if err != nil {
+		return err
+	}
+
+	course := &Course{Name: name}
+	err = course.LinkToSubjectOnFieldSubject(subject)
+	if err != nil {
+		return err
+	}
+
+	r.courses[name] = course
+	r.touch(course)
+	r.result.Courses++
+	return nil
+}
+
+func (r *roster) term(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("term", name, r.terms[name] != nil, func(name string) (bool, error) {
+		_, err := r.repos.Terms.FindByName(name)
+		if errors.Is(err, gogm.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	term := &Term{Name: name}
+	r.terms[name] = term
+	r.touch(term)
+	r.result.Terms++
+	return nil
+}
+
+func (r *roster) offering(row *csvRow) error {
+	course, err := r.findCourse(row.get("course"))
+	if err != nil {
+		return err
+	}
+
+	term, err := r.findTerm(row.get("term"))
+	if err != nil {
+		return err
+	}
+
+	name := offeringName(course, term, sectionOrDefault(row.get("section")))
+	err = newName("offering", name, r.offerings[name] != nil, func(name string) (bool, error) {
+		found, err := r.repos.Offerings.FindByName(name)
+		return len(found) != 0, err
+	})
+	if err != nil {
+		return err
+	}
+
+	offering, err := NewCourseOffering(course, term, row.get("section"))
+	if err != nil {
+		return err
+	}
+
+	if capacity := row.get("capacity"); capacity != "" {
+		offering.Capacity, err = strconv.Atoi(capacity)
+		if err != nil || offering.Capacity < 0 {
+			return fmt.Errorf("capacity %q is not a number of seats", capacity)
+		}
+	}
+
+	if teacherName := row.get("teacher"); teacherName != "" {
+		teacher, err := r.findTeacher(teacherName)
+		if err != nil {
+			return err
+		}
+
+		err = offering.LinkToTeacherOnFieldTeacher(teacher)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the offering is saved after its course and term, so saving it writes both relationships
+	r.offerings[name] = offering
+	r.touch(offering)
+	r.result.Offerings++
+	return nil
+}
+
+func (r *roster) student(row *csvRow) error {
+	name := row.get("name")
+
+	err := newName("student", name, r.students[name] != nil, func(name string) (bool, error) {
+		_, err := r.repos.Students.FindByName(name)
+		if errors.Is(err, gogm.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	student := &Student{Name: name}
+	r.students[name] = student
+	r.touch(student)
+	r.result.Students++
+	return nil
+}
+
+func (r *roster) enrollment(row *csvRow) error {
+	student, err := r.findStudent(row.get("student"))
+	if err != nil {
+		return err
+	}
+
+	offering, err := r.findOffering(row.get("course"), row.get("term"), row.get("section"))
+	if err != nil {
+		return err
+	}
+
+	enrolledDate := time.Now().UTC()
+	if date := row.get("enrolled_date"); date != "" {
+		enrolledDate, err = parseImportDate(date)
+		if err != nil {
+			return err
+		}
+	}
+
+	// like the Registrar, students that left the offering before are enrolled again
+	if current := latestEnrollment(student, offering); current != nil && (current.Active() || current.Waitlisted()) {
+		return fmt.Errorf("%s is already enrolled in %s", student.Name, offering.Name)
+	}
+
+	if offering.Capacity > 0 && enrolledCount(offering) >= offering.Capacity {
+		return fmt.Errorf("%s is full with %v students", offering.Name, offering.Capacity)
+	}
+
+	// like Registrar.Enroll, the student is checked when linked and needs its schedule and the prerequisites
+	// of the course for that, nodes from the graph are loaded without them
+	err = r.registrar.loadStudentSchedule(student)
+	if err != nil {
+		return err
+	}
+
+	if offering.Course != nil && offering.Course.UUID != "" {
+		course, err := r.repos.Courses.Get(offering.Course.UUID)
+		if err != nil {
+			return err
+		}
+
+		offering.Course.Prerequisites = course.Prerequisites
+	}
+
+	opts := &LinkOptions{Duplicates: AllowDuplicates}
+	err = student.LinkToCourseOfferingOnFieldEnrollmentsWith(opts, offering, NewEnrollment(enrolledDate))
+	if err != nil {
+		return err
+	}
+
+	// both ends may already exist, saving the student writes the enrollment
+	r.touch(student)
+	r.result.Enrollments++
+	return nil
+}
+
+// parseImportDate accepts RFC 3339 timestamps and plain dates
+func parseImportDate(date string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, date)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("enrolled_date %q is neither RFC 3339 nor 2006-01-02", date)
+}
+
+// onlyExisting checks that a reference found exactly one node in the graph
+func onlyExisting(typ, name string, found int) error {
+	switch found {
+	case 0:
+		return fmt.Errorf("unknown %s %s", typ, name)
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("%s %s is ambiguous, there are %v of them in the graph", typ, name, found)
+	}
+}
+
+// the find functions resolve a reference, first in the files and then in the graph.
+// nodes from the graph are remembered so every reference to them shares one instance
+
+func (r *roster) findDepartment(name string) (*Department, error) {
+	if name == "" {
+		return nil, errors.New("department is required")
+	}
+
+	if department, ok := r.departments[name]; ok {
+		return department, nil
+	}
+
+	found, err := r.repos.Departments.FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = onlyExisting("department", name, len(found))
+	if err != nil {
+		return nil, err
+	}
+
+	r.departments[name] = found[0]
+	r.touch(found[0])
+	return found[0], nil
+}
+
+func (r *roster) findSubject(name string) (*Subject, error) {
+	if name == "" {
+		return nil, errors.New("subject is required")
+	}
+
+	if subject, ok := r.subjects[name]; ok {
+		return subject, nil
+	}
+
+	found, err := r.repos.Subjects.FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = onlyExisting("subject", name, len(found))
+	if err != nil {
+		return nil, err
+	}
+
+	r.subjects[name] = found[0]
+	r.touch(found[0])
+	return found[0], nil
+}
+
+func (r *roster) findTeacher(name string) (*Teacher, error) {
+	if teacher, ok := r.teachers[name]; ok {
+		return teacher, nil
+	}
+
+	teacher, err := r.repos.Teachers.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil, fmt.Errorf("unknown teacher %s", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.teachers[name] = teacher
+	r.touch(teacher)
+	return teacher, nil
+}
+
+func (r *roster) findCourse(name string) (*Course, error) {
+	if name == "" {
+		return nil, errors.New("course is required")
+	}
+
+	if course, ok := r.courses[name]; ok {
+		return course, nil
+	}
+
+	found, err := r.repos.Courses.FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = onlyExisting("course", name, len(found))
+	if err != nil {
+		return nil, err
+	}
+
+	r.courses[name] = found[0]
+	r.touch(found[0])
+	return found[0], nil
+}
+
+func (r *roster) findTerm(name string) (*Term, error) {
+	if name == "" {
+		return nil, errors.New("term is required")
+	}
+
+	if term, ok := r.terms[name]; ok {
+		return term, nil
+	}
+
+	term, err := r.repos.Terms.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil, fmt.Errorf("unknown term %s", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.terms[name] = term
+	r.touch(term)
+	return term, nil
+}
+
+// findOffering resolves an offering by its course, term and section, the section may be left out
+// if the course has only one section in the term
+func (r *roster) findOffering(courseName, termName, section string) (*CourseOffering, error) {
+	course, err := r.findCourse(courseName)
+	if err != nil {
+		return nil, err
+	}
+
+	if termName == "" {
+		return nil, errors.New("term is required")
+	}
+
+	// offerings of courses from the graph are remembered like every other node
+	if course.UUID != "" {
+		found, err := r.repos.Offerings.FindOf(course, termName, section)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, offering := range found {
+			if _, ok := r.offerings[offering.Name]; !ok {
+				r.offerings[offering.Name] = offering
+			}
+		}
+	}
+
+	var matches []*CourseOffering
+	for _, offering := range r.offerings {
+		if sameCourse(offering.Course, course) && offering.Term != nil && offering.Term.Name == termName &&
+			(section == "" || offering.Section == section) {
+			matches = append(matches, offering)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%s is not offered in %s", courseName, termName)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%s has %v sections in %s, the section is required", courseName, len(matches), termName)
+	}
+}
+
+func (r *roster) findStudent(name string) (*Student, error) {
+	if name == "" {
+		return nil, errors.New("student is required")
+	}
+
+	if student, ok := r.students[name]; ok {
+		return student, nil
+	}
+
+	student, err := r.repos.Students.FindByName(name)
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil, fmt.Errorf("unknown student %s", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.students[name] = student
+	r.touch(student)
+	return student, nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeRoster writes the roster files to a new temporary directory and returns it
+func writeRoster(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "gogm-example-roster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// testRoster is a small school in roster files
+var testRoster = map[string]string{
+	departmentsFile: "name\nMath\n",
+	subjectsFile:    "name,department\nAlgebra,Math\nGeometry,Math\n",
+	teachersFile:    "name,department,subjects\nNoether,Math,Algebra;Geometry\n",
+	coursesFile:     "name,subject\nmath101,Algebra\n",
+	termsFile:       "name\nSpring 2027\n",
+	offeringsFile:   "course,term,section,teacher,capacity\nmath101,Spring 2027,,Noether,2\nmath101,Spring 2027,1,,\n",
+	studentsFile:    "\ufeffName\nanna\nbeth\n",
+	enrollmentsFile: "student,course,term,section,enrolled_date\nanna,math101,Spring 2027,0,2027-01-10\nbeth,math101,Spring 2027,1,2027-01-11T09:00:00Z\n",
+}
+
+func TestImportDir(t *testing.T) {
+	dir := writeRoster(t, testRoster)
+	defer os.RemoveAll(dir)
+
+	sess := newTestSession(t)
+	importer := NewRosterImporter(sess)
+	importer.BatchSize = 4
+
+	result, err := importer.ImportDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ImportResult{Departments: 1, Subjects: 2, Teachers: 1, Courses: 1, Terms: 1, Offerings: 2, Students: 2, Enrollments: 2, Batches: 3}
+	if *result != want {
+		t.Errorf("got %+v, want %+v", *result, want)
+	}
+
+	for _, tt := range []struct {
+		node interface{}
+		want int
+	}{
+		{&Department{}, 1},
+		{&Subject{}, 2},
+		{&Teacher{}, 1},
+		{&CourseOffering{}, 2},
+		{&Student{}, 2},
+	} {
+		if got := countNodes(sess, tt.node); got != tt.want {
+			t.Errorf("%T: got %v nodes, want %v", tt.node, got, tt.want)
+		}
+	}
+
+	anna, err := NewRepositories(sess).Students.FindByName("anna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anna.Enrollments) != 1 || anna.Enrollments[0].End.Name != "math101-0 Spring 2027" || anna.Enrollments[0].EnrolledDate.Format("2006-01-02") != "2027-01-10" {
+		t.Errorf("anna is not enrolled in math101-0 on 2027-01-10: %+v", anna.Enrollments)
+	}
+}
+
+func TestImportIntoGraph(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+	dir := writeRoster(t, map[string]string{
+		studentsFile:    "name\nanna\n",
+		enrollmentsFile: "student,course,term,section\nanna,hist347,Fall 2026,\nmichael,cs341,Fall 2026,1\n",
+	})
+	defer os.RemoveAll(dir)
+
+	result, err := NewRosterImporter(sess).ImportDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Students != 1 || result.Enrollments != 2 {
+		t.Errorf("got %+v, want 1 student and 2 enrollments", *result)
+	}
+
+	enrolled := 0
+	for _, rel := range sess.read().rels {
+		if rel.Type == "ENROLLED" {
+			enrolled++
+		}
+	}
+	if enrolled != 12 {
+		t.Errorf("got %v enrollments in the graph, want 12", enrolled)
+	}
+}
+
+func TestImportChecksPrerequisites(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	cs341, err := repos.Courses.Get(school.Courses["cs341"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phys122, err := repos.Courses.Get(school.Courses["phys122"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = NewRegistrar(sess).AddPrerequisite(cs341, phys122)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	michael, err := repos.Students.Get(school.Students["michael"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offering, err := repos.Offerings.Get(school.Offerings["phys122-0 "+exampleTerm].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewGradeBook(sess).Record(michael, offering, "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// michael passed phys122 and takes a new section, nikita never took it
+	files := map[string]string{
+		offeringsFile:   "course,term,section\ncs341,Fall 2026,2\n",
+		enrollmentsFile: "student,course,term,section\nmichael,cs341,Fall 2026,2\nnikita,cs341,Fall 2026,0\n",
+	}
+	dir := writeRoster(t, files)
+	defer os.RemoveAll(dir)
+
+	_, err = NewRosterImporter(sess).ImportDir(dir)
+	var errs ImportErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Line != 3 || !errors.Is(errs[0], ErrMissingPrerequisites) {
+		t.Fatalf("got %v, want nikita to miss phys122 on line 3", err)
+	}
+
+	files[enrollmentsFile] = "student,course,term,section\nmichael,cs341,Fall 2026,2\n"
+	dir = writeRoster(t, files)
+	defer os.RemoveAll(dir)
+
+	result, err := NewRosterImporter(sess).ImportDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Enrollments != 1 {
+		t.Errorf("got %+v, want michael enrolled", *result)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	dir := writeRoster(t, testRoster)
+	defer os.RemoveAll(dir)
+
+	sess := newTestSession(t)
+	importer := NewRosterImporter(sess)
+	importer.DryRun = true
+
+	result, err := importer.ImportDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Enrollments != 2 || result.Batches != 0 {
+		t.Errorf("got %+v, want 2 enrollments in 0 batches", *result)
+	}
+	if n := len(sess.read().nodes); n != 0 {
+		t.Errorf("a dry run saved %v nodes", n)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name:  "unknown column",
+			files: map[string]string{studentsFile: "name,age\nanna,7\n"},
+			want:  []string{`students.csv:1: unknown column "age"`},
+		},
+		{
+			name:  "missing column",
+			files: map[string]string{coursesFile: "name\nmath101\n"},
+			want:  []string{`courses.csv:1: missing column "subject"`},
+		},
+		{
+			name:  "multi line value",
+			files: map[string]string{studentsFile: "name\nanna\n\"be\nth\"\n"},
+			want:  []string{`students.csv:3: value of "name" spans several lines`},
+		},
+		{
+			name:  "names",
+			files: map[string]string{studentsFile: "name\nanna\nanna\neric\n \n"},
+			want: []string{
+				"students.csv:3: student anna is listed twice",
+				"students.csv:4: student eric already exists",
+				"students.csv:5: name is required",
+			},
+		},
+		{
+			name: "references",
+			files: map[string]string{
+				offeringsFile: "course,term,section,capacity\nmath101,Fall 2026,,\ncs341,Spring 2027,,\ncs341,Fall 2026,0,\nphys122,Fall 2026,1,many\n",
+			},
+			want: []string{
+				"offerings.csv:2: unknown course math101",
+				"offerings.csv:3: unknown term Spring 2027",
+				"offerings.csv:4: offering cs341-0 Fall 2026 already exists",
+				`offerings.csv:5: capacity "many" is not a number of seats`,
+			},
+		},
+		{
+			name: "enrollments",
+			files: map[string]string{
+				enrollmentsFile: "student,course,term,enrolled_date\neric,cs341,Fall 2026,\nmichael,hist347,Fall 2026,\nmichael,cs341,Spring 2027,\nsteven,phys122,Fall 2026,yesterday\n",
+			},
+			want: []string{
+				"enrollments.csv:2: cs341 has 2 sections in Fall 2026, the section is required",
+				"enrollments.csv:3: michael is already enrolled in hist347-0 Fall 2026",
+				"enrollments.csv:4: cs341 is not offered in Spring 2027",
+				`enrollments.csv:5: enrolled_date "yesterday" is neither RFC 3339 nor 2006-01-02`,
+			},
+		},
+		{
+			name: "every file is checked",
+			files: map[string]string{
+				departmentsFile: "name\nHistory\n",
+				studentsFile:    "name\nanna\n",
+				enrollmentsFile: "student,course,term\nanna,art100,Fall 2026\n",
+			},
+			want: []string{
+				"departments.csv:2: department History already exists",
+				"enrollments.csv:2: unknown course art100",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sess, _ := seedTestSchool(t)
+			nodes := len(sess.read().nodes)
+
+			dir := writeRoster(t, tt.files)
+			defer os.RemoveAll(dir)
+
+			_, err := NewRosterImporter(sess).ImportDir(dir)
+			var errs ImportErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("got %v, want ImportErrors", err)
+			}
+
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			if n := len(sess.read().nodes); n != nodes {
+				t.Errorf("a failed import saved %v nodes", n-nodes)
+			}
+		})
+	}
+}
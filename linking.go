@@ -1,14 +1,42 @@
-// Code generated by GoGM v1.0.1. DO NOT EDIT
+// Code generated by linkgen from models.go. DO NOT EDIT.
+
 package main
 
 import (
-	"errors"
+	"fmt"
 )
 
-//LinkToSubjectOnFieldSubject links Course to Subject on the fields Course.Subject and Subject.Courses
+// LinkToSubjectOnFieldSubject links Course to Subject on the fields Course.Subject and Subject.Courses.
+// the Course is removed from Courses of the Subject it was linked to before
 func (l *Course) LinkToSubjectOnFieldSubject(target *Subject) error {
+	return l.LinkToSubjectOnFieldSubjectWith(nil, target)
+}
+
+// LinkToSubjectOnFieldSubjectWith links Course to Subject on the fields Course.Subject and Subject.Courses using opts
+func (l *Course) LinkToSubjectOnFieldSubjectWith(opts *LinkOptions, target *Subject) error {
 	if target == nil {
-		return errors.New("start and end can not be nil")
+		return newLinkError("Course", "Subject", "SUBJECT_TAUGHT", ErrNilTarget)
+	}
+
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameSubject(l.Subject, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("Course", "Subject", "SUBJECT_TAUGHT", err)
+	}
+
+	if err := checkLink(l, "Subject", target, "Courses", nil); err != nil {
+		return newLinkError("Course", "Subject", "SUBJECT_TAUGHT", err)
+	}
+
+	if previous := l.Subject; previous != nil && !sameSubject(previous, target) {
+		if i := indexOfCourse(previous.Courses, l); i != -1 {
+			a := &previous.Courses
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
+
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Courses"})
 	}
 
 	l.Subject = target
@@ -23,521 +51,1844 @@ func (l *Course) LinkToSubjectOnFieldSubject(target *Subject) error {
 	return nil
 }
 
-//UnlinkFromSubjectOnFieldSubject unlinks Course from Subject on the fields Course.Subject and Subject.Courses
+// UnlinkFromSubjectOnFieldSubject unlinks Course from Subject on the fields Course.Subject and Subject.Courses
 func (l *Course) UnlinkFromSubjectOnFieldSubject(target *Subject) error {
 	if target == nil {
-		return errors.New("start and end can not be nil")
+		return newLinkError("Course", "Subject", "SUBJECT_TAUGHT", ErrNilTarget)
+	}
+
+	if !sameSubject(l.Subject, target) {
+		return newLinkError("Course", "Subject", "SUBJECT_TAUGHT", ErrNotLinked)
 	}
 
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Subject
 	l.Subject = nil
 
-	if target.Courses != nil {
-		for i, unlinkTarget := range target.Courses {
-			if unlinkTarget.UUID == l.UUID {
-				a := &target.Courses
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
-			}
-		}
+	if i := indexOfCourse(target.Courses, l); i != -1 {
+		a := &target.Courses
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
 	}
 
 	return nil
 }
 
-//LinkToTeacherOnFieldTeacher links Course to Teacher on the fields Course.Teacher and Teacher.Courses
-func (l *Course) LinkToTeacherOnFieldTeacher(target *Teacher) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
+// LinkToCourseOnFieldPrerequisites links Course to Course on the fields Course.Prerequisites and Course.RequiredBy
+func (l *Course) LinkToCourseOnFieldPrerequisites(targets ...*Course) error {
+	return l.LinkToCourseOnFieldPrerequisitesWith(nil, targets...)
+}
+
+// LinkToCourseOnFieldPrerequisitesWith links Course to Course on the fields Course.Prerequisites and Course.RequiredBy using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Course) LinkToCourseOnFieldPrerequisitesWith(opts *LinkOptions, targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "Prerequisites", "REQUIRES", ErrNoTargets)
 	}
 
-	l.Teacher = target
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, ErrNilTarget)
+		}
 
-	if target.Courses == nil {
-		target.Courses = make([]*Course, 1, 1)
-		target.Courses[0] = l
-	} else {
-		target.Courses = append(target.Courses, l)
-	}
+		if indexOfCourse(l.Prerequisites, target) != -1 || indexOfCourse(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, err)
+			}
+		}
 
-	return nil
-}
+		if err := checkLink(l, "Prerequisites", target, "RequiredBy", nil); err != nil {
+			return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, err)
+		}
 
-//UnlinkFromTeacherOnFieldTeacher unlinks Course from Teacher on the fields Course.Teacher and Teacher.Courses
-func (l *Course) UnlinkFromTeacherOnFieldTeacher(target *Teacher) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
+		if err := checkPendingLink(l, "Prerequisites", target, pending); err != nil {
+			return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, err)
+		}
+		pending = append(pending, target)
 	}
 
-	l.Teacher = nil
-
-	if target.Courses != nil {
-		for i, unlinkTarget := range target.Courses {
-			if unlinkTarget.UUID == l.UUID {
-				a := &target.Courses
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
+	for _, target := range targets {
+		if indexOfCourse(l.Prerequisites, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
 			}
 		}
+
+		if l.Prerequisites == nil {
+			l.Prerequisites = make([]*Course, 1, 1)
+			l.Prerequisites[0] = target
+		} else {
+			l.Prerequisites = append(l.Prerequisites, target)
+		}
+
+		if target.RequiredBy == nil {
+			target.RequiredBy = make([]*Course, 1, 1)
+			target.RequiredBy[0] = l
+		} else {
+			target.RequiredBy = append(target.RequiredBy, l)
+		}
 	}
 
 	return nil
 }
 
-// LinkToStudentOnFieldEnrollments links Course to Student on the fields Course.Enrollments and Student.Enrollments.
-// note this uses the special edge Enrollment
-func (l *Course) LinkToStudentOnFieldEnrollments(target *Student, edge *Enrollment) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromCourseOnFieldPrerequisites unlinks Course from Course on the fields Course.Prerequisites and Course.RequiredBy.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Course) UnlinkFromCourseOnFieldPrerequisites(targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "Prerequisites", "REQUIRES", ErrNoTargets)
 	}
 
-	if edge == nil {
-		return errors.New("edge can not be nil")
-	}
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, ErrNilTarget)
+		}
 
-	err := edge.SetStartNode(target)
-	if err != nil {
-		return err
+		if indexOfCourse(l.Prerequisites, target) == -1 || indexOfCourse(targets[:i], target) != -1 {
+			return newLinkTargetError("Course", "Prerequisites", "REQUIRES", i, ErrNotLinked)
+		}
 	}
 
-	err = edge.SetEndNode(l)
-	if err != nil {
-		return err
-	}
+	for _, target := range targets {
+		i := indexOfCourse(l.Prerequisites, target)
 
-	if l.Enrollments == nil {
-		l.Enrollments = make([]*Enrollment, 1, 1)
-		l.Enrollments[0] = edge
-	} else {
-		l.Enrollments = append(l.Enrollments, edge)
-	}
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Prerequisites[i]
 
-	if target.Enrollments == nil {
-		target.Enrollments = make([]*Enrollment, 1, 1)
-		target.Enrollments[0] = edge
-	} else {
-		target.Enrollments = append(target.Enrollments, edge)
+		a := &l.Prerequisites
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if j := indexOfCourse(target.RequiredBy, l); j != -1 {
+			a := &target.RequiredBy
+			(*a)[j] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
 	}
 
 	return nil
 }
 
-// UnlinkFromStudentOnFieldEnrollments unlinks Course from Student on the fields Course.Enrollments and Student.Enrollments.
-// also note this uses the special edge Enrollment
-func (l *Course) UnlinkFromStudentOnFieldEnrollments(target *Student) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
-	}
+// LinkToCourseOnFieldRequiredBy links Course to Course on the fields Course.RequiredBy and Course.Prerequisites
+func (l *Course) LinkToCourseOnFieldRequiredBy(targets ...*Course) error {
+	return l.LinkToCourseOnFieldRequiredByWith(nil, targets...)
+}
 
-	if l.Enrollments != nil {
-		for i, unlinkTarget := range l.Enrollments {
+// LinkToCourseOnFieldRequiredByWith links Course to Course on the fields Course.RequiredBy and Course.Prerequisites using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Course) LinkToCourseOnFieldRequiredByWith(opts *LinkOptions, targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "RequiredBy", "REQUIRES", ErrNoTargets)
+	}
 
-			obj := unlinkTarget.GetStartNode()
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, ErrNilTarget)
+		}
 
-			checkObj, ok := obj.(*Student)
-			if !ok {
-				return errors.New("unable to cast unlinkTarget to [*Student]")
-			}
-			if checkObj.UUID == target.UUID {
-				a := &l.Enrollments
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
+		if indexOfCourse(l.RequiredBy, target) != -1 || indexOfCourse(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, err)
 			}
 		}
-	}
 
-	if target.Enrollments != nil {
-		for i, unlinkTarget := range target.Enrollments {
+		if err := checkLink(l, "RequiredBy", target, "Prerequisites", nil); err != nil {
+			return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, err)
+		}
 
-			obj := unlinkTarget.GetEndNode()
+		if err := checkPendingLink(l, "RequiredBy", target, pending); err != nil {
+			return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, err)
+		}
+		pending = append(pending, target)
+	}
 
-			checkObj, ok := obj.(*Course)
-			if !ok {
-				return errors.New("unable to cast unlinkTarget to [*Course]")
-			}
-			if checkObj.UUID == l.UUID {
-				a := &target.Enrollments
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
+	for _, target := range targets {
+		if indexOfCourse(l.RequiredBy, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
 			}
 		}
+
+		if l.RequiredBy == nil {
+			l.RequiredBy = make([]*Course, 1, 1)
+			l.RequiredBy[0] = target
+		} else {
+			l.RequiredBy = append(l.RequiredBy, target)
+		}
+
+		if target.Prerequisites == nil {
+			target.Prerequisites = make([]*Course, 1, 1)
+			target.Prerequisites[0] = l
+		} else {
+			target.Prerequisites = append(target.Prerequisites, l)
+		}
 	}
 
 	return nil
 }
 
-// LinkToSubjectOnFieldSubjects links Department to Subject on the fields Department.Subjects and Subject.Department
-func (l *Department) LinkToSubjectOnFieldSubjects(targets ...*Subject) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromCourseOnFieldRequiredBy unlinks Course from Course on the fields Course.RequiredBy and Course.Prerequisites.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Course) UnlinkFromCourseOnFieldRequiredBy(targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "RequiredBy", "REQUIRES", ErrNoTargets)
 	}
 
-	for _, target := range targets {
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, ErrNilTarget)
+		}
 
-		if l.Subjects == nil {
-			l.Subjects = make([]*Subject, 1, 1)
-			l.Subjects[0] = target
-		} else {
-			l.Subjects = append(l.Subjects, target)
+		if indexOfCourse(l.RequiredBy, target) == -1 || indexOfCourse(targets[:i], target) != -1 {
+			return newLinkTargetError("Course", "RequiredBy", "REQUIRES", i, ErrNotLinked)
 		}
+	}
 
-		target.Department = l
+	for _, target := range targets {
+		i := indexOfCourse(l.RequiredBy, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.RequiredBy[i]
+
+		a := &l.RequiredBy
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if j := indexOfCourse(target.Prerequisites, l); j != -1 {
+			a := &target.Prerequisites
+			(*a)[j] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
 	}
 
 	return nil
 }
 
-//UnlinkFromSubjectOnFieldSubjects unlinks Department from Subject on the fields Department.Subjects and Subject.Department
-func (l *Department) UnlinkFromSubjectOnFieldSubjects(targets ...*Subject) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// LinkToCourseOfferingOnFieldOfferings links Course to CourseOffering on the fields Course.Offerings and CourseOffering.Course.
+// targets are removed from Offerings of the Course they were linked to before
+func (l *Course) LinkToCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	return l.LinkToCourseOfferingOnFieldOfferingsWith(nil, targets...)
+}
+
+// LinkToCourseOfferingOnFieldOfferingsWith links Course to CourseOffering on the fields Course.Offerings and CourseOffering.Course using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Course) LinkToCourseOfferingOnFieldOfferingsWith(opts *LinkOptions, targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "Offerings", "OFFERING_OF", ErrNoTargets)
 	}
 
-	for _, target := range targets {
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, ErrNilTarget)
+		}
 
-		if l.Subjects != nil {
-			for i, unlinkTarget := range l.Subjects {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Subjects
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
+		if indexOfCourseOffering(l.Offerings, target) != -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, err)
 			}
 		}
 
-		target.Department = nil
-	}
-
-	return nil
-}
+		if err := checkLink(l, "Offerings", target, "Course", nil); err != nil {
+			return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, err)
+		}
 
-// LinkToTeacherOnFieldTeachers links Department to Teacher on the fields Department.Teachers and Teacher.Department
-func (l *Department) LinkToTeacherOnFieldTeachers(targets ...*Teacher) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+		if err := checkPendingLink(l, "Offerings", target, pending); err != nil {
+			return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, err)
+		}
+		pending = append(pending, target)
 	}
 
 	for _, target := range targets {
+		if indexOfCourseOffering(l.Offerings, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
 
-		if l.Teachers == nil {
-			l.Teachers = make([]*Teacher, 1, 1)
-			l.Teachers[0] = target
+		if previous := target.Course; previous != nil && !sameCourse(previous, l) {
+			if i := indexOfCourseOffering(previous.Offerings, target); i != -1 {
+				a := &previous.Offerings
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Offerings"})
+		}
+
+		if l.Offerings == nil {
+			l.Offerings = make([]*CourseOffering, 1, 1)
+			l.Offerings[0] = target
 		} else {
-			l.Teachers = append(l.Teachers, target)
+			l.Offerings = append(l.Offerings, target)
 		}
 
-		target.Department = l
+		target.Course = l
 	}
 
 	return nil
 }
 
-//UnlinkFromTeacherOnFieldTeachers unlinks Department from Teacher on the fields Department.Teachers and Teacher.Department
-func (l *Department) UnlinkFromTeacherOnFieldTeachers(targets ...*Teacher) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromCourseOfferingOnFieldOfferings unlinks Course from CourseOffering on the fields Course.Offerings and CourseOffering.Course.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Course) UnlinkFromCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Course", "Offerings", "OFFERING_OF", ErrNoTargets)
 	}
 
-	for _, target := range targets {
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, ErrNilTarget)
+		}
 
-		if l.Teachers != nil {
-			for i, unlinkTarget := range l.Teachers {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Teachers
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
+		if indexOfCourseOffering(l.Offerings, target) == -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			return newLinkTargetError("Course", "Offerings", "OFFERING_OF", i, ErrNotLinked)
 		}
+	}
+
+	for _, target := range targets {
+		i := indexOfCourseOffering(l.Offerings, target)
 
-		target.Department = nil
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Offerings[i]
+
+		a := &l.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameCourse(target.Course, l) {
+			target.Course = nil
+		}
 	}
 
 	return nil
 }
 
-// LinkToCourseOnFieldEnrollments links Student to Course on the fields Student.Enrollments and Course.Enrollments.
-// note this uses the special edge Enrollment
-func (l *Student) LinkToCourseOnFieldEnrollments(target *Course, edge *Enrollment) error {
+// LinkToCourseOnFieldCourse links CourseOffering to Course on the fields CourseOffering.Course and Course.Offerings.
+// the CourseOffering is removed from Offerings of the Course it was linked to before
+func (l *CourseOffering) LinkToCourseOnFieldCourse(target *Course) error {
+	return l.LinkToCourseOnFieldCourseWith(nil, target)
+}
+
+// LinkToCourseOnFieldCourseWith links CourseOffering to Course on the fields CourseOffering.Course and Course.Offerings using opts
+func (l *CourseOffering) LinkToCourseOnFieldCourseWith(opts *LinkOptions, target *Course) error {
 	if target == nil {
-		return errors.New("start and end can not be nil")
+		return newLinkError("CourseOffering", "Course", "OFFERING_OF", ErrNilTarget)
 	}
 
-	if edge == nil {
-		return errors.New("edge can not be nil")
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameCourse(l.Course, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("CourseOffering", "Course", "OFFERING_OF", err)
 	}
 
-	err := edge.SetStartNode(l)
-	if err != nil {
-		return err
+	if err := checkLink(l, "Course", target, "Offerings", nil); err != nil {
+		return newLinkError("CourseOffering", "Course", "OFFERING_OF", err)
 	}
 
-	err = edge.SetEndNode(target)
-	if err != nil {
-		return err
-	}
+	if previous := l.Course; previous != nil && !sameCourse(previous, target) {
+		if i := indexOfCourseOffering(previous.Offerings, l); i != -1 {
+			a := &previous.Offerings
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
 
-	if l.Enrollments == nil {
-		l.Enrollments = make([]*Enrollment, 1, 1)
-		l.Enrollments[0] = edge
-	} else {
-		l.Enrollments = append(l.Enrollments, edge)
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Offerings"})
 	}
 
-	if target.Enrollments == nil {
-		target.Enrollments = make([]*Enrollment, 1, 1)
-		target.Enrollments[0] = edge
+	l.Course = target
+
+	if target.Offerings == nil {
+		target.Offerings = make([]*CourseOffering, 1, 1)
+		target.Offerings[0] = l
 	} else {
-		target.Enrollments = append(target.Enrollments, edge)
+		target.Offerings = append(target.Offerings, l)
 	}
 
 	return nil
 }
 
-// UnlinkFromCourseOnFieldEnrollments unlinks Student from Course on the fields Student.Enrollments and Course.Enrollments.
-// also note this uses the special edge Enrollment
-func (l *Student) UnlinkFromCourseOnFieldEnrollments(target *Course) error {
+// UnlinkFromCourseOnFieldCourse unlinks CourseOffering from Course on the fields CourseOffering.Course and Course.Offerings
+func (l *CourseOffering) UnlinkFromCourseOnFieldCourse(target *Course) error {
 	if target == nil {
-		return errors.New("start and end can not be nil")
+		return newLinkError("CourseOffering", "Course", "OFFERING_OF", ErrNilTarget)
 	}
 
-	if l.Enrollments != nil {
-		for i, unlinkTarget := range l.Enrollments {
-
-			obj := unlinkTarget.GetEndNode()
-
-			checkObj, ok := obj.(*Course)
-			if !ok {
-				return errors.New("unable to cast unlinkTarget to [*Course]")
-			}
-			if checkObj.UUID == target.UUID {
-				a := &l.Enrollments
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
-			}
-		}
+	if !sameCourse(l.Course, target) {
+		return newLinkError("CourseOffering", "Course", "OFFERING_OF", ErrNotLinked)
 	}
 
-	if target.Enrollments != nil {
-		for i, unlinkTarget := range target.Enrollments {
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Course
+	l.Course = nil
 
-			obj := unlinkTarget.GetStartNode()
-
-			checkObj, ok := obj.(*Student)
-			if !ok {
-				return errors.New("unable to cast unlinkTarget to [*Student]")
-			}
-			if checkObj.UUID == l.UUID {
-				a := &target.Enrollments
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
-			}
-		}
+	if i := indexOfCourseOffering(target.Offerings, l); i != -1 {
+		a := &target.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
 	}
 
 	return nil
 }
 
-//LinkToDepartmentOnFieldDepartment links Subject to Department on the fields Subject.Department and Department.Subjects
-func (l *Subject) LinkToDepartmentOnFieldDepartment(target *Department) error {
+// LinkToTermOnFieldTerm links CourseOffering to Term on the fields CourseOffering.Term and Term.Offerings.
+// the CourseOffering is removed from Offerings of the Term it was linked to before
+func (l *CourseOffering) LinkToTermOnFieldTerm(target *Term) error {
+	return l.LinkToTermOnFieldTermWith(nil, target)
+}
+
+// LinkToTermOnFieldTermWith links CourseOffering to Term on the fields CourseOffering.Term and Term.Offerings using opts
+func (l *CourseOffering) LinkToTermOnFieldTermWith(opts *LinkOptions, target *Term) error {
 	if target == nil {
-		return errors.New("start and end can not be nil")
+		return newLinkError("CourseOffering", "Term", "OFFERED_IN", ErrNilTarget)
 	}
 
-	l.Department = target
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameTerm(l.Term, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("CourseOffering", "Term", "OFFERED_IN", err)
+	}
 
-	if target.Subjects == nil {
-		target.Subjects = make([]*Subject, 1, 1)
-		target.Subjects[0] = l
-	} else {
-		target.Subjects = append(target.Subjects, l)
+	if err := checkLink(l, "Term", target, "Offerings", nil); err != nil {
+		return newLinkError("CourseOffering", "Term", "OFFERED_IN", err)
 	}
 
-	return nil
-}
+	if previous := l.Term; previous != nil && !sameTerm(previous, target) {
+		if i := indexOfCourseOffering(previous.Offerings, l); i != -1 {
+			a := &previous.Offerings
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
 
-//UnlinkFromDepartmentOnFieldDepartment unlinks Subject from Department on the fields Subject.Department and Department.Subjects
-func (l *Subject) UnlinkFromDepartmentOnFieldDepartment(target *Department) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Offerings"})
 	}
 
-	l.Department = nil
+	l.Term = target
 
-	if target.Subjects != nil {
-		for i, unlinkTarget := range target.Subjects {
-			if unlinkTarget.UUID == l.UUID {
-				a := &target.Subjects
-				(*a)[i] = (*a)[len(*a)-1]
-				(*a)[len(*a)-1] = nil
-				*a = (*a)[:len(*a)-1]
-				break
-			}
-		}
+	if target.Offerings == nil {
+		target.Offerings = make([]*CourseOffering, 1, 1)
+		target.Offerings[0] = l
+	} else {
+		target.Offerings = append(target.Offerings, l)
 	}
 
 	return nil
 }
 
-// LinkToTeacherOnFieldTeachers links Subject to Teacher on the fields Subject.Teachers and Teacher.Subjects
-func (l *Subject) LinkToTeacherOnFieldTeachers(targets ...*Teacher) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromTermOnFieldTerm unlinks CourseOffering from Term on the fields CourseOffering.Term and Term.Offerings
+func (l *CourseOffering) UnlinkFromTermOnFieldTerm(target *Term) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Term", "OFFERED_IN", ErrNilTarget)
 	}
 
-	for _, target := range targets {
+	if !sameTerm(l.Term, target) {
+		return newLinkError("CourseOffering", "Term", "OFFERED_IN", ErrNotLinked)
+	}
 
-		if l.Teachers == nil {
-			l.Teachers = make([]*Teacher, 1, 1)
-			l.Teachers[0] = target
-		} else {
-			l.Teachers = append(l.Teachers, target)
-		}
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Term
+	l.Term = nil
 
-		if target.Subjects == nil {
-			target.Subjects = make([]*Subject, 1, 1)
-			target.Subjects[0] = l
-		} else {
-			target.Subjects = append(target.Subjects, l)
-		}
+	if i := indexOfCourseOffering(target.Offerings, l); i != -1 {
+		a := &target.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
 	}
 
 	return nil
 }
 
-//UnlinkFromTeacherOnFieldTeachers unlinks Subject from Teacher on the fields Subject.Teachers and Teacher.Subjects
-func (l *Subject) UnlinkFromTeacherOnFieldTeachers(targets ...*Teacher) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
-	}
+// LinkToTeacherOnFieldTeacher links CourseOffering to Teacher on the fields CourseOffering.Teacher and Teacher.Offerings.
+// the CourseOffering is removed from Offerings of the Teacher it was linked to before
+func (l *CourseOffering) LinkToTeacherOnFieldTeacher(target *Teacher) error {
+	return l.LinkToTeacherOnFieldTeacherWith(nil, target)
+}
 
-	for _, target := range targets {
+// LinkToTeacherOnFieldTeacherWith links CourseOffering to Teacher on the fields CourseOffering.Teacher and Teacher.Offerings using opts
+func (l *CourseOffering) LinkToTeacherOnFieldTeacherWith(opts *LinkOptions, target *Teacher) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Teacher", "TEACHES_CLASS", ErrNilTarget)
+	}
 
-		if l.Teachers != nil {
-			for i, unlinkTarget := range l.Teachers {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Teachers
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
-		}
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameTeacher(l.Teacher, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("CourseOffering", "Teacher", "TEACHES_CLASS", err)
+	}
 
-		if target.Subjects != nil {
-			for i, unlinkTarget := range target.Subjects {
-				if unlinkTarget.UUID == l.UUID {
-					a := &target.Subjects
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
-		}
+	if err := checkLink(l, "Teacher", target, "Offerings", nil); err != nil {
+		return newLinkError("CourseOffering", "Teacher", "TEACHES_CLASS", err)
 	}
 
-	return nil
-}
+	if previous := l.Teacher; previous != nil && !sameTeacher(previous, target) {
+		if i := indexOfCourseOffering(previous.Offerings, l); i != -1 {
+			a := &previous.Offerings
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
 
-// LinkToCourseOnFieldCourses links Subject to Course on the fields Subject.Courses and Course.Subject
-func (l *Subject) LinkToCourseOnFieldCourses(targets ...*Course) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Offerings"})
 	}
 
-	for _, target := range targets {
-
-		if l.Courses == nil {
-			l.Courses = make([]*Course, 1, 1)
-			l.Courses[0] = target
-		} else {
-			l.Courses = append(l.Courses, target)
-		}
+	l.Teacher = target
 
-		target.Subject = l
+	if target.Offerings == nil {
+		target.Offerings = make([]*CourseOffering, 1, 1)
+		target.Offerings[0] = l
+	} else {
+		target.Offerings = append(target.Offerings, l)
 	}
 
 	return nil
 }
 
-//UnlinkFromCourseOnFieldCourses unlinks Subject from Course on the fields Subject.Courses and Course.Subject
-func (l *Subject) UnlinkFromCourseOnFieldCourses(targets ...*Course) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromTeacherOnFieldTeacher unlinks CourseOffering from Teacher on the fields CourseOffering.Teacher and Teacher.Offerings
+func (l *CourseOffering) UnlinkFromTeacherOnFieldTeacher(target *Teacher) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Teacher", "TEACHES_CLASS", ErrNilTarget)
 	}
 
-	for _, target := range targets {
+	if !sameTeacher(l.Teacher, target) {
+		return newLinkError("CourseOffering", "Teacher", "TEACHES_CLASS", ErrNotLinked)
+	}
 
-		if l.Courses != nil {
-			for i, unlinkTarget := range l.Courses {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Courses
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
-		}
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Teacher
+	l.Teacher = nil
 
-		target.Subject = nil
+	if i := indexOfCourseOffering(target.Offerings, l); i != -1 {
+		a := &target.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
 	}
 
 	return nil
 }
 
-//LinkToDepartmentOnFieldDepartment links Teacher to Department on the fields Teacher.Department and Department.Teachers
-func (l *Teacher) LinkToDepartmentOnFieldDepartment(target *Department) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
-	}
+// LinkToStudentOnFieldEnrollments links CourseOffering to Student on the fields CourseOffering.Enrollments and Student.Enrollments.
+// note this uses the special edge Enrollment
+func (l *CourseOffering) LinkToStudentOnFieldEnrollments(target *Student, edge *Enrollment) error {
+	return l.LinkToStudentOnFieldEnrollmentsWith(nil, target, edge)
+}
+
+// LinkToStudentOnFieldEnrollmentsWith links CourseOffering to Student on the fields CourseOffering.Enrollments and Student.Enrollments using opts.
+// the nodes count as linked if edge is already on CourseOffering.Enrollments or CourseOffering has any Enrollment to target
+func (l *CourseOffering) LinkToStudentOnFieldEnrollmentsWith(opts *LinkOptions, target *Student, edge *Enrollment) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	if edge == nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNilEdge)
+	}
+
+	for _, existing := range l.Enrollments {
+		if existing == edge {
+			_, err := opts.onDuplicate()
+			return newLinkError("CourseOffering", "Enrollments", "ENROLLED", err)
+		}
+
+		obj := existing.GetStartNode()
+		checkObj, ok := obj.(*Student)
+		if !ok {
+			return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*Student] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameStudent(checkObj, target) {
+			if skip, err := opts.onDuplicate(); skip {
+				return newLinkError("CourseOffering", "Enrollments", "ENROLLED", err)
+			}
+			break
+		}
+	}
+
+	err := checkLink(l, "Enrollments", target, "Enrollments", edge)
+	if err != nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", err)
+	}
+
+	err = edge.SetStartNode(target)
+	if err != nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	err = edge.SetEndNode(l)
+	if err != nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	if l.Enrollments == nil {
+		l.Enrollments = make([]*Enrollment, 1, 1)
+		l.Enrollments[0] = edge
+	} else {
+		l.Enrollments = append(l.Enrollments, edge)
+	}
+
+	if target.Enrollments == nil {
+		target.Enrollments = make([]*Enrollment, 1, 1)
+		target.Enrollments[0] = edge
+	} else {
+		target.Enrollments = append(target.Enrollments, edge)
+	}
+
+	return nil
+}
+
+// UnlinkFromStudentOnFieldEnrollments unlinks CourseOffering from Student on the fields CourseOffering.Enrollments and Student.Enrollments.
+// also note this uses the special edge Enrollment, only the first edge to target is removed
+func (l *CourseOffering) UnlinkFromStudentOnFieldEnrollments(target *Student) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	// match the node by pointer first so unsaved nodes without a uuid are told apart
+	i := -1
+	for j, unlinkTarget := range l.Enrollments {
+		obj := unlinkTarget.GetStartNode()
+		checkObj, ok := obj.(*Student)
+		if !ok {
+			return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*Student] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if checkObj == target {
+			i = j
+			break
+		}
+
+		if i == -1 && sameStudent(checkObj, target) {
+			i = j
+		}
+	}
+
+	if i == -1 {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	return l.UnlinkFromStudentOnFieldEnrollmentsByEdge(l.Enrollments[i])
+}
+
+// UnlinkFromStudentOnFieldEnrollmentsByEdge removes edge from CourseOffering.Enrollments and from Student.Enrollments of the Student it connects to.
+// use this to remove one specific edge when there are several between the same nodes
+func (l *CourseOffering) UnlinkFromStudentOnFieldEnrollmentsByEdge(edge *Enrollment) error {
+	if edge == nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNilEdge)
+	}
+
+	i := indexOfEnrollment(l.Enrollments, edge)
+	if i == -1 {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	// edge may only match by uuid, work with the one that is actually linked
+	edge = l.Enrollments[i]
+
+	obj := edge.GetStartNode()
+	target, ok := obj.(*Student)
+	if !ok {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*Student] found [%T]", ErrEdgeTypeMismatch, obj))
+	}
+
+	a := &l.Enrollments
+	(*a)[i] = (*a)[len(*a)-1]
+	(*a)[len(*a)-1] = nil
+	*a = (*a)[:len(*a)-1]
+
+	if target == nil {
+		return nil
+	}
+
+	if j := indexOfEnrollment(target.Enrollments, edge); j != -1 {
+		a := &target.Enrollments
+		(*a)[j] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// UnlinkFromStudentOnFieldEnrollmentsWhere removes every Enrollment between CourseOffering and target that match accepts.
+// a nil match accepts every edge
+func (l *CourseOffering) UnlinkFromStudentOnFieldEnrollmentsWhere(target *Student, match func(edge *Enrollment) bool) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	var edges []*Enrollment
+	for _, unlinkTarget := range l.Enrollments {
+		obj := unlinkTarget.GetStartNode()
+		checkObj, ok := obj.(*Student)
+		if !ok {
+			return newLinkError("CourseOffering", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*Student] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameStudent(checkObj, target) && (match == nil || match(unlinkTarget)) {
+			edges = append(edges, unlinkTarget)
+		}
+	}
+
+	if len(edges) == 0 {
+		return newLinkError("CourseOffering", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	for _, edge := range edges {
+		err := l.UnlinkFromStudentOnFieldEnrollmentsByEdge(edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAllFromStudentOnFieldEnrollments removes every Enrollment between CourseOffering and target
+func (l *CourseOffering) UnlinkAllFromStudentOnFieldEnrollments(target *Student) error {
+	return l.UnlinkFromStudentOnFieldEnrollmentsWhere(target, nil)
+}
+
+// LinkToRoomOnFieldMeetings links CourseOffering to Room on the fields CourseOffering.Meetings and Room.Meetings.
+// note this uses the special edge Meeting
+func (l *CourseOffering) LinkToRoomOnFieldMeetings(target *Room, edge *Meeting) error {
+	return l.LinkToRoomOnFieldMeetingsWith(nil, target, edge)
+}
+
+// LinkToRoomOnFieldMeetingsWith links CourseOffering to Room on the fields CourseOffering.Meetings and Room.Meetings using opts.
+// the nodes count as linked if edge is already on CourseOffering.Meetings or CourseOffering has any Meeting to target
+func (l *CourseOffering) LinkToRoomOnFieldMeetingsWith(opts *LinkOptions, target *Room, edge *Meeting) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	if edge == nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNilEdge)
+	}
+
+	for _, existing := range l.Meetings {
+		if existing == edge {
+			_, err := opts.onDuplicate()
+			return newLinkError("CourseOffering", "Meetings", "MEETS_IN", err)
+		}
+
+		obj := existing.GetEndNode()
+		checkObj, ok := obj.(*Room)
+		if !ok {
+			return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*Room] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameRoom(checkObj, target) {
+			if skip, err := opts.onDuplicate(); skip {
+				return newLinkError("CourseOffering", "Meetings", "MEETS_IN", err)
+			}
+			break
+		}
+	}
+
+	err := checkLink(l, "Meetings", target, "Meetings", edge)
+	if err != nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", err)
+	}
+
+	err = edge.SetStartNode(l)
+	if err != nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	err = edge.SetEndNode(target)
+	if err != nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	if l.Meetings == nil {
+		l.Meetings = make([]*Meeting, 1, 1)
+		l.Meetings[0] = edge
+	} else {
+		l.Meetings = append(l.Meetings, edge)
+	}
+
+	if target.Meetings == nil {
+		target.Meetings = make([]*Meeting, 1, 1)
+		target.Meetings[0] = edge
+	} else {
+		target.Meetings = append(target.Meetings, edge)
+	}
+
+	return nil
+}
+
+// UnlinkFromRoomOnFieldMeetings unlinks CourseOffering from Room on the fields CourseOffering.Meetings and Room.Meetings.
+// also note this uses the special edge Meeting, only the first edge to target is removed
+func (l *CourseOffering) UnlinkFromRoomOnFieldMeetings(target *Room) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	// match the node by pointer first so unsaved nodes without a uuid are told apart
+	i := -1
+	for j, unlinkTarget := range l.Meetings {
+		obj := unlinkTarget.GetEndNode()
+		checkObj, ok := obj.(*Room)
+		if !ok {
+			return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*Room] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if checkObj == target {
+			i = j
+			break
+		}
+
+		if i == -1 && sameRoom(checkObj, target) {
+			i = j
+		}
+	}
+
+	if i == -1 {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	return l.UnlinkFromRoomOnFieldMeetingsByEdge(l.Meetings[i])
+}
+
+// UnlinkFromRoomOnFieldMeetingsByEdge removes edge from CourseOffering.Meetings and from Room.Meetings of the Room it connects to.
+// use this to remove one specific edge when there are several between the same nodes
+func (l *CourseOffering) UnlinkFromRoomOnFieldMeetingsByEdge(edge *Meeting) error {
+	if edge == nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNilEdge)
+	}
+
+	i := indexOfMeeting(l.Meetings, edge)
+	if i == -1 {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	// edge may only match by uuid, work with the one that is actually linked
+	edge = l.Meetings[i]
+
+	obj := edge.GetEndNode()
+	target, ok := obj.(*Room)
+	if !ok {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*Room] found [%T]", ErrEdgeTypeMismatch, obj))
+	}
+
+	a := &l.Meetings
+	(*a)[i] = (*a)[len(*a)-1]
+	(*a)[len(*a)-1] = nil
+	*a = (*a)[:len(*a)-1]
+
+	if target == nil {
+		return nil
+	}
+
+	if j := indexOfMeeting(target.Meetings, edge); j != -1 {
+		a := &target.Meetings
+		(*a)[j] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// UnlinkFromRoomOnFieldMeetingsWhere removes every Meeting between CourseOffering and target that match accepts.
+// a nil match accepts every edge
+func (l *CourseOffering) UnlinkFromRoomOnFieldMeetingsWhere(target *Room, match func(edge *Meeting) bool) error {
+	if target == nil {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	var edges []*Meeting
+	for _, unlinkTarget := range l.Meetings {
+		obj := unlinkTarget.GetEndNode()
+		checkObj, ok := obj.(*Room)
+		if !ok {
+			return newLinkError("CourseOffering", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*Room] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameRoom(checkObj, target) && (match == nil || match(unlinkTarget)) {
+			edges = append(edges, unlinkTarget)
+		}
+	}
+
+	if len(edges) == 0 {
+		return newLinkError("CourseOffering", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	for _, edge := range edges {
+		err := l.UnlinkFromRoomOnFieldMeetingsByEdge(edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAllFromRoomOnFieldMeetings removes every Meeting between CourseOffering and target
+func (l *CourseOffering) UnlinkAllFromRoomOnFieldMeetings(target *Room) error {
+	return l.UnlinkFromRoomOnFieldMeetingsWhere(target, nil)
+}
+
+// LinkToSubjectOnFieldSubjects links Department to Subject on the fields Department.Subjects and Subject.Department.
+// targets are removed from Subjects of the Department they were linked to before
+func (l *Department) LinkToSubjectOnFieldSubjects(targets ...*Subject) error {
+	return l.LinkToSubjectOnFieldSubjectsWith(nil, targets...)
+}
+
+// LinkToSubjectOnFieldSubjectsWith links Department to Subject on the fields Department.Subjects and Subject.Department using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Department) LinkToSubjectOnFieldSubjectsWith(opts *LinkOptions, targets ...*Subject) error {
+	if len(targets) == 0 {
+		return newLinkError("Department", "Subjects", "CURRICULUM", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, ErrNilTarget)
+		}
+
+		if indexOfSubject(l.Subjects, target) != -1 || indexOfSubject(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Subjects", target, "Department", nil); err != nil {
+			return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, err)
+		}
+
+		if err := checkPendingLink(l, "Subjects", target, pending); err != nil {
+			return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOfSubject(l.Subjects, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
+
+		if previous := target.Department; previous != nil && !sameDepartment(previous, l) {
+			if i := indexOfSubject(previous.Subjects, target); i != -1 {
+				a := &previous.Subjects
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Subjects"})
+		}
+
+		if l.Subjects == nil {
+			l.Subjects = make([]*Subject, 1, 1)
+			l.Subjects[0] = target
+		} else {
+			l.Subjects = append(l.Subjects, target)
+		}
+
+		target.Department = l
+	}
+
+	return nil
+}
+
+// UnlinkFromSubjectOnFieldSubjects unlinks Department from Subject on the fields Department.Subjects and Subject.Department.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Department) UnlinkFromSubjectOnFieldSubjects(targets ...*Subject) error {
+	if len(targets) == 0 {
+		return newLinkError("Department", "Subjects", "CURRICULUM", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, ErrNilTarget)
+		}
+
+		if indexOfSubject(l.Subjects, target) == -1 || indexOfSubject(targets[:i], target) != -1 {
+			return newLinkTargetError("Department", "Subjects", "CURRICULUM", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOfSubject(l.Subjects, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Subjects[i]
+
+		a := &l.Subjects
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameDepartment(target.Department, l) {
+			target.Department = nil
+		}
+	}
+
+	return nil
+}
+
+// LinkToTeacherOnFieldTeachers links Department to Teacher on the fields Department.Teachers and Teacher.Department.
+// targets are removed from Teachers of the Department they were linked to before
+func (l *Department) LinkToTeacherOnFieldTeachers(targets ...*Teacher) error {
+	return l.LinkToTeacherOnFieldTeachersWith(nil, targets...)
+}
+
+// LinkToTeacherOnFieldTeachersWith links Department to Teacher on the fields Department.Teachers and Teacher.Department using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Department) LinkToTeacherOnFieldTeachersWith(opts *LinkOptions, targets ...*Teacher) error {
+	if len(targets) == 0 {
+		return newLinkError("Department", "Teachers", "FOR_DEPARTMENT", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, ErrNilTarget)
+		}
+
+		if indexOfTeacher(l.Teachers, target) != -1 || indexOfTeacher(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Teachers", target, "Department", nil); err != nil {
+			return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, err)
+		}
+
+		if err := checkPendingLink(l, "Teachers", target, pending); err != nil {
+			return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOfTeacher(l.Teachers, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
+
+		if previous := target.Department; previous != nil && !sameDepartment(previous, l) {
+			if i := indexOfTeacher(previous.Teachers, target); i != -1 {
+				a := &previous.Teachers
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Teachers"})
+		}
+
+		if l.Teachers == nil {
+			l.Teachers = make([]*Teacher, 1, 1)
+			l.Teachers[0] = target
+		} else {
+			l.Teachers = append(l.Teachers, target)
+		}
+
+		target.Department = l
+	}
+
+	return nil
+}
+
+// UnlinkFromTeacherOnFieldTeachers unlinks Department from Teacher on the fields Department.Teachers and Teacher.Department.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Department) UnlinkFromTeacherOnFieldTeachers(targets ...*Teacher) error {
+	if len(targets) == 0 {
+		return newLinkError("Department", "Teachers", "FOR_DEPARTMENT", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, ErrNilTarget)
+		}
+
+		if indexOfTeacher(l.Teachers, target) == -1 || indexOfTeacher(targets[:i], target) != -1 {
+			return newLinkTargetError("Department", "Teachers", "FOR_DEPARTMENT", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOfTeacher(l.Teachers, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Teachers[i]
+
+		a := &l.Teachers
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameDepartment(target.Department, l) {
+			target.Department = nil
+		}
+	}
+
+	return nil
+}
+
+// LinkToCourseOfferingOnFieldMeetings links Room to CourseOffering on the fields Room.Meetings and CourseOffering.Meetings.
+// note this uses the special edge Meeting
+func (l *Room) LinkToCourseOfferingOnFieldMeetings(target *CourseOffering, edge *Meeting) error {
+	return l.LinkToCourseOfferingOnFieldMeetingsWith(nil, target, edge)
+}
+
+// LinkToCourseOfferingOnFieldMeetingsWith links Room to CourseOffering on the fields Room.Meetings and CourseOffering.Meetings using opts.
+// the nodes count as linked if edge is already on Room.Meetings or Room has any Meeting to target
+func (l *Room) LinkToCourseOfferingOnFieldMeetingsWith(opts *LinkOptions, target *CourseOffering, edge *Meeting) error {
+	if target == nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	if edge == nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNilEdge)
+	}
+
+	for _, existing := range l.Meetings {
+		if existing == edge {
+			_, err := opts.onDuplicate()
+			return newLinkError("Room", "Meetings", "MEETS_IN", err)
+		}
+
+		obj := existing.GetStartNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameCourseOffering(checkObj, target) {
+			if skip, err := opts.onDuplicate(); skip {
+				return newLinkError("Room", "Meetings", "MEETS_IN", err)
+			}
+			break
+		}
+	}
+
+	err := checkLink(l, "Meetings", target, "Meetings", edge)
+	if err != nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", err)
+	}
+
+	err = edge.SetStartNode(target)
+	if err != nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	err = edge.SetEndNode(l)
+	if err != nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	if l.Meetings == nil {
+		l.Meetings = make([]*Meeting, 1, 1)
+		l.Meetings[0] = edge
+	} else {
+		l.Meetings = append(l.Meetings, edge)
+	}
+
+	if target.Meetings == nil {
+		target.Meetings = make([]*Meeting, 1, 1)
+		target.Meetings[0] = edge
+	} else {
+		target.Meetings = append(target.Meetings, edge)
+	}
+
+	return nil
+}
+
+// UnlinkFromCourseOfferingOnFieldMeetings unlinks Room from CourseOffering on the fields Room.Meetings and CourseOffering.Meetings.
+// also note this uses the special edge Meeting, only the first edge to target is removed
+func (l *Room) UnlinkFromCourseOfferingOnFieldMeetings(target *CourseOffering) error {
+	if target == nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	// match the node by pointer first so unsaved nodes without a uuid are told apart
+	i := -1
+	for j, unlinkTarget := range l.Meetings {
+		obj := unlinkTarget.GetStartNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if checkObj == target {
+			i = j
+			break
+		}
+
+		if i == -1 && sameCourseOffering(checkObj, target) {
+			i = j
+		}
+	}
+
+	if i == -1 {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	return l.UnlinkFromCourseOfferingOnFieldMeetingsByEdge(l.Meetings[i])
+}
+
+// UnlinkFromCourseOfferingOnFieldMeetingsByEdge removes edge from Room.Meetings and from CourseOffering.Meetings of the CourseOffering it connects to.
+// use this to remove one specific edge when there are several between the same nodes
+func (l *Room) UnlinkFromCourseOfferingOnFieldMeetingsByEdge(edge *Meeting) error {
+	if edge == nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNilEdge)
+	}
+
+	i := indexOfMeeting(l.Meetings, edge)
+	if i == -1 {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	// edge may only match by uuid, work with the one that is actually linked
+	edge = l.Meetings[i]
+
+	obj := edge.GetStartNode()
+	target, ok := obj.(*CourseOffering)
+	if !ok {
+		return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+	}
+
+	a := &l.Meetings
+	(*a)[i] = (*a)[len(*a)-1]
+	(*a)[len(*a)-1] = nil
+	*a = (*a)[:len(*a)-1]
+
+	if target == nil {
+		return nil
+	}
+
+	if j := indexOfMeeting(target.Meetings, edge); j != -1 {
+		a := &target.Meetings
+		(*a)[j] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// UnlinkFromCourseOfferingOnFieldMeetingsWhere removes every Meeting between Room and target that match accepts.
+// a nil match accepts every edge
+func (l *Room) UnlinkFromCourseOfferingOnFieldMeetingsWhere(target *CourseOffering, match func(edge *Meeting) bool) error {
+	if target == nil {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNilTarget)
+	}
+
+	var edges []*Meeting
+	for _, unlinkTarget := range l.Meetings {
+		obj := unlinkTarget.GetStartNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Room", "Meetings", "MEETS_IN", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameCourseOffering(checkObj, target) && (match == nil || match(unlinkTarget)) {
+			edges = append(edges, unlinkTarget)
+		}
+	}
+
+	if len(edges) == 0 {
+		return newLinkError("Room", "Meetings", "MEETS_IN", ErrNotLinked)
+	}
+
+	for _, edge := range edges {
+		err := l.UnlinkFromCourseOfferingOnFieldMeetingsByEdge(edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAllFromCourseOfferingOnFieldMeetings removes every Meeting between Room and target
+func (l *Room) UnlinkAllFromCourseOfferingOnFieldMeetings(target *CourseOffering) error {
+	return l.UnlinkFromCourseOfferingOnFieldMeetingsWhere(target, nil)
+}
+
+// LinkToCourseOfferingOnFieldEnrollments links Student to CourseOffering on the fields Student.Enrollments and CourseOffering.Enrollments.
+// note this uses the special edge Enrollment
+func (l *Student) LinkToCourseOfferingOnFieldEnrollments(target *CourseOffering, edge *Enrollment) error {
+	return l.LinkToCourseOfferingOnFieldEnrollmentsWith(nil, target, edge)
+}
+
+// LinkToCourseOfferingOnFieldEnrollmentsWith links Student to CourseOffering on the fields Student.Enrollments and CourseOffering.Enrollments using opts.
+// the nodes count as linked if edge is already on Student.Enrollments or Student has any Enrollment to target
+func (l *Student) LinkToCourseOfferingOnFieldEnrollmentsWith(opts *LinkOptions, target *CourseOffering, edge *Enrollment) error {
+	if target == nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	if edge == nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNilEdge)
+	}
+
+	for _, existing := range l.Enrollments {
+		if existing == edge {
+			_, err := opts.onDuplicate()
+			return newLinkError("Student", "Enrollments", "ENROLLED", err)
+		}
+
+		obj := existing.GetEndNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameCourseOffering(checkObj, target) {
+			if skip, err := opts.onDuplicate(); skip {
+				return newLinkError("Student", "Enrollments", "ENROLLED", err)
+			}
+			break
+		}
+	}
+
+	err := checkLink(l, "Enrollments", target, "Enrollments", edge)
+	if err != nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", err)
+	}
+
+	err = edge.SetStartNode(l)
+	if err != nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	err = edge.SetEndNode(target)
+	if err != nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, %s", ErrEdgeTypeMismatch, err))
+	}
+
+	if l.Enrollments == nil {
+		l.Enrollments = make([]*Enrollment, 1, 1)
+		l.Enrollments[0] = edge
+	} else {
+		l.Enrollments = append(l.Enrollments, edge)
+	}
+
+	if target.Enrollments == nil {
+		target.Enrollments = make([]*Enrollment, 1, 1)
+		target.Enrollments[0] = edge
+	} else {
+		target.Enrollments = append(target.Enrollments, edge)
+	}
+
+	return nil
+}
+
+// UnlinkFromCourseOfferingOnFieldEnrollments unlinks Student from CourseOffering on the fields Student.Enrollments and CourseOffering.Enrollments.
+// also note this uses the special edge Enrollment, only the first edge to target is removed
+func (l *Student) UnlinkFromCourseOfferingOnFieldEnrollments(target *CourseOffering) error {
+	if target == nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	// match the node by pointer first so unsaved nodes without a uuid are told apart
+	i := -1
+	for j, unlinkTarget := range l.Enrollments {
+		obj := unlinkTarget.GetEndNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if checkObj == target {
+			i = j
+			break
+		}
+
+		if i == -1 && sameCourseOffering(checkObj, target) {
+			i = j
+		}
+	}
+
+	if i == -1 {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	return l.UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(l.Enrollments[i])
+}
+
+// UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge removes edge from Student.Enrollments and from CourseOffering.Enrollments of the CourseOffering it connects to.
+// use this to remove one specific edge when there are several between the same nodes
+func (l *Student) UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(edge *Enrollment) error {
+	if edge == nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNilEdge)
+	}
+
+	i := indexOfEnrollment(l.Enrollments, edge)
+	if i == -1 {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	// edge may only match by uuid, work with the one that is actually linked
+	edge = l.Enrollments[i]
+
+	obj := edge.GetEndNode()
+	target, ok := obj.(*CourseOffering)
+	if !ok {
+		return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+	}
+
+	a := &l.Enrollments
+	(*a)[i] = (*a)[len(*a)-1]
+	(*a)[len(*a)-1] = nil
+	*a = (*a)[:len(*a)-1]
+
+	if target == nil {
+		return nil
+	}
+
+	if j := indexOfEnrollment(target.Enrollments, edge); j != -1 {
+		a := &target.Enrollments
+		(*a)[j] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// UnlinkFromCourseOfferingOnFieldEnrollmentsWhere removes every Enrollment between Student and target that match accepts.
+// a nil match accepts every edge
+func (l *Student) UnlinkFromCourseOfferingOnFieldEnrollmentsWhere(target *CourseOffering, match func(edge *Enrollment) bool) error {
+	if target == nil {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNilTarget)
+	}
+
+	var edges []*Enrollment
+	for _, unlinkTarget := range l.Enrollments {
+		obj := unlinkTarget.GetEndNode()
+		checkObj, ok := obj.(*CourseOffering)
+		if !ok {
+			return newLinkError("Student", "Enrollments", "ENROLLED", fmt.Errorf("%w, expected [*CourseOffering] found [%T]", ErrEdgeTypeMismatch, obj))
+		}
+
+		if sameCourseOffering(checkObj, target) && (match == nil || match(unlinkTarget)) {
+			edges = append(edges, unlinkTarget)
+		}
+	}
+
+	if len(edges) == 0 {
+		return newLinkError("Student", "Enrollments", "ENROLLED", ErrNotLinked)
+	}
+
+	for _, edge := range edges {
+		err := l.UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnlinkAllFromCourseOfferingOnFieldEnrollments removes every Enrollment between Student and target
+func (l *Student) UnlinkAllFromCourseOfferingOnFieldEnrollments(target *CourseOffering) error {
+	return l.UnlinkFromCourseOfferingOnFieldEnrollmentsWhere(target, nil)
+}
+
+// LinkToDepartmentOnFieldDepartment links Subject to Department on the fields Subject.Department and Department.Subjects.
+// the Subject is removed from Subjects of the Department it was linked to before
+func (l *Subject) LinkToDepartmentOnFieldDepartment(target *Department) error {
+	return l.LinkToDepartmentOnFieldDepartmentWith(nil, target)
+}
+
+// LinkToDepartmentOnFieldDepartmentWith links Subject to Department on the fields Subject.Department and Department.Subjects using opts
+func (l *Subject) LinkToDepartmentOnFieldDepartmentWith(opts *LinkOptions, target *Department) error {
+	if target == nil {
+		return newLinkError("Subject", "Department", "CURRICULUM", ErrNilTarget)
+	}
+
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameDepartment(l.Department, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("Subject", "Department", "CURRICULUM", err)
+	}
+
+	if err := checkLink(l, "Department", target, "Subjects", nil); err != nil {
+		return newLinkError("Subject", "Department", "CURRICULUM", err)
+	}
+
+	if previous := l.Department; previous != nil && !sameDepartment(previous, target) {
+		if i := indexOfSubject(previous.Subjects, l); i != -1 {
+			a := &previous.Subjects
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
+
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Subjects"})
+	}
+
+	l.Department = target
+
+	if target.Subjects == nil {
+		target.Subjects = make([]*Subject, 1, 1)
+		target.Subjects[0] = l
+	} else {
+		target.Subjects = append(target.Subjects, l)
+	}
+
+	return nil
+}
+
+// UnlinkFromDepartmentOnFieldDepartment unlinks Subject from Department on the fields Subject.Department and Department.Subjects
+func (l *Subject) UnlinkFromDepartmentOnFieldDepartment(target *Department) error {
+	if target == nil {
+		return newLinkError("Subject", "Department", "CURRICULUM", ErrNilTarget)
+	}
+
+	if !sameDepartment(l.Department, target) {
+		return newLinkError("Subject", "Department", "CURRICULUM", ErrNotLinked)
+	}
+
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Department
+	l.Department = nil
+
+	if i := indexOfSubject(target.Subjects, l); i != -1 {
+		a := &target.Subjects
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+	}
+
+	return nil
+}
+
+// LinkToTeacherOnFieldTeachers links Subject to Teacher on the fields Subject.Teachers and Teacher.Subjects
+func (l *Subject) LinkToTeacherOnFieldTeachers(targets ...*Teacher) error {
+	return l.LinkToTeacherOnFieldTeachersWith(nil, targets...)
+}
+
+// LinkToTeacherOnFieldTeachersWith links Subject to Teacher on the fields Subject.Teachers and Teacher.Subjects using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Subject) LinkToTeacherOnFieldTeachersWith(opts *LinkOptions, targets ...*Teacher) error {
+	if len(targets) == 0 {
+		return newLinkError("Subject", "Teachers", "TAUGHT_BY", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, ErrNilTarget)
+		}
+
+		if indexOfTeacher(l.Teachers, target) != -1 || indexOfTeacher(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Teachers", target, "Subjects", nil); err != nil {
+			return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, err)
+		}
+
+		if err := checkPendingLink(l, "Teachers", target, pending); err != nil {
+			return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOfTeacher(l.Teachers, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
+
+		if l.Teachers == nil {
+			l.Teachers = make([]*Teacher, 1, 1)
+			l.Teachers[0] = target
+		} else {
+			l.Teachers = append(l.Teachers, target)
+		}
+
+		if target.Subjects == nil {
+			target.Subjects = make([]*Subject, 1, 1)
+			target.Subjects[0] = l
+		} else {
+			target.Subjects = append(target.Subjects, l)
+		}
+	}
+
+	return nil
+}
+
+// UnlinkFromTeacherOnFieldTeachers unlinks Subject from Teacher on the fields Subject.Teachers and Teacher.Subjects.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Subject) UnlinkFromTeacherOnFieldTeachers(targets ...*Teacher) error {
+	if len(targets) == 0 {
+		return newLinkError("Subject", "Teachers", "TAUGHT_BY", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, ErrNilTarget)
+		}
+
+		if indexOfTeacher(l.Teachers, target) == -1 || indexOfTeacher(targets[:i], target) != -1 {
+			return newLinkTargetError("Subject", "Teachers", "TAUGHT_BY", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOfTeacher(l.Teachers, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Teachers[i]
+
+		a := &l.Teachers
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if j := indexOfSubject(target.Subjects, l); j != -1 {
+			a := &target.Subjects
+			(*a)[j] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
+		}
+	}
+
+	return nil
+}
+
+// LinkToCourseOnFieldCourses links Subject to Course on the fields Subject.Courses and Course.Subject.
+// targets are removed from Courses of the Subject they were linked to before
+func (l *Subject) LinkToCourseOnFieldCourses(targets ...*Course) error {
+	return l.LinkToCourseOnFieldCoursesWith(nil, targets...)
+}
+
+// LinkToCourseOnFieldCoursesWith links Subject to Course on the fields Subject.Courses and Course.Subject using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Subject) LinkToCourseOnFieldCoursesWith(opts *LinkOptions, targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Subject", "Courses", "SUBJECT_TAUGHT", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, ErrNilTarget)
+		}
+
+		if indexOfCourse(l.Courses, target) != -1 || indexOfCourse(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Courses", target, "Subject", nil); err != nil {
+			return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, err)
+		}
+
+		if err := checkPendingLink(l, "Courses", target, pending); err != nil {
+			return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOfCourse(l.Courses, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
+
+		if previous := target.Subject; previous != nil && !sameSubject(previous, l) {
+			if i := indexOfCourse(previous.Courses, target); i != -1 {
+				a := &previous.Courses
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
 
-	l.Department = target
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Courses"})
+		}
 
-	if target.Teachers == nil {
-		target.Teachers = make([]*Teacher, 1, 1)
-		target.Teachers[0] = l
-	} else {
-		target.Teachers = append(target.Teachers, l)
+		if l.Courses == nil {
+			l.Courses = make([]*Course, 1, 1)
+			l.Courses[0] = target
+		} else {
+			l.Courses = append(l.Courses, target)
+		}
+
+		target.Subject = l
 	}
 
 	return nil
 }
 
-//UnlinkFromDepartmentOnFieldDepartment unlinks Teacher from Department on the fields Teacher.Department and Department.Teachers
-func (l *Teacher) UnlinkFromDepartmentOnFieldDepartment(target *Department) error {
-	if target == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromCourseOnFieldCourses unlinks Subject from Course on the fields Subject.Courses and Course.Subject.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Subject) UnlinkFromCourseOnFieldCourses(targets ...*Course) error {
+	if len(targets) == 0 {
+		return newLinkError("Subject", "Courses", "SUBJECT_TAUGHT", ErrNoTargets)
 	}
 
-	l.Department = nil
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, ErrNilTarget)
+		}
+
+		if indexOfCourse(l.Courses, target) == -1 || indexOfCourse(targets[:i], target) != -1 {
+			return newLinkTargetError("Subject", "Courses", "SUBJECT_TAUGHT", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOfCourse(l.Courses, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Courses[i]
+
+		a := &l.Courses
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameSubject(target.Subject, l) {
+			target.Subject = nil
+		}
+	}
+
+	return nil
+}
+
+// LinkToCourseOfferingOnFieldOfferings links Teacher to CourseOffering on the fields Teacher.Offerings and CourseOffering.Teacher.
+// targets are removed from Offerings of the Teacher they were linked to before
+func (l *Teacher) LinkToCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	return l.LinkToCourseOfferingOnFieldOfferingsWith(nil, targets...)
+}
+
+// LinkToCourseOfferingOnFieldOfferingsWith links Teacher to CourseOffering on the fields Teacher.Offerings and CourseOffering.Teacher using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Teacher) LinkToCourseOfferingOnFieldOfferingsWith(opts *LinkOptions, targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Teacher", "Offerings", "TEACHES_CLASS", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, ErrNilTarget)
+		}
+
+		if indexOfCourseOffering(l.Offerings, target) != -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Offerings", target, "Teacher", nil); err != nil {
+			return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, err)
+		}
+
+		if err := checkPendingLink(l, "Offerings", target, pending); err != nil {
+			return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, err)
+		}
+		pending = append(pending, target)
+	}
+
+	for _, target := range targets {
+		if indexOfCourseOffering(l.Offerings, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
 
-	if target.Teachers != nil {
-		for i, unlinkTarget := range target.Teachers {
-			if unlinkTarget.UUID == l.UUID {
-				a := &target.Teachers
+		if previous := target.Teacher; previous != nil && !sameTeacher(previous, l) {
+			if i := indexOfCourseOffering(previous.Offerings, target); i != -1 {
+				a := &previous.Offerings
 				(*a)[i] = (*a)[len(*a)-1]
 				(*a)[len(*a)-1] = nil
 				*a = (*a)[:len(*a)-1]
-				break
 			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Offerings"})
+		}
+
+		if l.Offerings == nil {
+			l.Offerings = make([]*CourseOffering, 1, 1)
+			l.Offerings[0] = target
+		} else {
+			l.Offerings = append(l.Offerings, target)
+		}
+
+		target.Teacher = l
+	}
+
+	return nil
+}
+
+// UnlinkFromCourseOfferingOnFieldOfferings unlinks Teacher from CourseOffering on the fields Teacher.Offerings and CourseOffering.Teacher.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Teacher) UnlinkFromCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Teacher", "Offerings", "TEACHES_CLASS", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, ErrNilTarget)
+		}
+
+		if indexOfCourseOffering(l.Offerings, target) == -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			return newLinkTargetError("Teacher", "Offerings", "TEACHES_CLASS", i, ErrNotLinked)
+		}
+	}
+
+	for _, target := range targets {
+		i := indexOfCourseOffering(l.Offerings, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Offerings[i]
+
+		a := &l.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameTeacher(target.Teacher, l) {
+			target.Teacher = nil
 		}
 	}
 
@@ -546,11 +1897,45 @@ func (l *Teacher) UnlinkFromDepartmentOnFieldDepartment(target *Department) erro
 
 // LinkToSubjectOnFieldSubjects links Teacher to Subject on the fields Teacher.Subjects and Subject.Teachers
 func (l *Teacher) LinkToSubjectOnFieldSubjects(targets ...*Subject) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+	return l.LinkToSubjectOnFieldSubjectsWith(nil, targets...)
+}
+
+// LinkToSubjectOnFieldSubjectsWith links Teacher to Subject on the fields Teacher.Subjects and Subject.Teachers using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Teacher) LinkToSubjectOnFieldSubjectsWith(opts *LinkOptions, targets ...*Subject) error {
+	if len(targets) == 0 {
+		return newLinkError("Teacher", "Subjects", "TAUGHT_BY", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, ErrNilTarget)
+		}
+
+		if indexOfSubject(l.Subjects, target) != -1 || indexOfSubject(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Subjects", target, "Teachers", nil); err != nil {
+			return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, err)
+		}
+
+		if err := checkPendingLink(l, "Subjects", target, pending); err != nil {
+			return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, err)
+		}
+		pending = append(pending, target)
 	}
 
 	for _, target := range targets {
+		if indexOfSubject(l.Subjects, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
 
 		if l.Subjects == nil {
 			l.Subjects = make([]*Subject, 1, 1)
@@ -570,85 +1955,401 @@ func (l *Teacher) LinkToSubjectOnFieldSubjects(targets ...*Subject) error {
 	return nil
 }
 
-//UnlinkFromSubjectOnFieldSubjects unlinks Teacher from Subject on the fields Teacher.Subjects and Subject.Teachers
+// UnlinkFromSubjectOnFieldSubjects unlinks Teacher from Subject on the fields Teacher.Subjects and Subject.Teachers.
+// either every target is unlinked or, if one of them is rejected, none are
 func (l *Teacher) UnlinkFromSubjectOnFieldSubjects(targets ...*Subject) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+	if len(targets) == 0 {
+		return newLinkError("Teacher", "Subjects", "TAUGHT_BY", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, ErrNilTarget)
+		}
+
+		if indexOfSubject(l.Subjects, target) == -1 || indexOfSubject(targets[:i], target) != -1 {
+			return newLinkTargetError("Teacher", "Subjects", "TAUGHT_BY", i, ErrNotLinked)
+		}
 	}
 
 	for _, target := range targets {
+		i := indexOfSubject(l.Subjects, target)
 
-		if l.Subjects != nil {
-			for i, unlinkTarget := range l.Subjects {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Subjects
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Subjects[i]
+
+		a := &l.Subjects
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if j := indexOfTeacher(target.Teachers, l); j != -1 {
+			a := &target.Teachers
+			(*a)[j] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
 		}
+	}
 
-		if target.Teachers != nil {
-			for i, unlinkTarget := range target.Teachers {
-				if unlinkTarget.UUID == l.UUID {
-					a := &target.Teachers
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
-			}
+	return nil
+}
+
+// LinkToDepartmentOnFieldDepartment links Teacher to Department on the fields Teacher.Department and Department.Teachers.
+// the Teacher is removed from Teachers of the Department it was linked to before
+func (l *Teacher) LinkToDepartmentOnFieldDepartment(target *Department) error {
+	return l.LinkToDepartmentOnFieldDepartmentWith(nil, target)
+}
+
+// LinkToDepartmentOnFieldDepartmentWith links Teacher to Department on the fields Teacher.Department and Department.Teachers using opts
+func (l *Teacher) LinkToDepartmentOnFieldDepartmentWith(opts *LinkOptions, target *Department) error {
+	if target == nil {
+		return newLinkError("Teacher", "Department", "FOR_DEPARTMENT", ErrNilTarget)
+	}
+
+	// a to-one field holds one node, linking the same one again changes nothing whatever the policy
+	if sameDepartment(l.Department, target) {
+		_, err := opts.onDuplicate()
+		return newLinkError("Teacher", "Department", "FOR_DEPARTMENT", err)
+	}
+
+	if err := checkLink(l, "Department", target, "Teachers", nil); err != nil {
+		return newLinkError("Teacher", "Department", "FOR_DEPARTMENT", err)
+	}
+
+	if previous := l.Department; previous != nil && !sameDepartment(previous, target) {
+		if i := indexOfTeacher(previous.Teachers, l); i != -1 {
+			a := &previous.Teachers
+			(*a)[i] = (*a)[len(*a)-1]
+			(*a)[len(*a)-1] = nil
+			*a = (*a)[:len(*a)-1]
 		}
+
+		opts.moved(LinkMove{Node: l, Previous: previous, Current: target, Field: "Teachers"})
+	}
+
+	l.Department = target
+
+	if target.Teachers == nil {
+		target.Teachers = make([]*Teacher, 1, 1)
+		target.Teachers[0] = l
+	} else {
+		target.Teachers = append(target.Teachers, l)
+	}
+
+	return nil
+}
+
+// UnlinkFromDepartmentOnFieldDepartment unlinks Teacher from Department on the fields Teacher.Department and Department.Teachers
+func (l *Teacher) UnlinkFromDepartmentOnFieldDepartment(target *Department) error {
+	if target == nil {
+		return newLinkError("Teacher", "Department", "FOR_DEPARTMENT", ErrNilTarget)
+	}
+
+	if !sameDepartment(l.Department, target) {
+		return newLinkError("Teacher", "Department", "FOR_DEPARTMENT", ErrNotLinked)
+	}
+
+	// target may only match by uuid, work with the one that is actually linked
+	target = l.Department
+	l.Department = nil
+
+	if i := indexOfTeacher(target.Teachers, l); i != -1 {
+		a := &target.Teachers
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
 	}
 
 	return nil
 }
 
-// LinkToCourseOnFieldCourses links Teacher to Course on the fields Teacher.Courses and Course.Teacher
-func (l *Teacher) LinkToCourseOnFieldCourses(targets ...*Course) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// LinkToCourseOfferingOnFieldOfferings links Term to CourseOffering on the fields Term.Offerings and CourseOffering.Term.
+// targets are removed from Offerings of the Term they were linked to before
+func (l *Term) LinkToCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	return l.LinkToCourseOfferingOnFieldOfferingsWith(nil, targets...)
+}
+
+// LinkToCourseOfferingOnFieldOfferingsWith links Term to CourseOffering on the fields Term.Offerings and CourseOffering.Term using opts.
+// either every target is linked or, if one of them is rejected, none are
+func (l *Term) LinkToCourseOfferingOnFieldOfferingsWith(opts *LinkOptions, targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Term", "Offerings", "OFFERED_IN", ErrNoTargets)
+	}
+
+	// check every target before linking any of them so a bad target leaves the nodes untouched
+	pending := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, ErrNilTarget)
+		}
+
+		if indexOfCourseOffering(l.Offerings, target) != -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			if _, err := opts.onDuplicate(); err != nil {
+				return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, err)
+			}
+		}
+
+		if err := checkLink(l, "Offerings", target, "Term", nil); err != nil {
+			return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, err)
+		}
+
+		if err := checkPendingLink(l, "Offerings", target, pending); err != nil {
+			return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, err)
+		}
+		pending = append(pending, target)
 	}
 
 	for _, target := range targets {
+		if indexOfCourseOffering(l.Offerings, target) != -1 {
+			if skip, _ := opts.onDuplicate(); skip {
+				continue
+			}
+		}
 
-		if l.Courses == nil {
-			l.Courses = make([]*Course, 1, 1)
-			l.Courses[0] = target
+		if previous := target.Term; previous != nil && !sameTerm(previous, l) {
+			if i := indexOfCourseOffering(previous.Offerings, target); i != -1 {
+				a := &previous.Offerings
+				(*a)[i] = (*a)[len(*a)-1]
+				(*a)[len(*a)-1] = nil
+				*a = (*a)[:len(*a)-1]
+			}
+
+			opts.moved(LinkMove{Node: target, Previous: previous, Current: l, Field: "Offerings"})
+		}
+
+		if l.Offerings == nil {
+			l.Offerings = make([]*CourseOffering, 1, 1)
+			l.Offerings[0] = target
 		} else {
-			l.Courses = append(l.Courses, target)
+			l.Offerings = append(l.Offerings, target)
 		}
 
-		target.Teacher = l
+		target.Term = l
 	}
 
 	return nil
 }
 
-//UnlinkFromCourseOnFieldCourses unlinks Teacher from Course on the fields Teacher.Courses and Course.Teacher
-func (l *Teacher) UnlinkFromCourseOnFieldCourses(targets ...*Course) error {
-	if targets == nil {
-		return errors.New("start and end can not be nil")
+// UnlinkFromCourseOfferingOnFieldOfferings unlinks Term from CourseOffering on the fields Term.Offerings and CourseOffering.Term.
+// either every target is unlinked or, if one of them is rejected, none are
+func (l *Term) UnlinkFromCourseOfferingOnFieldOfferings(targets ...*CourseOffering) error {
+	if len(targets) == 0 {
+		return newLinkError("Term", "Offerings", "OFFERED_IN", ErrNoTargets)
+	}
+
+	// check every target before unlinking any of them so a bad target leaves the nodes untouched
+	for i, target := range targets {
+		if target == nil {
+			return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, ErrNilTarget)
+		}
+
+		if indexOfCourseOffering(l.Offerings, target) == -1 || indexOfCourseOffering(targets[:i], target) != -1 {
+			return newLinkTargetError("Term", "Offerings", "OFFERED_IN", i, ErrNotLinked)
+		}
 	}
 
 	for _, target := range targets {
+		i := indexOfCourseOffering(l.Offerings, target)
+
+		// target may only match by uuid, work with the one that is actually linked
+		target = l.Offerings[i]
+
+		a := &l.Offerings
+		(*a)[i] = (*a)[len(*a)-1]
+		(*a)[len(*a)-1] = nil
+		*a = (*a)[:len(*a)-1]
+
+		if sameTerm(target.Term, l) {
+			target.Term = nil
+		}
+	}
+
+	return nil
+}
+
+// sameCourse checks if a and b are the same Course, matching by pointer first and uuid second
+func sameCourse(a, b *Course) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameCourseOffering checks if a and b are the same CourseOffering, matching by pointer first and uuid second
+func sameCourseOffering(a, b *CourseOffering) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameDepartment checks if a and b are the same Department, matching by pointer first and uuid second
+func sameDepartment(a, b *Department) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameRoom checks if a and b are the same Room, matching by pointer first and uuid second
+func sameRoom(a, b *Room) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
 
-		if l.Courses != nil {
-			for i, unlinkTarget := range l.Courses {
-				if unlinkTarget.UUID == target.UUID {
-					a := &l.Courses
-					(*a)[i] = (*a)[len(*a)-1]
-					(*a)[len(*a)-1] = nil
-					*a = (*a)[:len(*a)-1]
-					break
-				}
+// sameStudent checks if a and b are the same Student, matching by pointer first and uuid second
+func sameStudent(a, b *Student) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameSubject checks if a and b are the same Subject, matching by pointer first and uuid second
+func sameSubject(a, b *Subject) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameTeacher checks if a and b are the same Teacher, matching by pointer first and uuid second
+func sameTeacher(a, b *Teacher) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// sameTerm checks if a and b are the same Term, matching by pointer first and uuid second
+func sameTerm(a, b *Term) bool {
+	if a == b {
+		return true
+	}
+
+	return a != nil && b != nil && a.UUID != "" && a.UUID == b.UUID
+}
+
+// indexOfCourse returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfCourse(nodes []*Course, node *Course) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
 			}
 		}
+	}
 
-		target.Teacher = nil
+	return -1
+}
+
+// indexOfCourseOffering returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfCourseOffering(nodes []*CourseOffering, node *CourseOffering) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
 	}
 
-	return nil
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// indexOfEnrollment returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfEnrollment(nodes []*Enrollment, node *Enrollment) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// indexOfMeeting returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfMeeting(nodes []*Meeting, node *Meeting) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// indexOfSubject returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfSubject(nodes []*Subject, node *Subject) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// indexOfTeacher returns the index of node in nodes, matching by pointer first and uuid second. returns -1 if node is not in nodes
+func indexOfTeacher(nodes []*Teacher, node *Teacher) int {
+	for i, n := range nodes {
+		if n == node {
+			return i
+		}
+	}
+
+	if node.UUID != "" {
+		for i, n := range nodes {
+			if n != nil && n.UUID == node.UUID {
+				return i
+			}
+		}
+	}
+
+	return -1
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errors returned by the functions in linking.go, always wrapped in a *LinkError.
+// use errors.Is to check for them
+var (
+	// ErrNilTarget is returned when a node to link or unlink is nil
+	ErrNilTarget = errors.New("target can not be nil")
+	// ErrNilEdge is returned when the special edge of a relationship is nil
+	ErrNilEdge = errors.New("edge can not be nil")
+	// ErrEdgeTypeMismatch is returned when a special edge does not connect the node types of the relationship
+	ErrEdgeTypeMismatch = errors.New("edge does not connect the expected node types")
+	// ErrAlreadyLinked is returned by the Link*With functions when the nodes are already linked
+	// and the options ask to fail on duplicates
+	ErrAlreadyLinked = errors.New("nodes are already linked")
+	// ErrNotLinked is returned by the Unlink* functions when the nodes were not linked to begin with
+	ErrNotLinked = errors.New("nodes are not linked")
+	// ErrNoTargets is returned when a variadic Link* or Unlink* function is called without targets
+	ErrNoTargets = errors.New("at least one target is required")
+)
+
+// LinkError names the relationship a linking error happened on
+type LinkError struct {
+	// Source is the type of the node the function was called on
+	Source string
+	// Field is the field on Source the relationship is stored in
+	Field string
+	// Relationship is the neo4j relationship type
+	Relationship string
+	// Index is the position of the rejected target in a variadic call, -1 if the error is not about one target
+	Index int
+
+	Err error
+}
+
+// newLinkError wraps err with the relationship it happened on, returns nil if err is nil
+func newLinkError(source, field, relationship string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &LinkError{
+		Source:       source,
+		Field:        field,
+		Relationship: relationship,
+		Index:        -1,
+		Err:          err,
+	}
+}
+
+// newLinkTargetError wraps err with the relationship it happened on and the index of the rejected target
+func newLinkTargetError(source, field, relationship string, index int, err error) error {
+	return &LinkError{
+		Source:       source,
+		Field:        field,
+		Relationship: relationship,
+		Index:        index,
+		Err:          err,
+	}
+}
+
+func (e *LinkError) Error() string {
+	if e.Index >= 0 {
+		return fmt.Sprintf("%s.%s [%s] target %v: %s", e.Source, e.Field, e.Relationship, e.Index, e.Err.Error())
+	}
+
+	return fmt.Sprintf("%s.%s [%s]: %s", e.Source, e.Field, e.Relationship, e.Err.Error())
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
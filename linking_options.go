@@ -0,0 +1,100 @@
+package main
+
+// LinkMove describes a node that was detached from the node it was linked to before.
+// Previous has to be saved as well, otherwise the old relationship is still in the database.
+type LinkMove struct {
+	// Node is the node that was relinked
+	Node interface{}
+	// Previous is the node Node was removed from
+	Previous interface{}
+	// Current is the node Node is linked to now
+	Current interface{}
+	// Field is the field on Previous that Node was removed from
+	Field string
+}
+
+// DuplicatePolicy decides what linking does when the nodes are already linked,
+// either by the same pointer or by nodes with the same uuid
+type DuplicatePolicy int
+
+const (
+	// SkipDuplicates leaves the existing link alone, linking again is a no-op
+	SkipDuplicates DuplicatePolicy = iota
+	// FailOnDuplicate returns ErrAlreadyLinked
+	FailOnDuplicate
+	// AllowDuplicates links again, for special edges this adds another edge between the same nodes.
+	// to-one fields hold a single node, linking the node they already hold is still a no-op
+	AllowDuplicates
+)
+
+// LinkOptions changes the behaviour of the generated Link*With functions
+type LinkOptions struct {
+	// Duplicates decides what happens when the nodes are already linked, defaults to SkipDuplicates
+	Duplicates DuplicatePolicy
+
+	// OnMove is called for every node that is detached from a previous owner while linking
+	OnMove func(move LinkMove)
+}
+
+// moved reports a move, opts may be nil
+func (o *LinkOptions) moved(move LinkMove) {
+	if o != nil && o.OnMove != nil {
+		o.OnMove(move)
+	}
+}
+
+// onDuplicate tells the caller whether to skip an existing link and what to return if it does, opts may be nil
+func (o *LinkOptions) onDuplicate() (bool, error) {
+	if o == nil {
+		return true, nil
+	}
+
+	switch o.Duplicates {
+	case FailOnDuplicate:
+		return true, ErrAlreadyLinked
+	case AllowDuplicates:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// LinkChecker is implemented by nodes that refuse some links. the Link* functions in linking.go ask both
+// nodes before linking them, a refusal is returned wrapped in a *LinkError and nothing is changed
+type LinkChecker interface {
+	// CheckLink is called before other is linked on field, edge is the special edge of the relationship or nil
+	CheckLink(field string, other interface{}, edge interface{}) error
+}
+
+// checkLink asks node whether target can be linked on field and target whether node can be linked on otherField
+func checkLink(node interface{}, field string, target interface{}, otherField string, edge interface{}) error {
+	if checker, ok := node.(LinkChecker); ok {
+		err := checker.CheckLink(field, target, edge)
+		if err != nil {
+			return err
+		}
+	}
+
+	if checker, ok := target.(LinkChecker); ok {
+		return checker.CheckLink(otherField, node, edge)
+	}
+
+	return nil
+}
+
+// PendingLinkChecker is implemented by nodes that refuse targets clashing with each other. the Link* functions
+// taking several targets check all of them before linking any, so CheckLink does not see the targets of the same
+// call, CheckPendingLink is asked about each target with the targets before it
+type PendingLinkChecker interface {
+	// CheckPendingLink is called after CheckLink accepted other on field, pending are the targets checked before it
+	CheckPendingLink(field string, other interface{}, pending []interface{}) error
+}
+
+// checkPendingLink asks node whether target can be linked on field along with the pending targets
+func checkPendingLink(node interface{}, field string, target interface{}, pending []interface{}) error {
+	if checker, ok := node.(PendingLinkChecker); ok {
+		return checker.CheckPendingLink(field, target, pending)
+	}
+
+	return nil
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// teacherNames returns the names of teachers in order
+func teacherNames(teachers []*Teacher) []string {
+	var names []string
+	for _, teacher := range teachers {
+		names = append(names, teacher.Name)
+	}
+	return names
+}
+
+func TestRelinkDetachesPreviousOwner(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		link func(opts *LinkOptions, crosby *Teacher, compsci *Department) error
+	}{
+		{"to-one", func(opts *LinkOptions, crosby *Teacher, compsci *Department) error {
+			return crosby.LinkToDepartmentOnFieldDepartmentWith(opts, compsci)
+		}},
+		{"to-many", func(opts *LinkOptions, crosby *Teacher, compsci *Department) error {
+			return compsci.LinkToTeacherOnFieldTeachersWith(opts, crosby)
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			history, compsci := &Department{Name: "History"}, &Department{Name: "Compsci"}
+			crosby, elias := &Teacher{Name: "Crosby"}, &Teacher{Name: "Elias"}
+			history.LinkToTeacherOnFieldTeachers(crosby, elias)
+
+			var moves []LinkMove
+			err := tt.link(&LinkOptions{OnMove: func(move LinkMove) { moves = append(moves, move) }}, crosby, compsci)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if crosby.Department != compsci {
+				t.Errorf("Crosby is in %v, want Compsci", crosby.Department.Name)
+			}
+			if got := teacherNames(compsci.Teachers); !reflect.DeepEqual(got, []string{"Crosby"}) {
+				t.Errorf("Compsci has %v, want [Crosby]", got)
+			}
+			if got := teacherNames(history.Teachers); !reflect.DeepEqual(got, []string{"Elias"}) {
+				t.Errorf("History still has %v, want [Elias]", got)
+			}
+
+			want := []LinkMove{{Node: crosby, Previous: history, Current: compsci, Field: "Teachers"}}
+			if !reflect.DeepEqual(moves, want) {
+				t.Errorf("got moves %+v, want %+v", moves, want)
+			}
+		})
+	}
+}
+
+func TestRelinkSameOwnerIsNotAMove(t *testing.T) {
+	compsci, elias := &Department{Name: "Compsci"}, &Teacher{Name: "Elias"}
+	compsci.LinkToTeacherOnFieldTeachers(elias)
+
+	moved := false
+	opts := &LinkOptions{OnMove: func(LinkMove) { moved = true }}
+
+	err := elias.LinkToDepartmentOnFieldDepartmentWith(opts, compsci)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moved {
+		t.Error("relinking the same department reported a move")
+	}
+	if len(compsci.Teachers) != 1 {
+		t.Errorf("Compsci has %v teachers, want 1", len(compsci.Teachers))
+	}
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	// each relationship kind links the same nodes twice and counts the links on the target
+	kinds := []struct {
+		name string
+		link func() (relink func(opts *LinkOptions) error, count func() int)
+	}{
+		{"to-one", func() (func(*LinkOptions) error, func() int) {
+			cs341, dataStructures := &Course{Name: "cs341"}, &Subject{Name: "dataStructures"}
+			cs341.LinkToSubjectOnFieldSubject(dataStructures)
+			return func(opts *LinkOptions) error {
+					return cs341.LinkToSubjectOnFieldSubjectWith(opts, dataStructures)
+				}, func() int {
+					return len(dataStructures.Courses)
+				}
+		}},
+		{"to-many", func() (func(*LinkOptions) error, func() int) {
+			compsci, dataStructures := &Department{Name: "Compsci"}, &Subject{Name: "dataStructures"}
+			compsci.LinkToSubjectOnFieldSubjects(dataStructures)
+			return func(opts *LinkOptions) error {
+					return compsci.LinkToSubjectOnFieldSubjectsWith(opts, dataStructures)
+				}, func() int {
+					return len(compsci.Subjects)
+				}
+		}},
+		{"edge", func() (func(*LinkOptions) error, func() int) {
+			eric, cs341 := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0"}
+			eric.LinkToCourseOfferingOnFieldEnrollments(cs341, NewEnrollment(testDate))
+			return func(opts *LinkOptions) error {
+					return eric.LinkToCourseOfferingOnFieldEnrollmentsWith(opts, cs341, NewEnrollment(testDate))
+				}, func() int {
+					return len(cs341.Enrollments)
+				}
+		}},
+	}
+
+	for _, tt := range []struct {
+		kind    string
+		opts    *LinkOptions
+		wantErr error
+		want    int
+	}{
+		{"to-one", nil, nil, 1},
+		{"to-one", &LinkOptions{Duplicates: SkipDuplicates}, nil, 1},
+		{"to-one", &LinkOptions{Duplicates: FailOnDuplicate}, ErrAlreadyLinked, 1},
+		{"to-one", &LinkOptions{Duplicates: AllowDuplicates}, nil, 1},
+		{"to-many", nil, nil, 1},
+		{"to-many", &LinkOptions{Duplicates: SkipDuplicates}, nil, 1},
+		{"to-many", &LinkOptions{Duplicates: FailOnDuplicate}, ErrAlreadyLinked, 1},
+		{"to-many", &LinkOptions{Duplicates: AllowDuplicates}, nil, 2},
+		{"edge", nil, nil, 1},
+		{"edge", &LinkOptions{Duplicates: SkipDuplicates}, nil, 1},
+		{"edge", &LinkOptions{Duplicates: FailOnDuplicate}, ErrAlreadyLinked, 1},
+		{"edge", &LinkOptions{Duplicates: AllowDuplicates}, nil, 2},
+	} {
+		for _, kind := range kinds {
+			if kind.name != tt.kind {
+				continue
+			}
+
+			relink, count := kind.link()
+			err := relink(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s with %+v: got %v, want %v", tt.kind, tt.opts, err, tt.wantErr)
+			}
+
+			if got := count(); got != tt.want {
+				t.Errorf("%s with %+v: got %v links, want %v", tt.kind, tt.opts, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestDuplicateMatchedByUUID(t *testing.T) {
+	compsci, dataStructures := &Department{Name: "Compsci"}, &Subject{Name: "dataStructures"}
+	dataStructures.UUID = "subject-1"
+	compsci.LinkToSubjectOnFieldSubjects(dataStructures)
+
+	// a copy loaded in another session is the same node
+	loaded := &Subject{Name: "dataStructures"}
+	loaded.UUID = dataStructures.UUID
+
+	err := compsci.LinkToSubjectOnFieldSubjectsWith(&LinkOptions{Duplicates: FailOnDuplicate}, loaded)
+	if !errors.Is(err, ErrAlreadyLinked) {
+		t.Errorf("got %v, want %v", err, ErrAlreadyLinked)
+	}
+}
+
+func TestUnlinkMatchesPointerFirst(t *testing.T) {
+	// none of the nodes are saved, so they all have the same empty uuid
+	compsci, dataStructures, algorithms := &Department{Name: "Compsci"}, &Subject{Name: "dataStructures"}, &Subject{Name: "algorithms"}
+	compsci.LinkToSubjectOnFieldSubjects(dataStructures, algorithms)
+
+	err := compsci.UnlinkFromSubjectOnFieldSubjects(algorithms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(compsci.Subjects) != 1 || compsci.Subjects[0] != dataStructures {
+		t.Errorf("Compsci has %+v, want only dataStructures", compsci.Subjects)
+	}
+	if algorithms.Department != nil || dataStructures.Department != compsci {
+		t.Error("the department of the subjects was not updated")
+	}
+
+	eric, cs341, hist347 := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0"}, &CourseOffering{Name: "hist347-0"}
+	eric.LinkToCourseOfferingOnFieldEnrollments(cs341, NewEnrollment(testDate))
+	eric.LinkToCourseOfferingOnFieldEnrollments(hist347, NewEnrollment(testDate))
+
+	err = eric.UnlinkFromCourseOfferingOnFieldEnrollments(hist347)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(eric.Enrollments) != 1 || eric.Enrollments[0].End != cs341 {
+		t.Errorf("eric is still enrolled in the wrong offerings")
+	}
+	if len(hist347.Enrollments) != 0 || len(cs341.Enrollments) != 1 {
+		t.Errorf("hist347 has %v enrollments and cs341 %v, want 0 and 1", len(hist347.Enrollments), len(cs341.Enrollments))
+	}
+}
+
+func TestUnlinkMatchesUUIDSecond(t *testing.T) {
+	compsci, dataStructures := &Department{Name: "Compsci"}, &Subject{Name: "dataStructures"}
+	dataStructures.UUID = "subject-1"
+	compsci.LinkToSubjectOnFieldSubjects(dataStructures)
+
+	loaded := &Subject{Name: "dataStructures"}
+	loaded.UUID = dataStructures.UUID
+
+	err := compsci.UnlinkFromSubjectOnFieldSubjects(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(compsci.Subjects) != 0 || dataStructures.Department != nil {
+		t.Error("the subject linked by pointer was not unlinked")
+	}
+
+	history, crosby := &Department{Name: "History"}, &Teacher{Name: "Crosby"}
+	history.UUID = "department-1"
+	crosby.LinkToDepartmentOnFieldDepartment(history)
+
+	loadedHistory := &Department{Name: "History"}
+	loadedHistory.UUID = history.UUID
+
+	err = crosby.UnlinkFromDepartmentOnFieldDepartment(loadedHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if crosby.Department != nil || len(history.Teachers) != 0 {
+		t.Error("the department linked by pointer still has the teacher")
+	}
+}
+
+func TestUnlinkNotLinked(t *testing.T) {
+	compsci, history := &Department{Name: "Compsci"}, &Department{Name: "History"}
+	dataStructures, crosby := &Subject{Name: "dataStructures"}, &Teacher{Name: "Crosby"}
+	eric, cs341 := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0"}
+	history.LinkToSubjectOnFieldSubjects(dataStructures)
+	crosby.LinkToDepartmentOnFieldDepartment(history)
+
+	for _, tt := range []struct {
+		name   string
+		unlink func() error
+	}{
+		{"to-many", func() error { return compsci.UnlinkFromSubjectOnFieldSubjects(dataStructures) }},
+		{"to-one", func() error { return crosby.UnlinkFromDepartmentOnFieldDepartment(compsci) }},
+		{"edge", func() error { return eric.UnlinkFromCourseOfferingOnFieldEnrollments(cs341) }},
+		{"edge by edge", func() error { return eric.UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(NewEnrollment(testDate)) }},
+	} {
+		if err := tt.unlink(); !errors.Is(err, ErrNotLinked) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, ErrNotLinked)
+		}
+	}
+
+	if dataStructures.Department != history || crosby.Department != history {
+		t.Error("a failed unlink changed the nodes")
+	}
+}
+
+func TestLinkErrors(t *testing.T) {
+	eric, cs341 := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0"}
+	compsci, crosby := &Department{Name: "Compsci"}, &Teacher{Name: "Crosby"}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want error
+		// the LinkError the sentinel is wrapped in
+		source, field, relationship string
+		index                       int
+		message                     string
+	}{
+		{
+			name: "nil target", err: crosby.LinkToDepartmentOnFieldDepartment(nil), want: ErrNilTarget,
+			source: "Teacher", field: "Department", relationship: "FOR_DEPARTMENT", index: -1,
+			message: "Teacher.Department [FOR_DEPARTMENT]: target can not be nil",
+		},
+		{
+			name: "nil edge", err: eric.LinkToCourseOfferingOnFieldEnrollments(cs341, nil), want: ErrNilEdge,
+			source: "Student", field: "Enrollments", relationship: "ENROLLED", index: -1,
+			message: "Student.Enrollments [ENROLLED]: edge can not be nil",
+		},
+		{
+			name: "no targets", err: compsci.LinkToTeacherOnFieldTeachers(), want: ErrNoTargets,
+			source: "Department", field: "Teachers", relationship: "FOR_DEPARTMENT", index: -1,
+			message: "Department.Teachers [FOR_DEPARTMENT]: at least one target is required",
+		},
+		{
+			name: "nil variadic target", err: compsci.LinkToTeacherOnFieldTeachers(crosby, nil), want: ErrNilTarget,
+			source: "Department", field: "Teachers", relationship: "FOR_DEPARTMENT", index: 1,
+			message: "Department.Teachers [FOR_DEPARTMENT] target 1: target can not be nil",
+		},
+		{
+			name: "not linked", err: crosby.UnlinkFromDepartmentOnFieldDepartment(compsci), want: ErrNotLinked,
+			source: "Teacher", field: "Department", relationship: "FOR_DEPARTMENT", index: -1,
+			message: "Teacher.Department [FOR_DEPARTMENT]: nodes are not linked",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Fatalf("got %v, want %v", tt.err, tt.want)
+			}
+
+			var linkErr *LinkError
+			if !errors.As(tt.err, &linkErr) {
+				t.Fatalf("%T is not a *LinkError", tt.err)
+			}
+
+			if linkErr.Source != tt.source || linkErr.Field != tt.field || linkErr.Relationship != tt.relationship || linkErr.Index != tt.index {
+				t.Errorf("got %+v, want %s.%s [%s] at %v", linkErr, tt.source, tt.field, tt.relationship, tt.index)
+			}
+
+			if tt.err.Error() != tt.message {
+				t.Errorf("got message %q, want %q", tt.err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestVariadicLinksAreAtomic(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		linked    []string
+		change    func(compsci *Department, teachers map[string]*Teacher) error
+		want      error
+		wantIndex int
+	}{
+		{
+			name: "nil target",
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.LinkToTeacherOnFieldTeachers(teachers["Elias"], nil, teachers["Oates"])
+			},
+			want: ErrNilTarget, wantIndex: 1,
+		},
+		{
+			name:   "already linked",
+			linked: []string{"Oates"},
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.LinkToTeacherOnFieldTeachersWith(&LinkOptions{Duplicates: FailOnDuplicate}, teachers["Elias"], teachers["Oates"])
+			},
+			want: ErrAlreadyLinked, wantIndex: 1,
+		},
+		{
+			name: "repeated target",
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.LinkToTeacherOnFieldTeachersWith(&LinkOptions{Duplicates: FailOnDuplicate}, teachers["Elias"], teachers["Oates"], teachers["Elias"])
+			},
+			want: ErrAlreadyLinked, wantIndex: 2,
+		},
+		{
+			name:   "unlink a target that is not linked",
+			linked: []string{"Elias", "Oates"},
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.UnlinkFromTeacherOnFieldTeachers(teachers["Elias"], teachers["Crosby"])
+			},
+			want: ErrNotLinked, wantIndex: 1,
+		},
+		{
+			name:   "unlink the same target twice",
+			linked: []string{"Elias", "Oates"},
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.UnlinkFromTeacherOnFieldTeachers(teachers["Oates"], teachers["Oates"])
+			},
+			want: ErrNotLinked, wantIndex: 1,
+		},
+		{
+			name:   "unlink a nil target",
+			linked: []string{"Elias"},
+			change: func(compsci *Department, teachers map[string]*Teacher) error {
+				return compsci.UnlinkFromTeacherOnFieldTeachers(teachers["Elias"], nil)
+			},
+			want: ErrNilTarget, wantIndex: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			compsci := &Department{Name: "Compsci"}
+			teachers := map[string]*Teacher{}
+			for _, name := range []string{"Crosby", "Elias", "Oates"} {
+				teachers[name] = &Teacher{Name: name}
+			}
+			for _, name := range tt.linked {
+				compsci.LinkToTeacherOnFieldTeachers(teachers[name])
+			}
+
+			err := tt.change(compsci, teachers)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+
+			var linkErr *LinkError
+			if !errors.As(err, &linkErr) || linkErr.Index != tt.wantIndex {
+				t.Errorf("got %v, want the target at %v to be rejected", err, tt.wantIndex)
+			}
+
+			// the call is all or nothing, the nodes are as they were before
+			if got := teacherNames(compsci.Teachers); !reflect.DeepEqual(got, tt.linked) {
+				t.Errorf("Compsci has %v, want %v", got, tt.linked)
+			}
+			for name, teacher := range teachers {
+				linked := false
+				for _, l := range tt.linked {
+					linked = linked || l == name
+				}
+				if (teacher.Department != nil) != linked {
+					t.Errorf("the department of %s is %v", name, teacher.Department)
+				}
+			}
+		})
+	}
+}
+
+func TestUnlinkEnrollmentEdges(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		unlink  func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error
+		want    error
+		wantCS  []int
+		wantAll int
+	}{
+		{
+			name: "first edge to the offering",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				return eric.UnlinkFromCourseOfferingOnFieldEnrollments(cs341)
+			},
+			wantCS: []int{1, 2}, wantAll: 3,
+		},
+		{
+			name: "by edge",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				return eric.UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(edges[1])
+			},
+			wantCS: []int{0, 2}, wantAll: 3,
+		},
+		{
+			name: "by a copy of the edge",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				edge := &Enrollment{}
+				edge.UUID = edges[2].UUID
+				return eric.UnlinkFromCourseOfferingOnFieldEnrollmentsByEdge(edge)
+			},
+			wantCS: []int{0, 1}, wantAll: 3,
+		},
+		{
+			name: "where dropped",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				return eric.UnlinkFromCourseOfferingOnFieldEnrollmentsWhere(cs341, func(edge *Enrollment) bool {
+					return edge.Status == EnrollmentDropped
+				})
+			},
+			wantCS: []int{2}, wantAll: 2,
+		},
+		{
+			name: "where nothing matches",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				return eric.UnlinkFromCourseOfferingOnFieldEnrollmentsWhere(cs341, func(edge *Enrollment) bool {
+					return edge.Status == EnrollmentWaitlisted
+				})
+			},
+			want: ErrNotLinked, wantCS: []int{0, 1, 2}, wantAll: 4,
+		},
+		{
+			name: "all",
+			unlink: func(eric *Student, cs341 *CourseOffering, edges []*Enrollment) error {
+				return eric.UnlinkAllFromCourseOfferingOnFieldEnrollments(cs341)
+			},
+			wantAll: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			eric, cs341, hist347 := &Student{Name: "eric"}, &CourseOffering{Name: "cs341-0"}, &CourseOffering{Name: "hist347-0"}
+
+			// eric dropped cs341 twice before staying in it
+			var edges []*Enrollment
+			for i, status := range []string{EnrollmentDropped, EnrollmentDropped, EnrollmentEnrolled} {
+				edge := NewEnrollment(testDate.AddDate(0, 0, i))
+				edge.UUID = fmt.Sprintf("enrollment-%v", i)
+				edge.Status = status
+				eric.LinkToCourseOfferingOnFieldEnrollmentsWith(&LinkOptions{Duplicates: AllowDuplicates}, cs341, edge)
+				edges = append(edges, edge)
+			}
+			eric.LinkToCourseOfferingOnFieldEnrollments(hist347, NewEnrollment(testDate))
+
+			err := tt.unlink(eric, cs341, edges)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+
+			var want []*Enrollment
+			for _, i := range tt.wantCS {
+				want = append(want, edges[i])
+			}
+
+			// unlinking swaps in the last edge, so compare them as sets
+			if !sameEnrollments(cs341.Enrollments, want) {
+				t.Errorf("cs341 has %v, want %v", enrollmentIDs(cs341.Enrollments), enrollmentIDs(want))
+			}
+			if len(eric.Enrollments) != tt.wantAll {
+				t.Errorf("eric has %v enrollments, want %v", len(eric.Enrollments), tt.wantAll)
+			}
+			if len(hist347.Enrollments) != 1 {
+				t.Errorf("the enrollment in hist347 was removed")
+			}
+		})
+	}
+}
+
+// sameEnrollments is true when a and b hold the same edges in any order
+func sameEnrollments(a, b []*Enrollment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, edge := range a {
+		if indexOfEnrollment(b, edge) == -1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// enrollmentIDs returns the uuids of edges
+func enrollmentIDs(edges []*Enrollment) []string {
+	var ids []string
+	for _, edge := range edges {
+		ids = append(ids, edge.UUID)
+	}
+	return ids
+}
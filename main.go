@@ -1,29 +1,124 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/mindstand/gogm"
 	"log"
+	"os"
 	"time"
 )
 
+// registeredTypes are the node and edge types of the example, everything that has to be passed to gogm.Init
+var registeredTypes = []interface{}{&Department{}, &Subject{}, &Teacher{}, &Course{}, &Term{}, &CourseOffering{}, &Student{}, &Room{}, &Enrollment{}, &Meeting{}}
+
 func main() {
-	conf := &gogm.Config{
-		Host:      "0.0.0.0",
-		Port:      7687,
-		IsCluster: false,
-		// IsCluster: true, // if we're trying to connect to a casual cluster
-		Username:      "neo4j",
-		Password:      "password",
-		PoolSize:      50,
-		IndexStrategy: gogm.ASSERT_INDEX,
+	// the connection is configured by flags, GOGM_* environment variables and an optional config file,
+	// see config.go. use -cluster or GOGM_IS_CLUSTER=true to connect to a casual cluster
+	loader := NewConfigLoader(flag.CommandLine)
+	printConfig := flag.Bool("print-config", false, "print the effective config with the password redacted and exit")
+	checkSchema := flag.Bool("check-schema", false, "check the gogm tags of the models and exit")
+	memory := flag.Bool("memory", false, "use an in-memory graph instead of neo4j, nothing is kept after the command exits")
+	flag.Usage = func() {
+		printUsage(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "\nflags:")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	// must register each node, including edges in gogm.Init(). Also note you must pass the pointer
-	err := gogm.Init(conf, &Department{}, &Subject{}, &Teacher{}, &Course{}, &Student{}, &Enrollment{})
+	conf, err := loader.Load()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if *printConfig {
+		fmt.Print(FormatConfig(conf))
+		return
+	}
+
+	// every problem with the tags of the models is reported here instead of one at a time by gogm or neo4j
+	err = ValidateSchema(registeredTypes...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *checkSchema {
+		fmt.Printf("the schema of the %v registered types is consistent\n", len(registeredTypes))
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// a diagram of the schema does not need a database
+	var newSession SessionFactory
+	if *memory || !needsGraph(flag.Args()) {
+		store := NewMemoryStore()
+		newSession = func() (gogm.ISession, error) {
+			return store.NewSession(false)
+		}
+	} else {
+		newSession, err = newNeo4jSessions(conf)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	sess, err := newSession()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = newCLI(sess, newSession, os.Stdout).run(flag.Args())
+	sess.Close()
+
+	if errors.Is(err, ErrUsage) {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newNeo4jSessions sets up gogm and returns a factory for sessions against neo4j
+func newNeo4jSessions(conf *gogm.Config) (SessionFactory, error) {
+	// must register each node, including edges in gogm.Init(). Also note you must pass the pointer
+	err := gogm.Init(conf, registeredTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (gogm.ISession, error) {
+		// create a session
+		// setting to false since we're not doing readonly, this is more important for casual clusters than single node clusters
+		return gogm.NewSession(false)
+	}, nil
+}
+
+// exampleTerm is the term the example courses are offered in
+const exampleTerm = "Fall 2026"
+
+// exampleSchool holds the nodes created by seedSchool by name
+type exampleSchool struct {
+	Departments map[string]*Department
+	Subjects    map[string]*Subject
+	Teachers    map[string]*Teacher
+	Courses     map[string]*Course
+	Terms       map[string]*Term
+	Offerings   map[string]*CourseOffering
+	Students    map[string]*Student
+	Rooms       map[string]*Room
+}
+
+// seedSchool builds the school graph, saves it and enrolls the students.
+// it only needs a session, so it can be run against a MemorySession as well as neo4j
+func seedSchool(sess gogm.ISession) (*exampleSchool, error) {
+	repos := NewRepositories(sess)
+
 	// create some teachers
 	crosby, shully, elias, oates := &Teacher{Name: "Crosby"}, &Teacher{Name: "Shully"}, &Teacher{Name: "Elias"}, &Teacher{Name: "Oates"}
 
@@ -33,8 +128,15 @@ func main() {
 	// create some subjects
 	dataStructures, modernHistory, hardPhysics := &Subject{Name: "dataStructures"}, &Subject{Name: "modernHistory"}, &Subject{Name: "hardPhysics"}
 
-	// create some courses
-	cs341_0, cs341_1, hist347, phys122 := &Course{Name: "cs341_0"}, &Course{Name: "cs341_1"}, &Course{Name: "hist347"}, &Course{Name: "phys122"}
+	// create some courses, the catalog only says what a course is about
+	cs341, hist347, phys122 := &Course{Name: "cs341"}, &Course{Name: "hist347"}, &Course{Name: "phys122"}
+
+	// the courses are offered in the fall, cs341 has two sections with different teachers
+	fall := &Term{Name: exampleTerm}
+	cs341_0, _ := NewCourseOffering(cs341, fall, "0")
+	cs341_1, _ := NewCourseOffering(cs341, fall, "1")
+	hist347_0, _ := NewCourseOffering(hist347, fall, "0")
+	phys122_0, _ := NewCourseOffering(phys122, fall, "0")
 
 	// create a few students
 	eric, steven, michael, nikita := &Student{Name: "eric"}, &Student{Name: "steven"}, &Student{Name: "michael"}, &Student{Name: "nikita"}
@@ -50,139 +152,161 @@ func main() {
 	history.LinkToSubjectOnFieldSubjects(modernHistory)
 	physics.LinkToSubjectOnFieldSubjects(hardPhysics)
 
-	// now lets link courses to their subjects and teachers
-	cs341_0.LinkToTeacherOnFieldTeacher(oates)
-	cs341_0.LinkToSubjectOnFieldSubject(dataStructures)
-
-	cs341_1.LinkToTeacherOnFieldTeacher(elias)
-	cs341_1.LinkToSubjectOnFieldSubject(dataStructures)
-
-	hist347.LinkToTeacherOnFieldTeacher(crosby)
+	// now lets link courses to their subjects and the sections to their teachers
+	cs341.LinkToSubjectOnFieldSubject(dataStructures)
 	hist347.LinkToSubjectOnFieldSubject(modernHistory)
-
-	phys122.LinkToTeacherOnFieldTeacher(shully)
 	phys122.LinkToSubjectOnFieldSubject(hardPhysics)
 
-	// lets save and visualize what we have now
-	// create a session
-	// setting to false since we're not doing readonly, this is more important for casual clusters than single node clusters
-	sess, err := gogm.NewSession(false)
-	if err != nil {
-		log.Fatal(err)
+	cs341_0.LinkToTeacherOnFieldTeacher(oates)
+	cs341_1.LinkToTeacherOnFieldTeacher(elias)
+	hist347_0.LinkToTeacherOnFieldTeacher(crosby)
+	phys122_0.LinkToTeacherOnFieldTeacher(shully)
+
+	// the sections meet every week in these rooms, a section can meet in the same room more than once
+	gates, baker := &Room{Name: "Gates 101"}, &Room{Name: "Baker 210"}
+	weekly := &LinkOptions{Duplicates: AllowDuplicates}
+	for _, meeting := range []struct {
+		offering                *CourseOffering
+		room                    *Room
+		day, startTime, endTime string
+	}{
+		{cs341_0, gates, "monday", "09:00", "10:30"},
+		{cs341_0, gates, "wednesday", "09:00", "10:30"},
+		{cs341_1, gates, "monday", "11:00", "12:30"},
+		{cs341_1, gates, "wednesday", "11:00", "12:30"},
+		{hist347_0, baker, "tuesday", "13:00", "14:30"},
+		{hist347_0, baker, "thursday", "13:00", "14:30"},
+		{phys122_0, baker, "friday", "10:00", "12:00"},
+	} {
+		edge, _ := NewMeeting(meeting.day, meeting.startTime, meeting.endTime)
+		meeting.offering.LinkToRoomOnFieldMeetingsWith(weekly, meeting.room, edge)
 	}
 
-	defer sess.Close()
+	offerings := []*CourseOffering{cs341_0, cs341_1, hist347_0, phys122_0}
 
+	// lets save and visualize what we have now
 	// create transaction for saving this
-	err = sess.Begin()
+	err := sess.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// also note we're passing in pointers to save depth
-	// saving depth of 2 to connect everything correctly
-	err = sess.SaveDepth(compsci, 2)
+	// the repositories know how deep each type has to be saved,
+	// departments are saved to a depth of 2 to connect everything correctly
+	err = repos.Departments.Save(compsci)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return nil, sess.RollbackWithError(err)
 	}
 
-	err = sess.SaveDepth(history, 2)
+	err = repos.Departments.Save(history)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return nil, sess.RollbackWithError(err)
 	}
 
-	err = sess.SaveDepth(physics, 2)
+	err = repos.Departments.Save(physics)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return nil, sess.RollbackWithError(err)
+	}
+
+	// offerings save to a depth of one, which connects them to their course, term, teacher and rooms
+	for _, offering := range offerings {
+		err = repos.Offerings.Save(offering)
+		if err != nil {
+			return nil, sess.RollbackWithError(err)
+		}
 	}
 
 	err = sess.Commit()
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return nil, sess.RollbackWithError(err)
 	}
 
-	// now we have all of the teachers, classes, departments and subjects saved.
+	// now we have all of the teachers, classes, departments and subjects saved,
+	// the meetings are saved along with the enrollments of the offerings.
 	// lets assign students to their classes
 
-	eric.LinkToCourseOnFieldEnrollments(cs341_0, &Enrollment{EnrolledDate: time.Now().UTC()})
-	eric.LinkToCourseOnFieldEnrollments(hist347, &Enrollment{EnrolledDate: time.Now().UTC()})
-	eric.LinkToCourseOnFieldEnrollments(phys122, &Enrollment{EnrolledDate: time.Now().UTC()})
+	eric.LinkToCourseOfferingOnFieldEnrollments(cs341_0, NewEnrollment(time.Now().UTC()))
+	eric.LinkToCourseOfferingOnFieldEnrollments(hist347_0, NewEnrollment(time.Now().UTC()))
+	eric.LinkToCourseOfferingOnFieldEnrollments(phys122_0, NewEnrollment(time.Now().UTC()))
 
-	nikita.LinkToCourseOnFieldEnrollments(cs341_1, &Enrollment{EnrolledDate: time.Now().UTC()})
-	nikita.LinkToCourseOnFieldEnrollments(phys122, &Enrollment{EnrolledDate: time.Now().UTC()})
-	nikita.LinkToCourseOnFieldEnrollments(hist347, &Enrollment{EnrolledDate: time.Now().UTC()})
+	nikita.LinkToCourseOfferingOnFieldEnrollments(cs341_1, NewEnrollment(time.Now().UTC()))
+	nikita.LinkToCourseOfferingOnFieldEnrollments(phys122_0, NewEnrollment(time.Now().UTC()))
+	nikita.LinkToCourseOfferingOnFieldEnrollments(hist347_0, NewEnrollment(time.Now().UTC()))
 
-	steven.LinkToCourseOnFieldEnrollments(cs341_0, &Enrollment{EnrolledDate: time.Now().UTC()})
-	steven.LinkToCourseOnFieldEnrollments(hist347, &Enrollment{EnrolledDate: time.Now().UTC()})
+	steven.LinkToCourseOfferingOnFieldEnrollments(cs341_0, NewEnrollment(time.Now().UTC()))
+	steven.LinkToCourseOfferingOnFieldEnrollments(hist347_0, NewEnrollment(time.Now().UTC()))
 
-	michael.LinkToCourseOnFieldEnrollments(phys122, &Enrollment{EnrolledDate: time.Now().UTC()})
-	michael.LinkToCourseOnFieldEnrollments(hist347, &Enrollment{EnrolledDate: time.Now().UTC()})
+	michael.LinkToCourseOfferingOnFieldEnrollments(phys122_0, NewEnrollment(time.Now().UTC()))
+	michael.LinkToCourseOfferingOnFieldEnrollments(hist347_0, NewEnrollment(time.Now().UTC()))
 
 	// now to save these assignments
 	err = sess.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// only saving to a depth of one
-	err = sess.SaveDepth(hist347, 1)
-	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+	for _, offering := range offerings {
+		err = repos.Offerings.Save(offering)
+		if err != nil {
+			return nil, sess.RollbackWithError(err)
+		}
 	}
 
-	err = sess.SaveDepth(cs341_0, 1)
+	err = sess.Commit()
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return nil, sess.RollbackWithError(err)
 	}
 
-	err = sess.SaveDepth(cs341_1, 1)
-	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
-	}
+	return &exampleSchool{
+		Departments: map[string]*Department{compsci.Name: compsci, history.Name: history, physics.Name: physics},
+		Subjects:    map[string]*Subject{dataStructures.Name: dataStructures, modernHistory.Name: modernHistory, hardPhysics.Name: hardPhysics},
+		Teachers:    map[string]*Teacher{crosby.Name: crosby, shully.Name: shully, elias.Name: elias, oates.Name: oates},
+		Courses:     map[string]*Course{cs341.Name: cs341, hist347.Name: hist347, phys122.Name: phys122},
+		Terms:       map[string]*Term{fall.Name: fall},
+		Offerings:   map[string]*CourseOffering{cs341_0.Name: cs341_0, cs341_1.Name: cs341_1, hist347_0.Name: hist347_0, phys122_0.Name: phys122_0},
+		Students:    map[string]*Student{eric.Name: eric, steven.Name: steven, michael.Name: michael, nikita.Name: nikita},
+		Rooms:       map[string]*Room{gates.Name: gates, baker.Name: baker},
+	}, nil
+}
 
-	err = sess.SaveDepth(phys122, 1)
-	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
-	}
+// runExample seeds the school graph and walks through updating, loading and deleting it
+func runExample(sess gogm.ISession) error {
+	repos := NewRepositories(sess)
 
-	err = sess.Commit()
+	school, err := seedSchool(sess)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return err
 	}
 
+	eric, steven, phys122 := school.Students["eric"], school.Students["steven"], school.Offerings["phys122-0 "+exampleTerm]
+
 	// now we have the whole thing setup.
 
 	// say I drop physics, i would do it like the following
-	// gogm stores which relationships it loads nodes with internally so it can figure out if a relationship is removed on save
-
-	err = eric.UnlinkFromCourseOnFieldEnrollments(phys122)
-	if err != nil {
-		log.Fatal(err)
-	}
+	// the enrollment is kept and marked dropped so the registrar still knows I was enrolled
 
 	err = sess.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = sess.SaveDepth(eric, 1)
+	_, err = NewRegistrar(sess).Drop(eric, phys122, time.Now().UTC(), false)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return sess.RollbackWithError(err)
 	}
 
 	err = sess.Commit()
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return sess.RollbackWithError(err)
 	}
 
-	// now im only enrolled in 2 courses
+	// now im only enrolled in 2 courses, phys122 shows up as dropped
 
 	// the following are some examples of how to load data
 	// gogm figures out what kind of node you are looking for internally to generate its queries
-	var allCourses []*Course
-	err = sess.LoadAll(&allCourses)
+	allCourses, err := repos.Courses.List()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	for _, course := range allCourses {
@@ -191,23 +315,25 @@ func main() {
 
 	err = sess.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// heres an example of deleting a node
-	err = sess.Delete(steven)
+	err = repos.Students.Delete(steven)
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return sess.RollbackWithError(err)
 	}
 
 	// we can also delete by uuid
 	//err = sess.DeleteUUID(steven.UUID)
 	//if err != nil {
-	//	log.Fatal(sess.RollbackWithError(err))
+	//	return sess.RollbackWithError(err)
 	//}
 
 	err = sess.Commit()
 	if err != nil {
-		log.Fatal(sess.RollbackWithError(err))
+		return sess.RollbackWithError(err)
 	}
+
+	return nil
 }
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"errors"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testDate is the first day of the example term, tests enroll and drop relative to it
+var testDate = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestSession returns a session on an empty in-memory store
+func newTestSession(t *testing.T) *MemorySession {
+	t.Helper()
+
+	sess, err := NewMemoryStore().NewSession(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sess
+}
+
+// seedTestSchool seeds the example school into a new in-memory store
+func seedTestSchool(t *testing.T) (*MemorySession, *exampleSchool) {
+	t.Helper()
+
+	sess := newTestSession(t)
+	school, err := seedSchool(sess)
+	if err != nil {
+		t.Fatalf("seeding the school: %v", err)
+	}
+
+	return sess, school
+}
+
+// countNodes counts the committed nodes of the type of node
+func countNodes(sess *MemorySession, node interface{}) int {
+	typ := reflect.TypeOf(node).Elem()
+
+	count := 0
+	for _, stored := range sess.read().nodes {
+		if stored.Value.Type() == typ {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestSeedSchoolSavesNodes(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+
+	for _, tt := range []struct {
+		node interface{}
+		want int
+	}{
+		{&Department{}, 3},
+		{&Subject{}, 3},
+		{&Teacher{}, 4},
+		{&Course{}, 3},
+		{&Term{}, 1},
+		{&CourseOffering{}, 4},
+		{&Student{}, 4},
+		{&Room{}, 2},
+	} {
+		if got := countNodes(sess, tt.node); got != tt.want {
+			t.Errorf("%T: got %v nodes, want %v", tt.node, got, tt.want)
+		}
+	}
+}
+
+func TestSeedSchoolRelationshipDirections(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+	g := sess.read()
+
+	for _, tt := range []struct {
+		relationship string
+		start, end   interface{}
+		want         int
+	}{
+		{"CURRICULUM", &Department{}, &Subject{}, 3},
+		{"FOR_DEPARTMENT", &Teacher{}, &Department{}, 4},
+		{"SUBJECT_TAUGHT", &Course{}, &Subject{}, 3},
+		{"OFFERING_OF", &CourseOffering{}, &Course{}, 4},
+		{"OFFERED_IN", &CourseOffering{}, &Term{}, 4},
+		{"TEACHES_CLASS", &Teacher{}, &CourseOffering{}, 4},
+		{"ENROLLED", &Student{}, &CourseOffering{}, 10},
+		{"MEETS_IN", &CourseOffering{}, &Room{}, 7},
+	} {
+		t.Run(tt.relationship, func(t *testing.T) {
+			start, end := reflect.TypeOf(tt.start).Elem(), reflect.TypeOf(tt.end).Elem()
+
+			count := 0
+			for _, rel := range g.rels {
+				if rel.Type != tt.relationship {
+					continue
+				}
+				count++
+
+				if got := g.typeOf(rel.StartUUID); got != start {
+					t.Errorf("starts at %v, want %v", got, start)
+				}
+				if got := g.typeOf(rel.EndUUID); got != end {
+					t.Errorf("ends at %v, want %v", got, end)
+				}
+			}
+
+			if count != tt.want {
+				t.Errorf("got %v relationships, want %v", count, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeedSchoolLoadsBothEnds(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	eric, err := repos.Students.FindByName("eric")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(eric.Enrollments) != 3 {
+		t.Fatalf("eric has %v enrollments, want 3", len(eric.Enrollments))
+	}
+
+	for _, enrollment := range eric.Enrollments {
+		if enrollment.Start != eric {
+			t.Errorf("enrollment in %s does not start at eric", enrollment.End.Name)
+		}
+		if _, ok := school.Offerings[enrollment.End.Name]; !ok {
+			t.Errorf("eric is enrolled in %q which was not seeded", enrollment.End.Name)
+		}
+	}
+
+	oates, err := repos.Teachers.FindByName("Oates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if oates.Department == nil || oates.Department.Name != "Compsci" {
+		t.Errorf("Oates is in %+v, want Compsci", oates.Department)
+	}
+}
+
+func TestRunExample(t *testing.T) {
+	sess := newTestSession(t)
+
+	err := runExample(sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos := NewRepositories(sess)
+
+	_, err = repos.Students.FindByName("steven")
+	if !errors.Is(err, gogm.ErrNotFound) {
+		t.Errorf("steven was not deleted, got %v", err)
+	}
+
+	eric, err := repos.Students.FindByName("eric")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]string{}
+	for _, enrollment := range eric.Enrollments {
+		statuses[enrollment.End.Name] = enrollment.CurrentStatus()
+	}
+
+	want := map[string]string{
+		"cs341-0 " + exampleTerm:   EnrollmentEnrolled,
+		"hist347-0 " + exampleTerm: EnrollmentEnrolled,
+		"phys122-0 " + exampleTerm: EnrollmentDropped,
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("got enrollments %v, want %v", statuses, want)
+	}
+}
+
+func TestSaveDepthCutoff(t *testing.T) {
+	for _, tt := range []struct {
+		depth     int
+		wantNodes []string
+		wantRels  int
+	}{
+		{0, []string{"Compsci"}, 0},
+		{1, []string{"Compsci", "dataStructures"}, 1},
+		{2, []string{"Compsci", "dataStructures", "cs341"}, 2},
+	} {
+		sess := newTestSession(t)
+
+		compsci, dataStructures, cs341 := &Department{Name: "Compsci"}, &Subject{Name: "dataStructures"}, &Course{Name: "cs341"}
+		compsci.LinkToSubjectOnFieldSubjects(dataStructures)
+		cs341.LinkToSubjectOnFieldSubject(dataStructures)
+
+		err := sess.SaveDepth(compsci, tt.depth)
+		if err != nil {
+			t.Fatalf("depth %v: %v", tt.depth, err)
+		}
+
+		g := sess.read()
+
+		var names []string
+		for _, node := range []interface{}{compsci, dataStructures, cs341} {
+			base := baseNodeOf(reflect.ValueOf(node).Elem())
+			if stored, ok := g.nodes[base.UUID]; ok {
+				names = append(names, stored.Value.FieldByName("Name").String())
+			}
+		}
+
+		if !reflect.DeepEqual(names, tt.wantNodes) {
+			t.Errorf("depth %v: saved %v, want %v", tt.depth, names, tt.wantNodes)
+		}
+
+		if len(g.rels) != tt.wantRels {
+			t.Errorf("depth %v: saved %v relationships, want %v", tt.depth, len(g.rels), tt.wantRels)
+		}
+	}
+}
+
+func TestSaveKeepsRelationshipsNotLoaded(t *testing.T) {
+	sess, school := seedTestSchool(t)
+
+	// the subjects and teachers are loaded without their courses and offerings
+	var compsci Department
+	err := sess.LoadDepth(&compsci, school.Departments["Compsci"].UUID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compsci.Name = "Computer Science"
+	err = compsci.UnlinkFromTeacherOnFieldTeachers(compsci.Teachers[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sess.SaveDepth(&compsci, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		node  interface{}
+		id    string
+		field string
+		want  int
+	}{
+		{&Department{}, compsci.UUID, "Teachers", 1},
+		{&Subject{}, school.Subjects["dataStructures"].UUID, "Courses", 1},
+		{&Teacher{}, school.Teachers["Elias"].UUID, "Offerings", 1},
+		{&Teacher{}, school.Teachers["Oates"].UUID, "Offerings", 1},
+	} {
+		err := sess.LoadDepth(tt.node, tt.id, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := reflect.ValueOf(tt.node).Elem()
+		if n := got.FieldByName(tt.field).Len(); n != tt.want {
+			t.Errorf("%s has %v %s after saving the department, want %v", got.FieldByName("Name"), n, tt.field, tt.want)
+		}
+	}
+
+	// the unlinked teacher lost the department it was loaded with
+	var oates Teacher
+	err = sess.LoadDepth(&oates, school.Teachers["Oates"].UUID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oates.Department != nil {
+		t.Errorf("Oates is still in %s", oates.Department.Name)
+	}
+}
+
+func TestSaveDepthLimits(t *testing.T) {
+	sess := newTestSession(t)
+
+	for _, tt := range []struct {
+		name  string
+		obj   interface{}
+		depth int
+		want  error
+	}{
+		{"negative depth", &Student{Name: "eric"}, -1, gogm.ErrInvalidParams},
+		{"too deep", &Student{Name: "eric"}, memoryMaxSaveDepth + 1, gogm.ErrConfiguration},
+		{"not a pointer", Student{Name: "eric"}, 1, gogm.ErrInvalidParams},
+	} {
+		err := sess.SaveDepth(tt.obj, tt.depth)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	err := sess.SaveDepth(NewEnrollment(testDate), 1)
+	if err == nil {
+		t.Error("saving an edge directly succeeded")
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	original := errors.New("original")
+
+	for _, tt := range []struct {
+		name      string
+		end       func(sess *MemorySession) error
+		wantErr   error
+		wantSaved bool
+	}{
+		{"commit", (*MemorySession).Commit, nil, true},
+		{"rollback", (*MemorySession).Rollback, nil, false},
+		{"rollback with error", func(sess *MemorySession) error {
+			return sess.RollbackWithError(original)
+		}, original, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			sess, _ := store.NewSession(false)
+			other, _ := store.NewSession(true)
+
+			err := sess.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			student := &Student{Name: "eric"}
+			err = sess.Save(student)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the write is only visible inside the transaction until it is committed
+			var loaded Student
+			if err := sess.Load(&loaded, student.UUID); err != nil {
+				t.Errorf("the transaction can not see its own write: %v", err)
+			}
+			if err := other.Load(&loaded, student.UUID); !errors.Is(err, gogm.ErrNotFound) {
+				t.Errorf("another session sees the uncommitted write, got %v", err)
+			}
+
+			err = tt.end(sess)
+			if err != tt.wantErr {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+
+			err = other.Load(&loaded, student.UUID)
+			if saved := err == nil; saved != tt.wantSaved {
+				t.Errorf("saved is %v, want %v, load returned %v", saved, tt.wantSaved, err)
+			}
+
+			if err := sess.Commit(); !errors.Is(err, gogm.ErrTransaction) {
+				t.Errorf("committing without a transaction got %v, want %v", err, gogm.ErrTransaction)
+			}
+		})
+	}
+}
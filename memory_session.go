@@ -0,0 +1,985 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// mirrors the limits gogm enforces on save
+const (
+	memoryDefaultDepth = 1
+	memoryMaxSaveDepth = 10
+)
+
+// MemoryStore is an in-memory stand in for a neo4j database. Sessions created from the same store
+// see each others committed writes, which makes it possible to run the example without a bolt endpoint.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	graph *memoryGraph
+
+	// txLock serializes write transactions across every session of the store
+	txLock sync.Mutex
+}
+
+// NewMemoryStore creates an empty in-memory database
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		graph: newMemoryGraph(),
+	}
+}
+
+// NewSession mirrors gogm.NewSession for the in-memory database
+func (m *MemoryStore) NewSession(readonly bool) (*MemorySession, error) {
+	return &MemorySession{
+		store:        m,
+		readOnly:     readonly,
+		DefaultDepth: memoryDefaultDepth,
+	}, nil
+}
+
+// committed returns the last committed graph. Committed graphs are never modified in place.
+func (m *MemoryStore) committed() *memoryGraph {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.graph
+}
+
+func (m *MemoryStore) commit(g *memoryGraph) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.graph = g
+}
+
+// memoryNode is a stored copy of a node with its relationship fields cleared
+type memoryNode struct {
+	Value reflect.Value
+}
+
+func (n *memoryNode) schema() *nodeSchema {
+	schema, _ := schemaFor(n.Value.Type())
+	return schema
+}
+
+// memoryRelationship is a directed relationship between two stored nodes
+type memoryRelationship struct {
+	Type      string
+	StartUUID string
+	EndUUID   string
+
+	// Edge holds a copy of the special edge struct with Start and End cleared, invalid for plain relationships
+	Edge reflect.Value
+}
+
+type memoryGraph struct {
+	nodes  map[string]*memoryNode
+	rels   []*memoryRelationship
+	lastId int64
+}
+
+func newMemoryGraph() *memoryGraph {
+	return &memoryGraph{
+		nodes: map[string]*memoryNode{},
+	}
+}
+
+func (g *memoryGraph) clone() *memoryGraph {
+	c := &memoryGraph{
+		nodes:  make(map[string]*memoryNode, len(g.nodes)),
+		rels:   make([]*memoryRelationship, 0, len(g.rels)),
+		lastId: g.lastId,
+	}
+
+	for id, node := range g.nodes {
+		c.nodes[id] = &memoryNode{Value: copyStored(node.Value)}
+	}
+
+	for _, rel := range g.rels {
+		cp := *rel
+		if rel.Edge.IsValid() {
+			cp.Edge = copyStored(rel.Edge)
+		}
+		c.rels = append(c.rels, &cp)
+	}
+
+	return c
+}
+
+// typeOf returns the struct type of the stored node, nil if it does not exist
+func (g *memoryGraph) typeOf(id string) reflect.Type {
+	node, ok := g.nodes[id]
+	if !ok {
+		return nil
+	}
+
+	return node.Value.Type()
+}
+
+func (g *memoryGraph) belongsTo(rel *memoryRelationship, id string, field *relationshipField) bool {
+	if rel.Type != field.Relationship {
+		return false
+	}
+
+	if field.Direction != directionIncoming && rel.StartUUID == id && g.typeOf(rel.EndUUID) == field.Target {
+		return true
+	}
+
+	return field.Direction != directionOutgoing && rel.EndUUID == id && g.typeOf(rel.StartUUID) == field.Target
+}
+
+// otherEnd returns the uuid of the node rel connects the node with uuid id to
+func otherEnd(rel *memoryRelationship, id string) string {
+	if rel.StartUUID == id {
+		return rel.EndUUID
+	}
+
+	return rel.StartUUID
+}
+
+// replaceRelationships stores rels for the field of the node with uuid id. Like gogm's calculateDels, only the
+// relationships to the nodes in loaded are removed when missing from rels, the rest of the field is left alone.
+func (g *memoryGraph) replaceRelationships(id string, field *relationshipField, rels []*memoryRelationship, loaded map[int64]bool) {
+	current := map[string]bool{}
+	for _, rel := range rels {
+		if rel.Edge.IsValid() {
+			current[baseNodeOf(rel.Edge).UUID] = true
+		} else {
+			current[otherEnd(rel, id)] = true
+		}
+	}
+
+	kept := g.rels[:0]
+	for _, rel := range g.rels {
+		if !g.belongsTo(rel, id, field) {
+			kept = append(kept, rel)
+			continue
+		}
+
+		other := otherEnd(rel, id)
+		if loaded[baseNodeOf(g.nodes[other].Value).Id] {
+			continue
+		}
+
+		// relationships being saved again are replaced rather than duplicated
+		if rel.Edge.IsValid() && current[baseNodeOf(rel.Edge).UUID] || !rel.Edge.IsValid() && current[other] {
+			continue
+		}
+
+		kept = append(kept, rel)
+	}
+
+	g.rels = append(kept, rels...)
+}
+
+// loadedIds returns the graph ids the field of node was loaded with
+func loadedIds(node *gogm.BaseNode, field *relationshipField) map[int64]bool {
+	ids := map[int64]bool{}
+	if conf, ok := node.LoadMap[field.FieldName]; ok {
+		for _, id := range conf.Ids {
+			ids[id] = true
+		}
+	}
+
+	return ids
+}
+
+// recordLoaded adds the node with graph id other to the entry of the field in loadMap
+func recordLoaded(loadMap map[string]*gogm.RelationConfig, field *relationshipField, other int64) {
+	conf, ok := loadMap[field.FieldName]
+	if !ok {
+		conf = &gogm.RelationConfig{RelationType: gogm.Single}
+		if field.Many {
+			conf.RelationType = gogm.Multi
+		}
+		loadMap[field.FieldName] = conf
+	}
+
+	conf.Ids = append(conf.Ids, other)
+}
+
+func (g *memoryGraph) deleteNode(id string) {
+	delete(g.nodes, id)
+
+	kept := g.rels[:0]
+	for _, rel := range g.rels {
+		if rel.StartUUID != id && rel.EndUUID != id {
+			kept = append(kept, rel)
+		}
+	}
+	g.rels = kept
+}
+
+// deleteEdge removes the relationship backed by the special edge with uuid id
+func (g *memoryGraph) deleteEdge(id string) bool {
+	for i, rel := range g.rels {
+		if rel.Edge.IsValid() && baseNodeOf(rel.Edge).UUID == id {
+			g.rels = append(g.rels[:i], g.rels[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// MemorySession implements gogm.ISession on top of a MemoryStore. Loads and saves follow the relationship
+// directions declared in the gogm struct tags. Loads record the relationships of each node in its LoadMap the
+// way gogm does. Saving a node to a depth greater than its distance from the saved object removes the loaded
+// relationships missing from its fields, relationships that were never loaded are kept.
+type MemorySession struct {
+	store    *MemoryStore
+	readOnly bool
+	tx       *memoryGraph
+
+	DefaultDepth int
+}
+
+var _ gogm.ISession = &MemorySession{}
+
+// Begin begins transaction
+func (s *MemorySession) Begin() error {
+	if s.tx != nil {
+		return fmt.Errorf("transaction already started: %w", gogm.ErrTransaction)
+	}
+
+	s.store.txLock.Lock()
+	s.tx = s.store.committed().clone()
+	return nil
+}
+
+// Rollback rolls back transaction
+func (s *MemorySession) Rollback() error {
+	if s.tx == nil {
+		return fmt.Errorf("cannot Rollback nil transaction: %w", gogm.ErrTransaction)
+	}
+
+	s.tx = nil
+	s.store.txLock.Unlock()
+	return nil
+}
+
+// RollbackWithError wraps original error into rollback error if there is one
+func (s *MemorySession) RollbackWithError(originalError error) error {
+	err := s.Rollback()
+	if err != nil {
+		return fmt.Errorf("original error: `%s`, rollback error: `%s`", originalError.Error(), err.Error())
+	}
+
+	return originalError
+}
+
+// Commit commits transaction
+func (s *MemorySession) Commit() error {
+	if s.tx == nil {
+		return fmt.Errorf("cannot commit nil transaction: %w", gogm.ErrTransaction)
+	}
+
+	s.store.commit(s.tx)
+	s.tx = nil
+	s.store.txLock.Unlock()
+	return nil
+}
+
+// Close rolls back any open transaction
+func (s *MemorySession) Close() error {
+	if s.tx != nil {
+		return s.Rollback()
+	}
+
+	return nil
+}
+
+// read returns the graph visible to this session
+func (s *MemorySession) read() *memoryGraph {
+	if s.tx != nil {
+		return s.tx
+	}
+
+	return s.store.committed()
+}
+
+// write runs fn in the open transaction, or in its own transaction if none is open
+func (s *MemorySession) write(fn func(g *memoryGraph) error) error {
+	if s.readOnly {
+		return fmt.Errorf("can not write in a readonly session: %w", gogm.ErrInvalidParams)
+	}
+
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+
+	s.store.txLock.Lock()
+	defer s.store.txLock.Unlock()
+
+	g := s.store.committed().clone()
+	err := fn(g)
+	if err != nil {
+		return err
+	}
+
+	s.store.commit(g)
+	return nil
+}
+
+func (s *MemorySession) Load(respObj interface{}, id string) error {
+	return s.LoadDepthFilterPagination(respObj, id, s.DefaultDepth, nil, nil, nil)
+}
+
+func (s *MemorySession) LoadDepth(respObj interface{}, id string, depth int) error {
+	return s.LoadDepthFilterPagination(respObj, id, depth, nil, nil, nil)
+}
+
+func (s *MemorySession) LoadDepthFilter(respObj interface{}, id string, depth int, filter *dsl.ConditionBuilder, params map[string]interface{}) error {
+	if filter == nil {
+		return s.LoadDepthFilterPagination(respObj, id, depth, nil, params, nil)
+	}
+
+	return s.LoadDepthFilterPagination(respObj, id, depth, filter, params, nil)
+}
+
+func (s *MemorySession) LoadDepthFilterPagination(respObj interface{}, id string, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *gogm.Pagination) error {
+	respVal := reflect.ValueOf(respObj)
+	if respVal.Kind() != reflect.Ptr || respVal.Elem().Kind() != reflect.Struct {
+		return errors.New("respObj must be type ptr")
+	}
+
+	if pagination != nil {
+		if err := pagination.Validate(); err != nil {
+			return err
+		}
+	}
+
+	g := s.read()
+
+	node, ok := g.nodes[id]
+	if !ok || node.Value.Type() != respVal.Elem().Type() {
+		return fmt.Errorf("no %s with uuid [%s], %w", respVal.Elem().Type().Name(), id, gogm.ErrNotFound)
+	}
+
+	if filter != nil {
+		match, err := memoryFilterMatches(filter, params, node)
+		if err != nil {
+			return err
+		}
+
+		if !match {
+			return fmt.Errorf("no %s with uuid [%s] matches filter, %w", respVal.Elem().Type().Name(), id, gogm.ErrNotFound)
+		}
+	}
+
+	g.materialize(depth, map[string]reflect.Value{id: respVal}, id)
+	return nil
+}
+
+func (s *MemorySession) LoadAll(respObj interface{}) error {
+	return s.LoadAllDepthFilterPagination(respObj, s.DefaultDepth, nil, nil, nil)
+}
+
+func (s *MemorySession) LoadAllDepth(respObj interface{}, depth int) error {
+	return s.LoadAllDepthFilterPagination(respObj, depth, nil, nil, nil)
+}
+
+func (s *MemorySession) LoadAllDepthFilter(respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}) error {
+	return s.LoadAllDepthFilterPagination(respObj, depth, filter, params, nil)
+}
+
+func (s *MemorySession) LoadAllDepthFilterPagination(respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *gogm.Pagination) error {
+	rawRespType := reflect.TypeOf(respObj)
+	if rawRespType == nil || rawRespType.Kind() != reflect.Ptr {
+		return fmt.Errorf("respObj must be a pointer to a slice, instead it is %T", respObj)
+	}
+
+	sliceType := rawRespType.Elem()
+	if sliceType.Kind() != reflect.Slice {
+		return fmt.Errorf("respObj must be type slice, instead it is %T", respObj)
+	}
+
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	g := s.read()
+
+	var matched []*memoryNode
+	for _, node := range g.nodes {
+		if node.Value.Type() != elemType {
+			continue
+		}
+
+		if filter != nil {
+			match, err := memoryFilterMatches(filter, params, node)
+			if err != nil {
+				return err
+			}
+
+			if !match {
+				continue
+			}
+		}
+
+		matched = append(matched, node)
+	}
+
+	// keep results in insertion order so loads are deterministic
+	sort.Slice(matched, func(i, j int) bool {
+		return baseNodeOf(matched[i].Value).Id < baseNodeOf(matched[j].Value).Id
+	})
+
+	if pagination != nil {
+		var err error
+		matched, err = memoryPaginate(matched, pagination)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(matched) == 0 {
+		return fmt.Errorf("no %s found, %w", elemType.Name(), gogm.ErrNotFound)
+	}
+
+	ids := make([]string, len(matched))
+	for i, node := range matched {
+		ids[i] = baseNodeOf(node.Value).UUID
+	}
+
+	objs := g.materialize(depth, map[string]reflect.Value{}, ids...)
+
+	result := reflect.MakeSlice(sliceType, 0, len(ids))
+	for _, id := range ids {
+		if isPtr {
+			result = reflect.Append(result, objs[id])
+		} else {
+			result = reflect.Append(result, objs[id].Elem())
+		}
+	}
+
+	reflect.ValueOf(respObj).Elem().Set(result)
+	return nil
+}
+
+func (s *MemorySession) LoadAllEdgeConstraint(respObj interface{}, endNodeType, endNodeField string, edgeConstraint interface{}, minJumps, maxJumps, depth int, filter dsl.ConditionOperator) error {
+	return errors.New("memory session does not support edge constraint loads")
+}
+
+func (s *MemorySession) Save(saveObj interface{}) error {
+	return s.SaveDepth(saveObj, s.DefaultDepth)
+}
+
+func (s *MemorySession) SaveDepth(saveObj interface{}, depth int) error {
+	saveVal := reflect.ValueOf(saveObj)
+	if saveVal.Kind() != reflect.Ptr || saveVal.IsNil() || saveVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("saveObj must be a non nil pointer to a struct, %w", gogm.ErrInvalidParams)
+	}
+
+	if depth < 0 {
+		return fmt.Errorf("depth can not be less than 0, %w", gogm.ErrInvalidParams)
+	}
+
+	if depth > memoryMaxSaveDepth {
+		return fmt.Errorf("saving depth of (%v) is currently not supported, maximum depth is (%v), %w", depth, memoryMaxSaveDepth, gogm.ErrConfiguration)
+	}
+
+	return s.write(func(g *memoryGraph) error {
+		return g.save(saveVal, depth)
+	})
+}
+
+func (s *MemorySession) Delete(deleteObj interface{}) error {
+	if deleteObj == nil {
+		return errors.New("deleteObj can not be nil")
+	}
+
+	delVal := reflect.ValueOf(deleteObj)
+	var vals []reflect.Value
+
+	switch delVal.Kind() {
+	case reflect.Ptr:
+		vals = append(vals, delVal.Elem())
+	case reflect.Slice:
+		for i := 0; i < delVal.Len(); i++ {
+			val := delVal.Index(i)
+			if val.Kind() == reflect.Ptr {
+				val = val.Elem()
+			}
+			vals = append(vals, val)
+		}
+	default:
+		return errors.New("delete obj can only be ptr or slice")
+	}
+
+	var uuids []string
+	for _, val := range vals {
+		base := baseNodeOf(val)
+		if base == nil {
+			return fmt.Errorf("unable to delete [%s], it does not embed gogm.BaseNode", val.Type())
+		}
+		uuids = append(uuids, base.UUID)
+	}
+
+	return s.write(func(g *memoryGraph) error {
+		for _, id := range uuids {
+			if !g.deleteEdge(id) {
+				g.deleteNode(id)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *MemorySession) DeleteUUID(uuid string) error {
+	return s.write(func(g *memoryGraph) error {
+		if !g.deleteEdge(uuid) {
+			g.deleteNode(uuid)
+		}
+		return nil
+	})
+}
+
+func (s *MemorySession) Query(query string, properties map[string]interface{}, respObj interface{}) error {
+	return errors.New("memory session can not run cypher queries")
+}
+
+func (s *MemorySession) QueryRaw(query string, properties map[string]interface{}) ([][]interface{}, error) {
+	return nil, errors.New("memory session can not run cypher queries")
+}
+
+func (s *MemorySession) PurgeDatabase() error {
+	return s.write(func(g *memoryGraph) error {
+		*g = *newMemoryGraph()
+		return nil
+	})
+}
+
+// save stores the node behind saveVal and everything reachable from it within depth
+func (g *memoryGraph) save(saveVal reflect.Value, depth int) error {
+	type queued struct {
+		val   reflect.Value
+		depth int
+	}
+
+	visited := map[uintptr]bool{saveVal.Pointer(): true}
+	queue := []queued{{val: saveVal, depth: 0}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		schema, err := schemaFor(cur.val.Type())
+		if err != nil {
+			return err
+		}
+
+		if schema.IsEdge {
+			return fmt.Errorf("can not save edge [%s] directly, save one of its nodes instead", schema.Label)
+		}
+
+		id := g.identify(cur.val)
+		g.nodes[id] = &memoryNode{Value: copyStored(cur.val.Elem())}
+
+		if cur.depth >= depth {
+			continue
+		}
+
+		base := baseNodeOf(cur.val)
+		loadMap := map[string]*gogm.RelationConfig{}
+
+		for _, field := range schema.Relationships {
+			var rels []*memoryRelationship
+
+			for _, target := range relationshipTargets(cur.val.Elem(), field) {
+				other := target
+				rel := &memoryRelationship{Type: field.Relationship}
+
+				if field.Edge != nil {
+					edge := target.Interface().(gogm.IEdge)
+					if field.Direction == directionIncoming {
+						other = reflect.ValueOf(edge.GetStartNode())
+					} else {
+						other = reflect.ValueOf(edge.GetEndNode())
+					}
+
+					if !other.IsValid() || other.IsNil() {
+						return fmt.Errorf("edge on field %s.%s is missing a node", schema.Label, field.FieldName)
+					}
+
+					g.identify(target)
+					rel.Edge = copyStored(target.Elem())
+				}
+
+				otherId := g.identify(other)
+				recordLoaded(loadMap, field, baseNodeOf(other).Id)
+
+				if field.Direction == directionIncoming {
+					rel.StartUUID, rel.EndUUID = otherId, id
+				} else {
+					rel.StartUUID, rel.EndUUID = id, otherId
+				}
+				rels = append(rels, rel)
+
+				if !visited[other.Pointer()] {
+					visited[other.Pointer()] = true
+					queue = append(queue, queued{val: other, depth: cur.depth + 1})
+				}
+			}
+
+			// targets may not be stored yet, store a placeholder so direction checks can see their type
+			for _, rel := range rels {
+				for _, relId := range []string{rel.StartUUID, rel.EndUUID} {
+					if _, ok := g.nodes[relId]; !ok {
+						g.nodes[relId] = &memoryNode{Value: reflect.New(field.Target).Elem()}
+					}
+				}
+			}
+
+			g.replaceRelationships(id, field, rels, loadedIds(base, field))
+		}
+
+		// the saved fields are what the graph holds now, as if the node had been loaded again
+		base.LoadMap = loadMap
+	}
+
+	return nil
+}
+
+// identify makes sure the node or edge behind ptr has a uuid and graph id, returning the uuid
+func (g *memoryGraph) identify(ptr reflect.Value) string {
+	base := baseNodeOf(ptr.Elem())
+
+	if base.UUID == "" {
+		base.UUID = uuid.New().String()
+	}
+
+	if existing, ok := g.nodes[base.UUID]; ok && baseNodeOf(existing.Value).Id != 0 {
+		base.Id = baseNodeOf(existing.Value).Id
+	} else if base.Id == 0 {
+		g.lastId++
+		base.Id = g.lastId
+	}
+
+	return base.UUID
+}
+
+// materialize builds fresh objects for the nodes with the given uuids and everything within depth of them.
+// objs can be seeded with pointers that should be filled in instead of allocated.
+func (g *memoryGraph) materialize(depth int, objs map[string]reflect.Value, ids ...string) map[string]reflect.Value {
+	distance := map[string]int{}
+	var queue []string
+
+	get := func(id string) {
+		if _, ok := objs[id]; !ok {
+			objs[id] = reflect.New(g.nodes[id].Value.Type())
+		}
+		objs[id].Elem().Set(copyStored(g.nodes[id].Value))
+	}
+
+	for _, id := range ids {
+		get(id)
+		distance[id] = 0
+		queue = append(queue, id)
+	}
+
+	traversed := map[*memoryRelationship]bool{}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if distance[id] >= depth {
+			continue
+		}
+
+		for _, rel := range g.rels {
+			var other string
+			switch id {
+			case rel.StartUUID:
+				other = rel.EndUUID
+			case rel.EndUUID:
+				other = rel.StartUUID
+			default:
+				continue
+			}
+
+			traversed[rel] = true
+			if _, ok := distance[other]; !ok {
+				get(other)
+				distance[other] = distance[id] + 1
+				queue = append(queue, other)
+			}
+		}
+	}
+
+	// every materialized node starts with an empty LoadMap, filled in as its relationships are wired
+	for _, obj := range objs {
+		baseNodeOf(obj).LoadMap = map[string]*gogm.RelationConfig{}
+	}
+
+	// wire in stored order so slices come back the way they were saved
+	for _, rel := range g.rels {
+		if traversed[rel] {
+			wireRelationship(rel, objs[rel.StartUUID], objs[rel.EndUUID])
+		}
+	}
+
+	return objs
+}
+
+// wireRelationship sets the relationship fields on both nodes of rel
+func wireRelationship(rel *memoryRelationship, start, end reflect.Value) {
+	startSchema, _ := schemaFor(start.Type())
+	endSchema, _ := schemaFor(end.Type())
+
+	startField := startSchema.relationshipTo(rel.Type, directionOutgoing, end.Type().Elem())
+	endField := endSchema.relationshipTo(rel.Type, directionIncoming, start.Type().Elem())
+
+	startTarget, endTarget := end, start
+	if rel.Edge.IsValid() {
+		edge := reflect.New(rel.Edge.Type())
+		edge.Elem().Set(copyStored(rel.Edge))
+
+		iedge := edge.Interface().(gogm.IEdge)
+		_ = iedge.SetStartNode(start.Interface())
+		_ = iedge.SetEndNode(end.Interface())
+
+		startTarget, endTarget = edge, edge
+	}
+
+	if startField != nil {
+		setRelationship(start.Elem(), startField, startTarget)
+		recordLoaded(baseNodeOf(start).LoadMap, startField, baseNodeOf(end).Id)
+	}
+
+	if endField != nil {
+		setRelationship(end.Elem(), endField, endTarget)
+		recordLoaded(baseNodeOf(end).LoadMap, endField, baseNodeOf(start).Id)
+	}
+}
+
+func setRelationship(node reflect.Value, field *relationshipField, target reflect.Value) {
+	fieldVal := node.FieldByIndex(field.Index)
+	if field.Many {
+		fieldVal.Set(reflect.Append(fieldVal, target))
+	} else {
+		fieldVal.Set(target)
+	}
+}
+
+// relationshipTargets returns the non nil pointers held by a relationship field
+func relationshipTargets(node reflect.Value, field *relationshipField) []reflect.Value {
+	fieldVal := node.FieldByIndex(field.Index)
+
+	if !field.Many {
+		if fieldVal.IsNil() {
+			return nil
+		}
+		return []reflect.Value{fieldVal}
+	}
+
+	var targets []reflect.Value
+	for i := 0; i < fieldVal.Len(); i++ {
+		if !fieldVal.Index(i).IsNil() {
+			targets = append(targets, fieldVal.Index(i))
+		}
+	}
+
+	return targets
+}
+
+// copyStored copies a node or edge struct, dropping everything gogm does not store as a property
+func copyStored(val reflect.Value) reflect.Value {
+	schema, _ := schemaFor(val.Type())
+
+	cp := reflect.New(val.Type()).Elem()
+	for _, prop := range schema.Properties {
+		src := val.FieldByIndex(prop.Index)
+		if prop.Properties && !src.IsNil() {
+			props := reflect.MakeMapWithSize(src.Type(), src.Len())
+			for _, key := range src.MapKeys() {
+				props.SetMapIndex(key, src.MapIndex(key))
+			}
+			cp.FieldByIndex(prop.Index).Set(props)
+			continue
+		}
+
+		cp.FieldByIndex(prop.Index).Set(src)
+	}
+
+	return cp
+}
+
+// baseNodeOf returns the embedded gogm.BaseNode of a node or edge struct
+func baseNodeOf(val reflect.Value) *gogm.BaseNode {
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	base := val.FieldByName("BaseNode")
+	if !base.IsValid() || !base.CanAddr() {
+		return nil
+	}
+
+	return base.Addr().Interface().(*gogm.BaseNode)
+}
+
+// memoryPaginate orders and slices nodes the way the cypher generated for a gogm.Pagination would
+func memoryPaginate(nodes []*memoryNode, pagination *gogm.Pagination) ([]*memoryNode, error) {
+	if err := pagination.Validate(); err != nil {
+		return nil, err
+	}
+
+	if pagination.OrderByField != "" && len(nodes) > 0 {
+		prop := nodes[0].schema().property(pagination.OrderByField)
+		if prop == nil {
+			return nil, fmt.Errorf("can not order by unknown property [%s]", pagination.OrderByField)
+		}
+
+		sort.SliceStable(nodes, func(i, j int) bool {
+			less := lessValue(nodes[i].Value.FieldByIndex(prop.Index), nodes[j].Value.FieldByIndex(prop.Index))
+			if pagination.OrderByDesc {
+				return lessValue(nodes[j].Value.FieldByIndex(prop.Index), nodes[i].Value.FieldByIndex(prop.Index))
+			}
+			return less
+		})
+	}
+
+	skip := pagination.LimitPerPage * pagination.PageNumber
+	if skip >= len(nodes) {
+		return nil, nil
+	}
+
+	nodes = nodes[skip:]
+	if pagination.LimitPerPage > 0 && pagination.LimitPerPage < len(nodes) {
+		nodes = nodes[:pagination.LimitPerPage]
+	}
+
+	return nodes, nil
+}
+
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
+
+var memoryConditionRegex = regexp.MustCompile(`^\w+\.(\w+) (=|<>|<=|>=|<|>|CONTAINS|STARTS WITH|ENDS WITH) (.+)$`)
+
+// memoryFilterMatches evaluates a gogm filter against a stored node. Only comparisons of a
+// property against a parameter or literal joined with AND are understood.
+func memoryFilterMatches(filter dsl.ConditionOperator, params map[string]interface{}, node *memoryNode) (bool, error) {
+	where, err := filter.Build()
+	if err != nil {
+		return false, err
+	}
+
+	schema := node.schema()
+	for _, clause := range strings.Split(string(where), " AND ") {
+		parts := memoryConditionRegex.FindStringSubmatch(strings.TrimSpace(clause))
+		if parts == nil {
+			return false, fmt.Errorf("memory session does not support filter [%s]", clause)
+		}
+
+		prop := schema.property(parts[1])
+		if prop == nil {
+			return false, nil
+		}
+
+		check, err := memoryFilterValue(parts[3], params)
+		if err != nil {
+			return false, err
+		}
+
+		actual := node.Value.FieldByIndex(prop.Index).Interface()
+		if !compareFilterValue(parts[2], actual, check) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func memoryFilterValue(raw string, params map[string]interface{}) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(raw, "{") && strings.HasSuffix(raw, "}"), strings.HasPrefix(raw, "$"):
+		name := strings.Trim(raw, "{}$")
+		val, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("filter parameter [%s] not provided", name)
+		}
+		return val, nil
+	case strings.HasPrefix(raw, "'") && strings.HasSuffix(raw, "'"):
+		return strings.Trim(raw, "'"), nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	default:
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("memory session can not parse filter value [%s]", raw)
+	}
+}
+
+func compareFilterValue(op string, actual, check interface{}) bool {
+	a, c := fmt.Sprint(actual), fmt.Sprint(check)
+
+	switch op {
+	case "=":
+		return a == c
+	case "<>":
+		return a != c
+	case "CONTAINS":
+		return strings.Contains(a, c)
+	case "STARTS WITH":
+		return strings.HasPrefix(a, c)
+	case "ENDS WITH":
+		return strings.HasSuffix(a, c)
+	}
+
+	af, aErr := strconv.ParseFloat(a, 64)
+	cf, cErr := strconv.ParseFloat(c, 64)
+	if aErr != nil || cErr != nil {
+		return compareOrdered(op, strings.Compare(a, c))
+	}
+
+	switch {
+	case af < cf:
+		return compareOrdered(op, -1)
+	case af > cf:
+		return compareOrdered(op, 1)
+	default:
+		return compareOrdered(op, 0)
+	}
+}
+
+func compareOrdered(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return false
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
-	"fmt"
 	"github.com/mindstand/gogm"
-	"reflect"
 	"time"
 )
 
-//nodes
+// linking.go and edges.go are generated from the nodes and edges below, run go generate after changing them
+//go:generate go run ./cmd/linkgen -o linking.go -edges edges.go models.go
+
+// nodes
 type Department struct {
 	gogm.BaseNode
 
@@ -32,72 +33,102 @@ type Teacher struct {
 
 	Name string `gogm:"name=name;unique"`
 
-	Courses    []*Course   `gogm:"direction=outgoing;relationship=TEACHES_CLASS"`
-	Subjects   []*Subject  `gogm:"direction=incoming;relationship=TAUGHT_BY"`
-	Department *Department `gogm:"direction=outgoing;relationship=FOR_DEPARTMENT"`
+	Offerings  []*CourseOffering `gogm:"direction=outgoing;relationship=TEACHES_CLASS"`
+	Subjects   []*Subject        `gogm:"direction=incoming;relationship=TAUGHT_BY"`
+	Department *Department       `gogm:"direction=outgoing;relationship=FOR_DEPARTMENT"`
 }
 
+// Course is a course of the catalog, it is taught in terms through its offerings
 type Course struct {
 	gogm.BaseNode
 
 	Name string `gogm:"name=name"`
 
-	Subject     *Subject      `gogm:"direction=outgoing;relationship=SUBJECT_TAUGHT"`
+	Subject *Subject `gogm:"direction=outgoing;relationship=SUBJECT_TAUGHT"`
+	// Prerequisites are the courses a student has to complete before enrolling in this one
+	Prerequisites []*Course `gogm:"direction=outgoing;relationship=REQUIRES"`
+	// RequiredBy are the courses that have this one as a prerequisite
+	RequiredBy []*Course `gogm:"direction=incoming;relationship=REQUIRES"`
+	// Offerings are the sections of the course in every term
+	Offerings []*CourseOffering `gogm:"direction=incoming;relationship=OFFERING_OF"`
+}
+
+// Term is a school term like "Fall 2026"
+type Term struct {
+	gogm.BaseNode
+
+	Name string `gogm:"name=name;unique"`
+
+	Offerings []*CourseOffering `gogm:"direction=incoming;relationship=OFFERED_IN"`
+}
+
+// CourseOffering is one section of a course in a term, students enroll in offerings
+type CourseOffering struct {
+	gogm.BaseNode
+
+	// Name is set by NewCourseOffering from the course, section and term, like "cs341-0 Fall 2026"
+	Name    string `gogm:"name=name"`
+	Section string `gogm:"name=section"`
+	// Capacity is the number of students that can be enrolled at once, 0 means unlimited
+	Capacity int `gogm:"name=capacity"`
+
+	Course      *Course       `gogm:"direction=outgoing;relationship=OFFERING_OF"`
+	Term        *Term         `gogm:"direction=outgoing;relationship=OFFERED_IN"`
 	Teacher     *Teacher      `gogm:"direction=incoming;relationship=TEACHES_CLASS"`
 	Enrollments []*Enrollment `gogm:"direction=incoming;relationship=ENROLLED"`
+	// Meetings are the weekly meeting times of the section and the rooms it meets in
+	Meetings []*Meeting `gogm:"direction=outgoing;relationship=MEETS_IN"`
 }
 
 type Student struct {
 	gogm.BaseNode
 
-	Name   string                 `gogm:"name=name;unique"`
+	Name string `gogm:"name=name;unique"`
+	// Grades is free form, course grades are recorded on the enrollments through the GradeBook
 	Grades map[string]interface{} `gogm:"name=grades;properties"`
 
 	Enrollments []*Enrollment `gogm:"direction=outgoing;relationship=ENROLLED"`
 }
 
-//edges
-type Enrollment struct {
+type Room struct {
 	gogm.BaseNode
 
-	Start *Student
-	End   *Course
+	Name string `gogm:"name=name;unique"`
 
-	EnrolledDate time.Time `gogm:"name=enrolled_date;time"`
+	Meetings []*Meeting `gogm:"direction=incoming;relationship=MEETS_IN"`
 }
 
-func (e *Enrollment) GetStartNode() interface{} {
-	return e.Start
-}
+//edges
 
-func (e *Enrollment) GetStartNodeType() reflect.Type {
-	return reflect.TypeOf(&Student{})
-}
+// the gogm.IEdge methods of the edges are generated into edges.go from their Start and End fields
 
-func (e *Enrollment) SetStartNode(v interface{}) error {
-	student, ok := v.(*Student)
-	if !ok {
-		return fmt.Errorf("unable to convert to [*Student] from [%T]", v)
-	}
+type Enrollment struct {
+	gogm.BaseNode
 
-	e.Start = student
-	return nil
-}
+	Start *Student
+	End   *CourseOffering
 
-func (e *Enrollment) GetEndNode() interface{} {
-	return e.End
+	// Status is one of the Enrollment* statuses, dropped and withdrawn enrollments are kept for the record
+	Status       string    `gogm:"name=status"`
+	EnrolledDate time.Time `gogm:"name=enrolled_date;time"`
+	DroppedDate  time.Time `gogm:"name=dropped_date;time"`
+	// WaitlistedDate is when the student was put on the waitlist of a full course, it decides the waitlist order
+	WaitlistedDate time.Time `gogm:"name=waitlisted_date;time"`
+	// FinalGrade is a letter grade, empty until the student is graded
+	FinalGrade string    `gogm:"name=final_grade"`
+	GradedDate time.Time `gogm:"name=graded_date;time"`
 }
 
-func (e *Enrollment) GetEndNodeType() reflect.Type {
-	return reflect.TypeOf(&Course{})
-}
+// Meeting is a weekly meeting of a course offering in a room
+type Meeting struct {
+	gogm.BaseNode
 
-func (e *Enrollment) SetEndNode(v interface{}) error {
-	course, ok := v.(*Course)
-	if !ok {
-		return fmt.Errorf("unable to convert to [*Course] from [%T]", v)
-	}
+	Start *CourseOffering
+	End   *Room
 
-	e.End = course
-	return nil
+	// Day is a lowercase weekday like "monday"
+	Day string `gogm:"name=day"`
+	// StartTime and EndTime are 24 hour clock times like "09:30", the meeting ends before EndTime
+	StartTime string `gogm:"name=start_time"`
+	EndTime   string `gogm:"name=end_time"`
 }
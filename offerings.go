@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOfferingExists is returned when offering a section of a course that is already offered in the term
+var ErrOfferingExists = errors.New("the section is already offered")
+
+// defaultSection is the section of offerings created without one
+const defaultSection = "0"
+
+// offeringName is the name of a section of course in term, like "cs341-0 Fall 2026"
+func offeringName(course *Course, term *Term, section string) string {
+	return fmt.Sprintf("%s-%s %s", course.Name, section, term.Name)
+}
+
+// sectionOrDefault trims section, an empty section is defaultSection
+func sectionOrDefault(section string) string {
+	section = strings.TrimSpace(section)
+	if section == "" {
+		return defaultSection
+	}
+
+	return section
+}
+
+// NewCourseOffering creates section of course in term, an empty section is defaultSection
+func NewCourseOffering(course *Course, term *Term, section string) (*CourseOffering, error) {
+	if course == nil || term == nil {
+		return nil, ErrNilTarget
+	}
+
+	section = sectionOrDefault(section)
+	offering := &CourseOffering{Name: offeringName(course, term, section), Section: section}
+
+	err := offering.LinkToCourseOnFieldCourse(course)
+	if err != nil {
+		return nil, err
+	}
+
+	err = offering.LinkToTermOnFieldTerm(term)
+	if err != nil {
+		return nil, err
+	}
+
+	return offering, nil
+}
+
+// Offer creates and saves section of course in term taught by teacher, teacher may be nil.
+// the term has to be loaded with its offerings.
+// fails with ErrOfferingExists if the term already has the section of the course
+func (r *Registrar) Offer(course *Course, term *Term, section string, teacher *Teacher, capacity int) (*CourseOffering, error) {
+	if capacity < 0 {
+		return nil, fmt.Errorf("%w, got %v", ErrInvalidCapacity, capacity)
+	}
+
+	if course == nil || term == nil {
+		return nil, ErrNilTarget
+	}
+
+	name := offeringName(course, term, sectionOrDefault(section))
+	for _, existing := range term.Offerings {
+		if existing.Name == name {
+			return nil, fmt.Errorf("%w, %s", ErrOfferingExists, name)
+		}
+	}
+
+	offering, err := NewCourseOffering(course, term, section)
+	if err != nil {
+		return nil, err
+	}
+
+	offering.Capacity = capacity
+
+	if teacher != nil {
+		err = offering.LinkToTeacherOnFieldTeacher(teacher)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.offerings.Save(offering)
+	if err != nil {
+		return nil, err
+	}
+
+	return offering, nil
+}
+
+// renameOfferings renames offerings after their course or term got renamed
+func (r *Registrar) renameOfferings(offerings []*CourseOffering) error {
+	for _, offering := range offerings {
+		loaded, err := r.offerings.Get(offering.UUID)
+		if err != nil {
+			return err
+		}
+
+		if loaded.Course == nil || loaded.Term == nil {
+			continue
+		}
+
+		loaded.Name = offeringName(loaded.Course, loaded.Term, loaded.Section)
+		offering.Name = loaded.Name
+
+		err = r.offerings.Save(loaded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
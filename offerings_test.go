@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewCourseOffering(t *testing.T) {
+	course, term := &Course{Name: "cs341"}, &Term{Name: "Fall 2026"}
+
+	for _, tt := range []struct {
+		section     string
+		wantName    string
+		wantSection string
+	}{
+		{"", "cs341-0 Fall 2026", "0"},
+		{" 2 ", "cs341-2 Fall 2026", "2"},
+		{"A", "cs341-A Fall 2026", "A"},
+	} {
+		offering, err := NewCourseOffering(course, term, tt.section)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if offering.Name != tt.wantName || offering.Section != tt.wantSection {
+			t.Errorf("section %q: got %q section %q, want %q section %q", tt.section, offering.Name, offering.Section, tt.wantName, tt.wantSection)
+		}
+		if offering.Course != course || offering.Term != term {
+			t.Errorf("section %q is not linked to its course and term", tt.section)
+		}
+	}
+
+	_, err := NewCourseOffering(course, nil, "")
+	if !errors.Is(err, ErrNilTarget) {
+		t.Errorf("an offering without a term got %v, want ErrNilTarget", err)
+	}
+}
+
+func TestOffer(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+
+	oates, err := repos.Teachers.FindByName("Oates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the offers are made in order, the term is loaded again for each
+	for _, tt := range []struct {
+		course   string
+		section  string
+		capacity int
+		want     error
+	}{
+		{"cs341", "", 0, ErrOfferingExists},
+		{"cs341", "1", 0, ErrOfferingExists},
+		{"cs341", "2", 30, nil},
+		{"cs341", " 2", 0, ErrOfferingExists},
+		{"hist347", "1", 0, nil},
+		{"phys122", "1", -1, ErrInvalidCapacity},
+	} {
+		term, err := repos.Terms.Get(school.Terms[exampleTerm].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = registrar.Offer(school.Courses[tt.course], term, tt.section, oates, tt.capacity)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("offering %s section %q: got %v, want %v", tt.course, tt.section, err, tt.want)
+		}
+	}
+
+	offerings, err := repos.Offerings.FindByName("cs341-2 " + exampleTerm)
+	if err != nil || len(offerings) != 1 {
+		t.Fatalf("finding cs341-2: %v", err)
+	}
+
+	offering := offerings[0]
+	if offering.Capacity != 30 || offering.Teacher == nil || offering.Teacher.Name != "Oates" ||
+		offering.Course == nil || offering.Course.Name != "cs341" || offering.Term == nil || offering.Term.Name != exampleTerm {
+		t.Errorf("cs341-2 is not saved with its capacity, teacher, course and term: %+v", offering)
+	}
+
+	if n := countNodes(sess, &CourseOffering{}); n != 6 {
+		t.Errorf("got %v offerings, want 6", n)
+	}
+}
+
+func TestOfferingSearch(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	spring := &Term{Name: "Spring 2027"}
+	err := repos.Terms.Save(spring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewRegistrar(sess).Offer(school.Courses["cs341"], spring, "", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		term, department string
+		want             []string
+	}{
+		{"", "", []string{"cs341-0 Fall 2026", "cs341-0 Spring 2027", "cs341-1 Fall 2026", "hist347-0 Fall 2026", "phys122-0 Fall 2026"}},
+		{exampleTerm, "Compsci", []string{"cs341-0 Fall 2026", "cs341-1 Fall 2026"}},
+		{"Spring 2027", "", []string{"cs341-0 Spring 2027"}},
+		{"", "History", []string{"hist347-0 Fall 2026"}},
+		{"Spring 2027", "History", nil},
+	} {
+		found, err := repos.Offerings.Search(tt.term, tt.department)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, offering := range found {
+			names = append(names, offering.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Search(%q, %q) got %q, want %q", tt.term, tt.department, names, tt.want)
+		}
+	}
+
+	// FindOf picks the offerings of a course by term and section
+	cs341, err := repos.Courses.Get(school.Courses["cs341"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		term, section string
+		want          int
+	}{
+		{"", "", 3},
+		{exampleTerm, "", 2},
+		{exampleTerm, "1", 1},
+		{"Spring 2027", "0", 1},
+		{"Spring 2027", "1", 0},
+	} {
+		found, err := repos.Offerings.FindOf(cs341, tt.term, tt.section)
+		if err != nil || len(found) != tt.want {
+			t.Errorf("FindOf(cs341, %q, %q) found %v, %v, want %v", tt.term, tt.section, len(found), err, tt.want)
+		}
+	}
+}
+
+func TestRenameOfferings(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	course, err := repos.Courses.Get(school.Courses["cs341"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	course.Name = "cs342"
+	err = repos.Courses.Save(course)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewRegistrar(sess).renameOfferings(course.Offerings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cs342-0 Fall 2026", "cs342-1 Fall 2026"} {
+		found, err := repos.Offerings.FindByName(name)
+		if err != nil || len(found) != 1 {
+			t.Errorf("finding %s after the rename: %v, %v", name, len(found), err)
+		}
+	}
+}
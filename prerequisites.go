@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPrerequisiteCycle is returned when a prerequisite would make a course require itself
+	ErrPrerequisiteCycle = errors.New("prerequisites can not form a cycle")
+	// ErrMissingPrerequisites is returned when enrolling a student that has not passed every prerequisite of a course
+	ErrMissingPrerequisites = errors.New("prerequisites are not completed")
+)
+
+// Passed is true when the enrollment is completed with a grade better than F
+func (e *Enrollment) Passed() bool {
+	points, graded := e.GradePoints()
+	return e.CurrentStatus() == EnrollmentCompleted && graded && points > 0
+}
+
+// AddPrerequisite makes course require prerequisite, both have to be loaded with their relationships.
+// fails with ErrPrerequisiteCycle if prerequisite already requires course, directly or through other courses
+func (r *Registrar) AddPrerequisite(course, prerequisite *Course) error {
+	path, err := r.prerequisitePath(prerequisite, course)
+	if err != nil {
+		return err
+	}
+
+	if path != nil {
+		names := []string{course.Name}
+		for _, step := range path {
+			names = append(names, step.Name)
+		}
+		return fmt.Errorf("%w, %s", ErrPrerequisiteCycle, strings.Join(names, " requires "))
+	}
+
+	err = course.LinkToCourseOnFieldPrerequisitesWith(&LinkOptions{Duplicates: FailOnDuplicate}, prerequisite)
+	if err != nil {
+		return err
+	}
+
+	return r.courses.Save(course)
+}
+
+// RemovePrerequisite makes course no longer require prerequisite
+func (r *Registrar) RemovePrerequisite(course, prerequisite *Course) error {
+	err := course.UnlinkFromCourseOnFieldPrerequisites(prerequisite)
+	if err != nil {
+		return err
+	}
+
+	return r.courses.Save(course)
+}
+
+// prerequisitePath finds the chain of prerequisites leading from from to to, starting with from.
+// returns nil if from does not require to. courses are loaded as the search reaches them
+func (r *Registrar) prerequisitePath(from, to *Course) ([]*Course, error) {
+	if sameCourse(from, to) {
+		return []*Course{from}, nil
+	}
+
+	// breadth first so the shortest cycle is reported
+	previous := map[string]*Course{from.UUID: nil}
+	queue := []*Course{from}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		loaded, err := r.courses.Get(current.UUID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, next := range loaded.Prerequisites {
+			if _, seen := previous[next.UUID]; seen {
+				continue
+			}
+			previous[next.UUID] = current
+
+			if sameCourse(next, to) {
+				path := []*Course{next}
+				for step := current; step != nil; step = previous[step.UUID] {
+					path = append([]*Course{step}, path...)
+				}
+				return path, nil
+			}
+
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, nil
+}
+
+// checkPrerequisites makes sure student passed every prerequisite of course in some term,
+// the offerings of the student have to be loaded with their course
+func checkPrerequisites(student *Student, course *Course) error {
+	var missing []string
+	for _, prerequisite := range course.Prerequisites {
+		passed := false
+		for _, enrollment := range student.Enrollments {
+			if enrollment.End != nil && sameCourse(enrollment.End.Course, prerequisite) && enrollment.Passed() {
+				passed = true
+				break
+			}
+		}
+
+		if !passed {
+			missing = append(missing, prerequisite.Name)
+		}
+	}
+
+	if len(missing) != 0 {
+		return fmt.Errorf("%w, %s has not passed %s", ErrMissingPrerequisites, student.Name, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrollmentPassed(t *testing.T) {
+	for _, tt := range []struct {
+		status, grade string
+		want          bool
+	}{
+		{EnrollmentCompleted, "C-", true},
+		{EnrollmentCompleted, "D-", true},
+		{EnrollmentCompleted, "F", false},
+		{EnrollmentCompleted, "", false},
+		{EnrollmentEnrolled, "A", false},
+		{EnrollmentWithdrawn, "A", false},
+	} {
+		e := &Enrollment{Status: tt.status, FinalGrade: tt.grade}
+		if got := e.Passed(); got != tt.want {
+			t.Errorf("%s with %q: got passed %v, want %v", tt.status, tt.grade, got, tt.want)
+		}
+	}
+}
+
+func TestAddPrerequisite(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+
+	course := func(name string) *Course {
+		found, err := repos.Courses.FindByName(name)
+		if err != nil || len(found) != 1 {
+			t.Fatalf("finding %s: %v", name, err)
+		}
+		return found[0]
+	}
+
+	// the prerequisites are added in order
+	for _, tt := range []struct {
+		course, prerequisite string
+		want                 error
+		cycle                string
+	}{
+		{course: "cs341", prerequisite: "phys122"},
+		{course: "phys122", prerequisite: "hist347"},
+		{course: "cs341", prerequisite: "hist347"},
+		{course: "cs341", prerequisite: "phys122", want: ErrAlreadyLinked},
+		{course: "cs341", prerequisite: "cs341", want: ErrPrerequisiteCycle, cycle: "cs341 requires cs341"},
+		{course: "phys122", prerequisite: "cs341", want: ErrPrerequisiteCycle, cycle: "phys122 requires cs341 requires phys122"},
+		{course: "hist347", prerequisite: "cs341", want: ErrPrerequisiteCycle, cycle: "hist347 requires cs341 requires hist347"},
+	} {
+		err := registrar.AddPrerequisite(course(tt.course), course(tt.prerequisite))
+		if !errors.Is(err, tt.want) || (tt.cycle != "" && !strings.HasSuffix(err.Error(), tt.cycle)) {
+			t.Errorf("%s requires %s: got %v, want %v %s", tt.course, tt.prerequisite, err, tt.want, tt.cycle)
+		}
+	}
+
+	var got []string
+	for _, prerequisite := range course("cs341").Prerequisites {
+		got = append(got, prerequisite.Name)
+	}
+	if strings.Join(got, " ") != "phys122 hist347" {
+		t.Errorf("cs341 requires %q, want phys122 and hist347", got)
+	}
+
+	// without the prerequisite the cycle is gone
+	err := registrar.RemovePrerequisite(course("phys122"), course("hist347"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = registrar.RemovePrerequisite(course("cs341"), course("hist347"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = registrar.AddPrerequisite(course("hist347"), course("cs341"))
+	if err != nil {
+		t.Errorf("hist347 requires cs341 after removing the cycle: %v", err)
+	}
+}
+
+func TestPrerequisitesEnforced(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+	book := NewGradeBook(sess)
+
+	cs341, err := repos.Courses.Get(school.Courses["cs341"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hist347, err := repos.Courses.Get(school.Courses["hist347"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = registrar.AddPrerequisite(cs341, hist347)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	michael := func() *Student {
+		found, err := repos.Students.Get(school.Students["michael"].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	offering := func(name string) *CourseOffering {
+		found, err := repos.Offerings.Get(school.Offerings[name+" "+exampleTerm].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	enroll := func() error {
+		return registrar.Enroll(michael(), offering("cs341-1"), NewEnrollment(time.Now().UTC()))
+	}
+	grade := func(letter string) func() error {
+		return func() error {
+			_, err := book.Record(michael(), offering("hist347-0"), letter)
+			return err
+		}
+	}
+
+	// michael takes hist347, the steps run in order
+	for _, tt := range []struct {
+		name string
+		step func() error
+		want error
+	}{
+		{"not completed", enroll, ErrMissingPrerequisites},
+		{"failing grade", grade("F"), nil},
+		{"failed", enroll, ErrMissingPrerequisites},
+		{"passing grade", grade("D"), nil},
+		{"passed", enroll, nil},
+	} {
+		err := tt.step()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// linking an enrollment directly checks the prerequisites of an offering loaded with them
+	anna := &Student{Name: "anna"}
+	cs341_0 := offering("cs341-0")
+	cs341_0.Course.Prerequisites = []*Course{hist347}
+
+	err = anna.LinkToCourseOfferingOnFieldEnrollments(cs341_0, NewEnrollment(testDate))
+	var linkErr *LinkError
+	if !errors.As(err, &linkErr) || !errors.Is(err, ErrMissingPrerequisites) {
+		t.Errorf("linking without the prerequisites got %v, want a *LinkError with ErrMissingPrerequisites", err)
+	}
+	if !strings.Contains(err.Error(), "anna has not passed hist347") || len(anna.Enrollments) != 0 {
+		t.Errorf("got %v with %v enrollments", err, len(anna.Enrollments))
+	}
+}
@@ -0,0 +1,729 @@
+package main
+
+import (
+	"errors"
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/mindstand/gogm"
+	"sort"
+)
+
+// load and save depths for each aggregate.
+// departments are saved to a depth of 2 so courses get connected through their subjects,
+// everything else only needs its direct relationships. every aggregate is loaded to the depth it is
+// saved at, so a save never writes back a node that was only partly loaded.
+const (
+	departmentLoadDepth = 2
+	departmentSaveDepth = 2
+
+	subjectLoadDepth = 1
+	subjectSaveDepth = 1
+
+	teacherLoadDepth = 1
+	teacherSaveDepth = 1
+
+	courseLoadDepth = 1
+	courseSaveDepth = 1
+
+	studentLoadDepth = 1
+	studentSaveDepth = 1
+
+	roomLoadDepth = 1
+	roomSaveDepth = 1
+
+	termLoadDepth = 1
+	termSaveDepth = 1
+
+	offeringLoadDepth = 1
+	offeringSaveDepth = 1
+
+	// offeringSearchDepth reaches from an offering through its course and subject to the department
+	offeringSearchDepth = 3
+)
+
+// Repositories groups the repository of every node type around one session
+type Repositories struct {
+	Departments *DepartmentRepo
+	Subjects    *SubjectRepo
+	Teachers    *TeacherRepo
+	Courses     *CourseRepo
+	Students    *StudentRepo
+	Rooms       *RoomRepo
+	Terms       *TermRepo
+	Offerings   *OfferingRepo
+}
+
+// NewRepositories creates repositories for every node type sharing sess
+func NewRepositories(sess gogm.ISession) *Repositories {
+	return &Repositories{
+		Departments: NewDepartmentRepo(sess),
+		Subjects:    NewSubjectRepo(sess),
+		Teachers:    NewTeacherRepo(sess),
+		Courses:     NewCourseRepo(sess),
+		Students:    NewStudentRepo(sess),
+		Rooms:       NewRoomRepo(sess),
+		Terms:       NewTermRepo(sess),
+		Offerings:   NewOfferingRepo(sess),
+	}
+}
+
+// nameFilter matches nodes on their name property
+func nameFilter(name string) (dsl.ConditionOperator, map[string]interface{}) {
+	return dsl.C(&dsl.ConditionConfig{
+		Name:              "n",
+		Field:             "name",
+		ConditionOperator: dsl.EqualToOperator,
+		Check:             dsl.ParamString("{name}"),
+	}), map[string]interface{}{
+		"name": name,
+	}
+}
+
+// ignoreNotFound turns gogm.ErrNotFound into an empty result
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gogm.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// pageBounds returns the slice bounds of page (zero based) when there are perPage items per page
+func pageBounds(total, page, perPage int) (int, int) {
+	if page < 0 || perPage <= 0 {
+		return 0, 0
+	}
+
+	start := page * perPage
+	if start > total {
+		return total, total
+	}
+
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+// note on ListPaged: gogm.Pagination.Validate in the pinned gogm version rejects every valid pagination,
+// so pages are cut after loading the nodes sorted by name.
+
+// DepartmentRepo persists departments along with their subjects, teachers and courses
+type DepartmentRepo struct {
+	sess gogm.ISession
+}
+
+// NewDepartmentRepo creates a DepartmentRepo on top of sess
+func NewDepartmentRepo(sess gogm.ISession) *DepartmentRepo {
+	return &DepartmentRepo{sess: sess}
+}
+
+// Get loads the department with uuid
+func (r *DepartmentRepo) Get(uuid string) (*Department, error) {
+	var department Department
+	err := r.sess.LoadDepth(&department, uuid, departmentLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &department, nil
+}
+
+// FindByName loads every department called name
+func (r *DepartmentRepo) FindByName(name string) ([]*Department, error) {
+	var departments []*Department
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&departments, departmentLoadDepth, filter, params)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return departments, nil
+}
+
+// List loads every department
+func (r *DepartmentRepo) List() ([]*Department, error) {
+	var departments []*Department
+	err := r.sess.LoadAllDepth(&departments, departmentLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return departments, nil
+}
+
+// ListPaged loads one page of departments ordered by name
+func (r *DepartmentRepo) ListPaged(page, perPage int) ([]*Department, error) {
+	departments, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(departments, func(i, j int) bool {
+		return departments[i].Name < departments[j].Name
+	})
+
+	start, end := pageBounds(len(departments), page, perPage)
+	return departments[start:end], nil
+}
+
+// Save persists the department, its subjects, teachers and the courses of its subjects
+func (r *DepartmentRepo) Save(department *Department) error {
+	return SaveValidated(r.sess, department, departmentSaveDepth)
+}
+
+// Delete removes the department and its relationships
+func (r *DepartmentRepo) Delete(department *Department) error {
+	return r.sess.Delete(department)
+}
+
+// SubjectRepo persists subjects with their department, teachers and courses
+type SubjectRepo struct {
+	sess gogm.ISession
+}
+
+// NewSubjectRepo creates a SubjectRepo on top of sess
+func NewSubjectRepo(sess gogm.ISession) *SubjectRepo {
+	return &SubjectRepo{sess: sess}
+}
+
+// Get loads the subject with uuid
+func (r *SubjectRepo) Get(uuid string) (*Subject, error) {
+	var subject Subject
+	err := r.sess.LoadDepth(&subject, uuid, subjectLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &subject, nil
+}
+
+// FindByName loads every subject called name
+func (r *SubjectRepo) FindByName(name string) ([]*Subject, error) {
+	var subjects []*Subject
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&subjects, subjectLoadDepth, filter, params)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return subjects, nil
+}
+
+// List loads every subject
+func (r *SubjectRepo) List() ([]*Subject, error) {
+	var subjects []*Subject
+	err := r.sess.LoadAllDepth(&subjects, subjectLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return subjects, nil
+}
+
+// ListPaged loads one page of subjects ordered by name
+func (r *SubjectRepo) ListPaged(page, perPage int) ([]*Subject, error) {
+	subjects, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(subjects, func(i, j int) bool {
+		return subjects[i].Name < subjects[j].Name
+	})
+
+	start, end := pageBounds(len(subjects), page, perPage)
+	return subjects[start:end], nil
+}
+
+// Save persists the subject and its direct relationships
+func (r *SubjectRepo) Save(subject *Subject) error {
+	return SaveValidated(r.sess, subject, subjectSaveDepth)
+}
+
+// Delete removes the subject and its relationships
+func (r *SubjectRepo) Delete(subject *Subject) error {
+	return r.sess.Delete(subject)
+}
+
+// TeacherRepo persists teachers with their department, subjects and offerings
+type TeacherRepo struct {
+	sess gogm.ISession
+}
+
+// NewTeacherRepo creates a TeacherRepo on top of sess
+func NewTeacherRepo(sess gogm.ISession) *TeacherRepo {
+	return &TeacherRepo{sess: sess}
+}
+
+// Get loads the teacher with uuid
+func (r *TeacherRepo) Get(uuid string) (*Teacher, error) {
+	var teacher Teacher
+	err := r.sess.LoadDepth(&teacher, uuid, teacherLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &teacher, nil
+}
+
+// FindByName loads the teacher called name, teacher names are unique.
+// returns gogm.ErrNotFound if there is no such teacher
+func (r *TeacherRepo) FindByName(name string) (*Teacher, error) {
+	var teachers []*Teacher
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&teachers, teacherLoadDepth, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return teachers[0], nil
+}
+
+// List loads every teacher
+func (r *TeacherRepo) List() ([]*Teacher, error) {
+	var teachers []*Teacher
+	err := r.sess.LoadAllDepth(&teachers, teacherLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return teachers, nil
+}
+
+// ListPaged loads one page of teachers ordered by name
+func (r *TeacherRepo) ListPaged(page, perPage int) ([]*Teacher, error) {
+	teachers, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(teachers, func(i, j int) bool {
+		return teachers[i].Name < teachers[j].Name
+	})
+
+	start, end := pageBounds(len(teachers), page, perPage)
+	return teachers[start:end], nil
+}
+
+// Save persists the teacher and its direct relationships
+func (r *TeacherRepo) Save(teacher *Teacher) error {
+	return SaveValidated(r.sess, teacher, teacherSaveDepth)
+}
+
+// Delete removes the teacher and its relationships
+func (r *TeacherRepo) Delete(teacher *Teacher) error {
+	return r.sess.Delete(teacher)
+}
+
+// CourseRepo persists catalog courses with their subject, prerequisites and offerings
+type CourseRepo struct {
+	sess gogm.ISession
+}
+
+// NewCourseRepo creates a CourseRepo on top of sess
+func NewCourseRepo(sess gogm.ISession) *CourseRepo {
+	return &CourseRepo{sess: sess}
+}
+
+// Get loads the course with uuid
+func (r *CourseRepo) Get(uuid string) (*Course, error) {
+	var course Course
+	err := r.sess.LoadDepth(&course, uuid, courseLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &course, nil
+}
+
+// FindByName loads every course called name
+func (r *CourseRepo) FindByName(name string) ([]*Course, error) {
+	var courses []*Course
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&courses, courseLoadDepth, filter, params)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return courses, nil
+}
+
+// List loads every course
+func (r *CourseRepo) List() ([]*Course, error) {
+	var courses []*Course
+	err := r.sess.LoadAllDepth(&courses, courseLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return courses, nil
+}
+
+// ListPaged loads one page of courses ordered by name
+func (r *CourseRepo) ListPaged(page, perPage int) ([]*Course, error) {
+	courses, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(courses, func(i, j int) bool {
+		return courses[i].Name < courses[j].Name
+	})
+
+	start, end := pageBounds(len(courses), page, perPage)
+	return courses[start:end], nil
+}
+
+// Save persists the course and its direct relationships
+func (r *CourseRepo) Save(course *Course) error {
+	return SaveValidated(r.sess, course, courseSaveDepth)
+}
+
+// Delete removes the course and its relationships
+func (r *CourseRepo) Delete(course *Course) error {
+	return r.sess.Delete(course)
+}
+
+// StudentRepo persists students with their enrollments
+type StudentRepo struct {
+	sess gogm.ISession
+}
+
+// NewStudentRepo creates a StudentRepo on top of sess
+func NewStudentRepo(sess gogm.ISession) *StudentRepo {
+	return &StudentRepo{sess: sess}
+}
+
+// Get loads the student with uuid
+func (r *StudentRepo) Get(uuid string) (*Student, error) {
+	var student Student
+	err := r.sess.LoadDepth(&student, uuid, studentLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &student, nil
+}
+
+// FindByName loads the student called name, student names are unique.
+// returns gogm.ErrNotFound if there is no such student
+func (r *StudentRepo) FindByName(name string) (*Student, error) {
+	var students []*Student
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&students, studentLoadDepth, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return students[0], nil
+}
+
+// List loads every student
+func (r *StudentRepo) List() ([]*Student, error) {
+	var students []*Student
+	err := r.sess.LoadAllDepth(&students, studentLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return students, nil
+}
+
+// ListPaged loads one page of students ordered by name
+func (r *StudentRepo) ListPaged(page, perPage int) ([]*Student, error) {
+	students, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(students, func(i, j int) bool {
+		return students[i].Name < students[j].Name
+	})
+
+	start, end := pageBounds(len(students), page, perPage)
+	return students[start:end], nil
+}
+
+// Save persists the student, its enrollments and the offerings it is enrolled in
+func (r *StudentRepo) Save(student *Student) error {
+	return SaveValidated(r.sess, student, studentSaveDepth)
+}
+
+// Delete removes the student and its enrollments
+func (r *StudentRepo) Delete(student *Student) error {
+	return r.sess.Delete(student)
+}
+
+// RoomRepo persists rooms with the meetings of the offerings in them
+type RoomRepo struct {
+	sess gogm.ISession
+}
+
+// NewRoomRepo creates a RoomRepo on top of sess
+func NewRoomRepo(sess gogm.ISession) *RoomRepo {
+	return &RoomRepo{sess: sess}
+}
+
+// Get loads the room with uuid
+func (r *RoomRepo) Get(uuid string) (*Room, error) {
+	var room Room
+	err := r.sess.LoadDepth(&room, uuid, roomLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &room, nil
+}
+
+// FindByName loads the room called name, room names are unique.
+// returns gogm.ErrNotFound if there is no such room
+func (r *RoomRepo) FindByName(name string) (*Room, error) {
+	var rooms []*Room
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&rooms, roomLoadDepth, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return rooms[0], nil
+}
+
+// List loads every room
+func (r *RoomRepo) List() ([]*Room, error) {
+	var rooms []*Room
+	err := r.sess.LoadAllDepth(&rooms, roomLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return rooms, nil
+}
+
+// ListPaged loads one page of rooms ordered by name
+func (r *RoomRepo) ListPaged(page, perPage int) ([]*Room, error) {
+	rooms, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return rooms[i].Name < rooms[j].Name
+	})
+
+	start, end := pageBounds(len(rooms), page, perPage)
+	return rooms[start:end], nil
+}
+
+// Save persists the room and its meetings
+func (r *RoomRepo) Save(room *Room) error {
+	return SaveValidated(r.sess, room, roomSaveDepth)
+}
+
+// Delete removes the room and its meetings
+func (r *RoomRepo) Delete(room *Room) error {
+	return r.sess.Delete(room)
+}
+
+// TermRepo persists terms with their offerings
+type TermRepo struct {
+	sess gogm.ISession
+}
+
+// NewTermRepo creates a TermRepo on top of sess
+func NewTermRepo(sess gogm.ISession) *TermRepo {
+	return &TermRepo{sess: sess}
+}
+
+// Get loads the term with uuid
+func (r *TermRepo) Get(uuid string) (*Term, error) {
+	var term Term
+	err := r.sess.LoadDepth(&term, uuid, termLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &term, nil
+}
+
+// FindByName loads the term called name, term names are unique.
+// returns gogm.ErrNotFound if there is no such term
+func (r *TermRepo) FindByName(name string) (*Term, error) {
+	var terms []*Term
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&terms, termLoadDepth, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return terms[0], nil
+}
+
+// List loads every term
+func (r *TermRepo) List() ([]*Term, error) {
+	var terms []*Term
+	err := r.sess.LoadAllDepth(&terms, termLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return terms, nil
+}
+
+// ListPaged loads one page of terms ordered by name
+func (r *TermRepo) ListPaged(page, perPage int) ([]*Term, error) {
+	terms, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].Name < terms[j].Name
+	})
+
+	start, end := pageBounds(len(terms), page, perPage)
+	return terms[start:end], nil
+}
+
+// Save persists the term and its direct relationships
+func (r *TermRepo) Save(term *Term) error {
+	return SaveValidated(r.sess, term, termSaveDepth)
+}
+
+// Delete removes the term and its relationships
+func (r *TermRepo) Delete(term *Term) error {
+	return r.sess.Delete(term)
+}
+
+// OfferingRepo persists course offerings with their course, term, teacher, enrollments and meetings
+type OfferingRepo struct {
+	sess gogm.ISession
+}
+
+// NewOfferingRepo creates an OfferingRepo on top of sess
+func NewOfferingRepo(sess gogm.ISession) *OfferingRepo {
+	return &OfferingRepo{sess: sess}
+}
+
+// Get loads the offering with uuid
+func (r *OfferingRepo) Get(uuid string) (*CourseOffering, error) {
+	var offering CourseOffering
+	err := r.sess.LoadDepth(&offering, uuid, offeringLoadDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &offering, nil
+}
+
+// FindByName loads every offering called name
+func (r *OfferingRepo) FindByName(name string) ([]*CourseOffering, error) {
+	var offerings []*CourseOffering
+	filter, params := nameFilter(name)
+	err := r.sess.LoadAllDepthFilter(&offerings, offeringLoadDepth, filter, params)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return offerings, nil
+}
+
+// FindOf loads the offerings of course, loaded with its offerings, in term and section.
+// an empty term or section matches every term or section, offerings that were never saved are skipped
+func (r *OfferingRepo) FindOf(course *Course, term, section string) ([]*CourseOffering, error) {
+	var offerings []*CourseOffering
+	for _, offering := range course.Offerings {
+		if offering.UUID == "" || (section != "" && offering.Section != section) {
+			continue
+		}
+
+		loaded, err := r.Get(offering.UUID)
+		if err != nil {
+			return nil, err
+		}
+
+		if term != "" && (loaded.Term == nil || loaded.Term.Name != term) {
+			continue
+		}
+
+		offerings = append(offerings, loaded)
+	}
+
+	return offerings, nil
+}
+
+// Search loads the offerings in term of the courses of department ordered by name,
+// an empty term or department matches every term or department.
+// the offerings are loaded deep enough to reach the department of their course
+func (r *OfferingRepo) Search(term, department string) ([]*CourseOffering, error) {
+	var offerings []*CourseOffering
+	err := r.sess.LoadAllDepth(&offerings, offeringSearchDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	var found []*CourseOffering
+	for _, offering := range offerings {
+		if term != "" && (offering.Term == nil || offering.Term.Name != term) {
+			continue
+		}
+
+		if department != "" && offeringDepartment(offering) != department {
+			continue
+		}
+
+		found = append(found, offering)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Name < found[j].Name
+	})
+
+	return found, nil
+}
+
+// offeringDepartment is the name of the department offering the course of offering, empty if it has none
+func offeringDepartment(offering *CourseOffering) string {
+	if offering.Course == nil || offering.Course.Subject == nil || offering.Course.Subject.Department == nil {
+		return ""
+	}
+
+	return offering.Course.Subject.Department.Name
+}
+
+// List loads every offering
+func (r *OfferingRepo) List() ([]*CourseOffering, error) {
+	var offerings []*CourseOffering
+	err := r.sess.LoadAllDepth(&offerings, offeringLoadDepth)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+
+	return offerings, nil
+}
+
+// ListPaged loads one page of offerings ordered by name
+func (r *OfferingRepo) ListPaged(page, perPage int) ([]*CourseOffering, error) {
+	offerings, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(offerings, func(i, j int) bool {
+		return offerings[i].Name < offerings[j].Name
+	})
+
+	start, end := pageBounds(len(offerings), page, perPage)
+	return offerings[start:end], nil
+}
+
+// Save persists the offering, its enrollments, meetings and the nodes at their other ends
+func (r *OfferingRepo) Save(offering *CourseOffering) error {
+	return SaveValidated(r.sess, offering, offeringSaveDepth)
+}
+
+// Delete removes the offering with its enrollments and meetings
+func (r *OfferingRepo) Delete(offering *CourseOffering) error {
+	return r.sess.Delete(offering)
+}
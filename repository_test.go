@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"testing"
+)
+
+func TestPageBounds(t *testing.T) {
+	for _, tt := range []struct {
+		total, page, perPage int
+		start, end           int
+	}{
+		{10, 0, 3, 0, 3},
+		{10, 1, 3, 3, 6},
+		{10, 3, 3, 9, 10},
+		{10, 4, 3, 10, 10},
+		{0, 0, 3, 0, 0},
+		{10, -1, 3, 0, 0},
+		{10, 0, 0, 0, 0},
+	} {
+		start, end := pageBounds(tt.total, tt.page, tt.perPage)
+		if start != tt.start || end != tt.end {
+			t.Errorf("pageBounds(%v, %v, %v) = %v, %v, want %v, %v", tt.total, tt.page, tt.perPage, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestListPaged(t *testing.T) {
+	sess := newTestSession(t)
+	repos := NewRepositories(sess)
+
+	for _, name := range []string{"eric", "steven", "michael", "nikita", "anna"} {
+		err := repos.Students.Save(&Student{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		page, perPage int
+		want          []string
+	}{
+		{0, 2, []string{"anna", "eric"}},
+		{1, 2, []string{"michael", "nikita"}},
+		{2, 2, []string{"steven"}},
+		{3, 2, nil},
+		{0, 10, []string{"anna", "eric", "michael", "nikita", "steven"}},
+		{-1, 2, nil},
+	} {
+		students, err := repos.Students.ListPaged(tt.page, tt.perPage)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, student := range students {
+			names = append(names, student.Name)
+		}
+
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("page %v of %v: got %v, want %v", tt.page, tt.perPage, names, tt.want)
+		}
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	// unique names return the node or gogm.ErrNotFound
+	for _, tt := range []struct {
+		name string
+		find func(name string) (string, error)
+	}{
+		{"eric", func(name string) (string, error) {
+			student, err := repos.Students.FindByName(name)
+			if err != nil {
+				return "", err
+			}
+			return student.Name, nil
+		}},
+		{"Oates", func(name string) (string, error) {
+			teacher, err := repos.Teachers.FindByName(name)
+			if err != nil {
+				return "", err
+			}
+			return teacher.Name, nil
+		}},
+		{"Gates 101", func(name string) (string, error) {
+			room, err := repos.Rooms.FindByName(name)
+			if err != nil {
+				return "", err
+			}
+			return room.Name, nil
+		}},
+		{exampleTerm, func(name string) (string, error) {
+			term, err := repos.Terms.FindByName(name)
+			if err != nil {
+				return "", err
+			}
+			return term.Name, nil
+		}},
+	} {
+		found, err := tt.find(tt.name)
+		if err != nil || found != tt.name {
+			t.Errorf("FindByName(%q) = %q, %v", tt.name, found, err)
+		}
+
+		_, err = tt.find("nobody")
+		if !errors.Is(err, gogm.ErrNotFound) {
+			t.Errorf("FindByName(%q) of a missing node got %v, want %v", tt.name, err, gogm.ErrNotFound)
+		}
+	}
+
+	// other names can be shared, missing ones return nothing
+	for _, tt := range []struct {
+		name string
+		want int
+	}{
+		{"Compsci", 1},
+		{"nobody", 0},
+	} {
+		departments, err := repos.Departments.FindByName(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(departments) != tt.want {
+			t.Errorf("Departments.FindByName(%q) found %v, want %v", tt.name, len(departments), tt.want)
+		}
+	}
+}
+
+func TestRepositorySaveAndDelete(t *testing.T) {
+	sess := newTestSession(t)
+	repos := NewRepositories(sess)
+
+	room := &Room{Name: "Gates 101"}
+	err := repos.Rooms.Save(room)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := repos.Rooms.Get(room.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Name != room.Name {
+		t.Errorf("loaded %q, want %q", loaded.Name, room.Name)
+	}
+
+	err = repos.Rooms.Delete(room)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repos.Rooms.Get(room.UUID)
+	if !errors.Is(err, gogm.ErrNotFound) {
+		t.Errorf("deleted room got %v, want %v", err, gogm.ErrNotFound)
+	}
+}
+
+func TestRenameDepartment(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+
+	compsci, err := repos.Departments.Get(school.Departments["Compsci"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the department is loaded as deep as it is saved
+	if len(compsci.Subjects[0].Courses) != 1 || len(compsci.Teachers[0].Offerings) != 1 {
+		t.Fatalf("loaded %v courses of %s and %v offerings of %s, want 1 each",
+			len(compsci.Subjects[0].Courses), compsci.Subjects[0].Name, len(compsci.Teachers[0].Offerings), compsci.Teachers[0].Name)
+	}
+
+	compsci.Name = "Computer Science"
+	err = repos.Departments.Save(compsci)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := repos.Subjects.Get(school.Subjects["dataStructures"].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subject.Courses) != 1 || subject.Department == nil || subject.Department.Name != "Computer Science" {
+		t.Errorf("dataStructures has %v courses in %+v after the rename, want cs341 in Computer Science", len(subject.Courses), subject.Department)
+	}
+
+	for _, name := range []string{"Elias", "Oates"} {
+		teacher, err := repos.Teachers.Get(school.Teachers[name].UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(teacher.Offerings) != 1 {
+			t.Errorf("%s teaches %v offerings after the rename, want 1", name, len(teacher.Offerings))
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidMeeting is returned for meetings on an unknown day or that do not end after they start
+	ErrInvalidMeeting = errors.New("invalid meeting time")
+	// ErrScheduleConflict is returned when a student, teacher or room would be in two meetings at once
+	ErrScheduleConflict = errors.New("schedule conflict")
+)
+
+// clockLayout is the format of the start and end times of a meeting
+const clockLayout = "15:04"
+
+// weekdays are the days a course can meet on
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// NewMeeting creates a weekly meeting on day from start until end, like NewMeeting("Monday", "9:00", "10:30")
+func NewMeeting(day, start, end string) (*Meeting, error) {
+	meeting := &Meeting{Day: strings.ToLower(strings.TrimSpace(day)), StartTime: start, EndTime: end}
+	startMinute, endMinute, err := meeting.minutes()
+	if err != nil {
+		return nil, err
+	}
+
+	// store the times as 09:00 so they sort and read the same everywhere
+	meeting.StartTime = fmt.Sprintf("%02d:%02d", startMinute/60, startMinute%60)
+	meeting.EndTime = fmt.Sprintf("%02d:%02d", endMinute/60, endMinute%60)
+	return meeting, nil
+}
+
+// minutes returns the start and end of the meeting in minutes after midnight, checking the meeting is valid
+func (m *Meeting) minutes() (int, int, error) {
+	err := checkWeekday(m.Day)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start, err := clockMinute(m.StartTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := clockMinute(m.EndTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if end <= start {
+		return 0, 0, fmt.Errorf("%w, %s does not end after it starts", ErrInvalidMeeting, m)
+	}
+
+	return start, end, nil
+}
+
+// checkWeekday checks that day is a lowercase weekday like monday
+func checkWeekday(day string) error {
+	for _, weekday := range weekdays {
+		if day == weekday {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w, %q is not one of %s", ErrInvalidMeeting, day, strings.Join(weekdays, " "))
+}
+
+// clockMinute parses a 24 hour clock time like 13:45 into minutes after midnight
+func clockMinute(clock string) (int, error) {
+	t, err := time.Parse(clockLayout, strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("%w, %q is not a time like 13:45", ErrInvalidMeeting, clock)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Overlaps is true when both meetings are on the same day and share some time,
+// a meeting ending at 10:00 does not overlap one starting at 10:00. invalid meetings overlap nothing
+func (m *Meeting) Overlaps(other *Meeting) bool {
+	if m.Day != other.Day {
+		return false
+	}
+
+	start, end, err := m.minutes()
+	if err != nil {
+		return false
+	}
+
+	otherStart, otherEnd, err := other.minutes()
+	if err != nil {
+		return false
+	}
+
+	return start < otherEnd && otherStart < end
+}
+
+func (m *Meeting) String() string {
+	return fmt.Sprintf("%s %s-%s", m.Day, m.StartTime, m.EndTime)
+}
+
+// overlappingMeetings returns the first meeting in a that overlaps one in b along with that one, nils if none do
+func overlappingMeetings(a, b []*Meeting) (*Meeting, *Meeting) {
+	for _, meeting := range a {
+		for _, other := range b {
+			if meeting != other && meeting.Overlaps(other) {
+				return meeting, other
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// CheckLink refuses a teacher that teaches another offering at the same time and meetings that are invalid or
+// clash with the other offerings of the teacher. the teacher has to be loaded with the meetings of its offerings
+func (o *CourseOffering) CheckLink(field string, other interface{}, edge interface{}) error {
+	switch field {
+	case "Teacher":
+		teacher, _ := other.(*Teacher)
+		return checkTeacherSchedule(teacher, o, o.Meetings)
+	case "Meetings":
+		meeting, ok := edge.(*Meeting)
+		if !ok || meeting == nil {
+			return nil
+		}
+
+		_, _, err := meeting.minutes()
+		if err != nil {
+			return err
+		}
+
+		return checkTeacherSchedule(o.Teacher, o, []*Meeting{meeting})
+	}
+
+	return nil
+}
+
+// CheckLink refuses meetings while the room is taken by another one of the same term.
+// the meetings of the room have to be loaded with the term of their offering
+func (r *Room) CheckLink(field string, other interface{}, edge interface{}) error {
+	meeting, ok := edge.(*Meeting)
+	if field != "Meetings" || !ok || meeting == nil {
+		return nil
+	}
+
+	offering, _ := other.(*CourseOffering)
+
+	var meetings []*Meeting
+	for _, existing := range r.Meetings {
+		taught := existing.Start
+		if offering != nil && taught != nil && taught.Term != nil && offering.Term != nil && !sameTerm(taught.Term, offering.Term) {
+			continue
+		}
+
+		meetings = append(meetings, existing)
+	}
+
+	taken, _ := overlappingMeetings(meetings, []*Meeting{meeting})
+	if taken == nil {
+		return nil
+	}
+
+	by := "another course"
+	if taken.Start != nil {
+		by = taken.Start.Name
+	}
+
+	return fmt.Errorf("%w, %s is taken by %s %s", ErrScheduleConflict, r.Name, by, taken)
+}
+
+// CheckLink refuses enrolling in an offering of a course whose prerequisites the student has not passed, or that
+// meets at the same time as another offering the student is enrolled or waitlisted in for the same term.
+// the offering has to be loaded with the prerequisites of its course and the offerings of the student with
+// their course, term and meetings
+func (s *Student) CheckLink(field string, other interface{}, edge interface{}) error {
+	offering, ok := other.(*CourseOffering)
+	enrollment, _ := edge.(*Enrollment)
+	if field != "Enrollments" || !ok || enrollment == nil || !takesSeat(enrollment) {
+		return nil
+	}
+
+	if offering.Course != nil {
+		err := checkPrerequisites(s, offering.Course)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, existing := range s.Enrollments {
+		taken := existing.End
+		if existing == enrollment || taken == nil || sameCourseOffering(taken, offering) || !takesSeat(existing) {
+			continue
+		}
+
+		if taken.Term != nil && offering.Term != nil && !sameTerm(taken.Term, offering.Term) {
+			continue
+		}
+
+		if meeting, overlapped := overlappingMeetings(offering.Meetings, taken.Meetings); meeting != nil {
+			return fmt.Errorf("%w, %s takes %s %s which overlaps %s %s",
+				ErrScheduleConflict, s.Name, taken.Name, overlapped, offering.Name, meeting)
+		}
+	}
+
+	return nil
+}
+
+// takesSeat is true for enrollments that block the time of their offering, waitlisted students can get a seat any time
+func takesSeat(enrollment *Enrollment) bool {
+	return enrollment.Active() || enrollment.Waitlisted()
+}
+
+// CheckPendingLink refuses an offering that meets at the same time as another offering linked in the same call.
+// the offerings are checked against the ones the teacher already teaches by CourseOffering.CheckLink
+func (t *Teacher) CheckPendingLink(field string, other interface{}, pending []interface{}) error {
+	offering, ok := other.(*CourseOffering)
+	if field != "Offerings" || !ok {
+		return nil
+	}
+
+	var offerings []*CourseOffering
+	for _, p := range pending {
+		if taught, ok := p.(*CourseOffering); ok {
+			offerings = append(offerings, taught)
+		}
+	}
+
+	return checkTaughtOfferings(t, offerings, offering, offering.Meetings)
+}
+
+// checkTeacherSchedule makes sure none of the other offerings of teacher overlap meetings of offering.
+// offerings in different terms never overlap
+func checkTeacherSchedule(teacher *Teacher, offering *CourseOffering, meetings []*Meeting) error {
+	if teacher == nil {
+		return nil
+	}
+
+	return checkTaughtOfferings(teacher, teacher.Offerings, offering, meetings)
+}
+
+// checkTaughtOfferings makes sure none of offerings taught by teacher overlap meetings of offering
+func checkTaughtOfferings(teacher *Teacher, offerings []*CourseOffering, offering *CourseOffering, meetings []*Meeting) error {
+	for _, taught := range offerings {
+		if sameCourseOffering(taught, offering) {
+			continue
+		}
+
+		if taught.Term != nil && offering.Term != nil && !sameTerm(taught.Term, offering.Term) {
+			continue
+		}
+
+		if meeting, taken := overlappingMeetings(meetings, taught.Meetings); meeting != nil {
+			return fmt.Errorf("%w, %s teaches %s %s which overlaps %s %s",
+				ErrScheduleConflict, teacher.Name, taught.Name, taken, offering.Name, meeting)
+		}
+	}
+
+	return nil
+}
+
+// Schedule adds the weekly meeting of offering in room, the offering has to be loaded with its meetings and the
+// room with its own. fails with ErrScheduleConflict if the room is taken or the teacher teaches another offering then
+func (r *Registrar) Schedule(offering *CourseOffering, room *Room, meeting *Meeting) error {
+	// the room is only taken by offerings of the same term
+	var booked []*CourseOffering
+	for _, existing := range room.Meetings {
+		if existing.Start != nil {
+			booked = append(booked, existing.Start)
+		}
+	}
+
+	err := r.loadOfferings(booked)
+	if err != nil {
+		return err
+	}
+
+	if offering.Teacher != nil {
+		teacher, err := r.teachers.Get(offering.Teacher.UUID)
+		if err != nil {
+			return err
+		}
+
+		err = r.loadOfferings(teacher.Offerings)
+		if err != nil {
+			return err
+		}
+
+		// link the teacher with its schedule so the linking checks see it
+		offering.Teacher.Offerings = teacher.Offerings
+	}
+
+	err = offering.LinkToRoomOnFieldMeetingsWith(&LinkOptions{Duplicates: AllowDuplicates}, room, meeting)
+	if err != nil {
+		return err
+	}
+
+	return r.offerings.Save(offering)
+}
+
+// Unschedule removes a meeting of offering
+func (r *Registrar) Unschedule(offering *CourseOffering, meeting *Meeting) error {
+	err := offering.UnlinkFromRoomOnFieldMeetingsByEdge(meeting)
+	if err != nil {
+		return err
+	}
+
+	return r.offerings.Save(offering)
+}
+
+// AssignTeacher makes teacher teach offering, the teacher has to be loaded with its offerings.
+// fails with ErrScheduleConflict if the teacher teaches another offering at the same time
+func (r *Registrar) AssignTeacher(offering *CourseOffering, teacher *Teacher) error {
+	err := r.loadOfferings(teacher.Offerings)
+	if err != nil {
+		return err
+	}
+
+	err = offering.LinkToTeacherOnFieldTeacher(teacher)
+	if err != nil {
+		return err
+	}
+
+	return r.offerings.Save(offering)
+}
+
+// loadStudentSchedule loads the course, term and meetings of the offerings student has enrollments in
+func (r *Registrar) loadStudentSchedule(student *Student) error {
+	var offerings []*CourseOffering
+	for _, enrollment := range student.Enrollments {
+		if enrollment.End != nil {
+			offerings = append(offerings, enrollment.End)
+		}
+	}
+
+	return r.loadOfferings(offerings)
+}
+
+// loadOfferings loads the course, term and meetings of offerings that were loaded without their relationships
+func (r *Registrar) loadOfferings(offerings []*CourseOffering) error {
+	for _, offering := range offerings {
+		if offering.Course != nil || offering.UUID == "" {
+			continue
+		}
+
+		loaded, err := r.offerings.Get(offering.UUID)
+		if err != nil {
+			return err
+		}
+
+		offering.Course = loaded.Course
+		offering.Term = loaded.Term
+		offering.Meetings = loaded.Meetings
+	}
+
+	return nil
+}
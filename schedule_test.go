@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewMeeting(t *testing.T) {
+	for _, tt := range []struct {
+		day, start, end string
+		want            string
+	}{
+		{"Monday", "9:00", "10:30", "monday 09:00-10:30"},
+		{" friday ", "13:05", "14:00", "friday 13:05-14:00"},
+		{"sunday", "00:00", "23:59", "sunday 00:00-23:59"},
+		{"someday", "9:00", "10:00", ""},
+		{"mon", "9:00", "10:00", ""},
+		{"monday", "9am", "10:00", ""},
+		{"monday", "9:00", "24:00", ""},
+		{"monday", "10:00", "10:00", ""},
+		{"monday", "10:00", "9:00", ""},
+	} {
+		meeting, err := NewMeeting(tt.day, tt.start, tt.end)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidMeeting) {
+				t.Errorf("NewMeeting(%q, %q, %q) got %v, want ErrInvalidMeeting", tt.day, tt.start, tt.end, err)
+			}
+			continue
+		}
+
+		if err != nil || meeting.String() != tt.want {
+			t.Errorf("NewMeeting(%q, %q, %q) = %v, %v, want %s", tt.day, tt.start, tt.end, meeting, err, tt.want)
+		}
+	}
+}
+
+func TestMeetingOverlaps(t *testing.T) {
+	for _, tt := range []struct {
+		a, b *Meeting
+		want bool
+	}{
+		{&Meeting{Day: "monday", StartTime: "09:00", EndTime: "10:30"}, &Meeting{Day: "monday", StartTime: "10:00", EndTime: "11:00"}, true},
+		{&Meeting{Day: "monday", StartTime: "09:00", EndTime: "12:00"}, &Meeting{Day: "monday", StartTime: "10:00", EndTime: "11:00"}, true},
+		{&Meeting{Day: "monday", StartTime: "09:00", EndTime: "10:00"}, &Meeting{Day: "monday", StartTime: "10:00", EndTime: "11:00"}, false},
+		{&Meeting{Day: "monday", StartTime: "09:00", EndTime: "10:30"}, &Meeting{Day: "tuesday", StartTime: "09:00", EndTime: "10:30"}, false},
+		{&Meeting{Day: "monday", StartTime: "09:00", EndTime: "10:30"}, &Meeting{Day: "monday", StartTime: "10:30", EndTime: "09:00"}, false},
+	} {
+		if got := tt.a.Overlaps(tt.b); got != tt.want {
+			t.Errorf("%v overlaps %v: got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.b.Overlaps(tt.a); got != tt.want {
+			t.Errorf("%v overlaps %v: got %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleConflicts(t *testing.T) {
+	sess, school := seedTestSchool(t)
+	repos := NewRepositories(sess)
+	registrar := NewRegistrar(sess)
+
+	spring := &Term{Name: "Spring 2027"}
+	err := repos.Terms.Save(spring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = registrar.Offer(school.Courses["cs341"], spring, "", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offering := func(name string) *CourseOffering {
+		found, err := repos.Offerings.FindByName(name)
+		if err != nil || len(found) != 1 {
+			t.Fatalf("finding %s: %v", name, err)
+		}
+		return found[0]
+	}
+	room := func(name string) *Room {
+		found, err := repos.Rooms.FindByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	schedule := func(offeringName, roomName, day, start, end string) func() error {
+		return func() error {
+			return registrar.Schedule(offering(offeringName), room(roomName), &Meeting{Day: day, StartTime: start, EndTime: end})
+		}
+	}
+	assign := func(offeringName, teacherName string) func() error {
+		return func() error {
+			teacher, err := repos.Teachers.FindByName(teacherName)
+			if err != nil {
+				return err
+			}
+			return registrar.AssignTeacher(offering(offeringName), teacher)
+		}
+	}
+	enroll := func(studentName, offeringName string) func() error {
+		return func() error {
+			student, err := repos.Students.FindByName(studentName)
+			if err != nil {
+				return err
+			}
+			return registrar.Enroll(student, offering(offeringName), NewEnrollment(time.Now().UTC()))
+		}
+	}
+
+	// the steps run in order against the seeded schedule
+	for _, tt := range []struct {
+		name string
+		step func() error
+		want error
+	}{
+		{"invalid meeting", schedule("phys122-0 Fall 2026", "Gates 101", "someday", "10:00", "11:00"), ErrInvalidMeeting},
+		{"room taken", schedule("phys122-0 Fall 2026", "Gates 101", "monday", "10:00", "11:00"), ErrScheduleConflict},
+		{"room free right after", schedule("phys122-0 Fall 2026", "Gates 101", "monday", "10:30", "11:00"), nil},
+		{"room free on another day", schedule("phys122-0 Fall 2026", "Baker 210", "wednesday", "09:30", "10:00"), nil},
+		{"teacher teaches then", assign("phys122-0 Fall 2026", "Oates"), ErrScheduleConflict},
+		{"teacher is free", assign("phys122-0 Fall 2026", "Crosby"), nil},
+		{"meeting while the teacher teaches", schedule("hist347-0 Fall 2026", "Gates 101", "friday", "11:00", "12:00"), ErrScheduleConflict},
+		{"student takes a course then", enroll("steven", "phys122-0 Fall 2026"), ErrScheduleConflict},
+		{"other term", schedule("cs341-0 Spring 2027", "Gates 101", "monday", "09:00", "10:30"), nil},
+		{"teacher in another term", assign("cs341-0 Spring 2027", "Oates"), nil},
+		{"student in another term", enroll("steven", "cs341-0 Spring 2027"), nil},
+	} {
+		err := tt.step()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// refused meetings are not saved
+	if got := len(offering("phys122-0 Fall 2026").Meetings); got != 3 {
+		t.Errorf("phys122-0 has %v meetings, want 3", got)
+	}
+}
+
+func TestTeacherOfferingsLinkedTogether(t *testing.T) {
+	fall, spring := &Term{Name: exampleTerm}, &Term{Name: "Spring 2027"}
+
+	offering := func(course string, term *Term, day string) *CourseOffering {
+		offering, err := NewCourseOffering(&Course{Name: course}, term, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		meeting, err := NewMeeting(day, "09:00", "10:30")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = offering.LinkToRoomOnFieldMeetings(&Room{Name: course + " room"}, meeting)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return offering
+	}
+
+	for _, tt := range []struct {
+		name      string
+		offerings []*CourseOffering
+		want      error
+	}{
+		{"same time", []*CourseOffering{offering("cs341", fall, "monday"), offering("cs342", fall, "monday")}, ErrScheduleConflict},
+		{"other day", []*CourseOffering{offering("cs341", fall, "monday"), offering("cs342", fall, "tuesday")}, nil},
+		{"other term", []*CourseOffering{offering("cs341", fall, "monday"), offering("cs342", spring, "monday")}, nil},
+	} {
+		teacher := &Teacher{Name: "Oates"}
+		err := teacher.LinkToCourseOfferingOnFieldOfferings(tt.offerings...)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+
+		var linkErr *LinkError
+		if tt.want != nil && (!errors.As(err, &linkErr) || linkErr.Index != 1 || len(teacher.Offerings) != 0) {
+			t.Errorf("%s: got %v with %v offerings linked, want the second target refused and none linked", tt.name, err, len(teacher.Offerings))
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package main
+
+import (
+	"fmt"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// directions that can be declared on a gogm relationship field
+const (
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+	directionBoth     = "both"
+	directionNone     = "none"
+)
+
+var edgeInterfaceType = reflect.TypeOf((*gogm.IEdge)(nil)).Elem()
+
+// propertyField describes a field that is stored as a property on a node or edge
+type propertyField struct {
+	// FieldName is the name of the go struct field
+	FieldName string
+	// Name is the name of the property in neo4j
+	Name string
+	// Index is the field index path, used with reflect.Value.FieldByIndex
+	Index []int
+
+	Unique     bool
+	Time       bool
+	Properties bool
+}
+
+// relationshipField describes a field that gogm maps to a relationship
+type relationshipField struct {
+	// FieldName is the name of the go struct field
+	FieldName string
+	// Index is the field index path, used with reflect.Value.FieldByIndex
+	Index []int
+
+	Relationship string
+	Direction    string
+
+	// Many is true when the field is a slice
+	Many bool
+	// Target is the struct type of the node on the other side of the relationship
+	Target reflect.Type
+	// Edge is the struct type of the special edge, nil if the relationship has no properties
+	Edge reflect.Type
+}
+
+// nodeSchema is the gogm mapping of a node or edge struct, read from its struct tags
+type nodeSchema struct {
+	Label string
+	Type  reflect.Type
+
+	Properties    []*propertyField
+	Relationships []*relationshipField
+
+	// IsEdge is true for structs implementing gogm.IEdge
+	IsEdge bool
+	// Start and End are the node types an edge connects
+	Start reflect.Type
+	End   reflect.Type
+	// StartIndex and EndIndex are the fields holding the edge endpoints
+	StartIndex []int
+	EndIndex   []int
+}
+
+// property returns the property field stored under the neo4j name
+func (n *nodeSchema) property(name string) *propertyField {
+	for _, prop := range n.Properties {
+		if prop.Name == name {
+			return prop
+		}
+	}
+
+	return nil
+}
+
+// relationshipTo finds the field a relationship of type rel in direction dir to other is stored in
+func (n *nodeSchema) relationshipTo(rel, dir string, other reflect.Type) *relationshipField {
+	for _, field := range n.Relationships {
+		if field.Relationship != rel || (field.Direction != dir && field.Direction != directionBoth) {
+			continue
+		}
+
+		if field.Target == other {
+			return field
+		}
+	}
+
+	return nil
+}
+
+var schemaCache = struct {
+	sync.Mutex
+	schemas map[reflect.Type]*nodeSchema
+}{schemas: map[reflect.Type]*nodeSchema{}}
+
+// schemaFor reads the gogm mapping of t, which can be a struct or a pointer to a struct
+func schemaFor(t reflect.Type) (*nodeSchema, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can not build schema for non struct type [%s]", t)
+	}
+
+	schemaCache.Lock()
+	defer schemaCache.Unlock()
+
+	if schema, ok := schemaCache.schemas[t]; ok {
+		return schema, nil
+	}
+
+	schema := &nodeSchema{
+		Label:  t.Name(),
+		Type:   t,
+		IsEdge: reflect.PtrTo(t).Implements(edgeInterfaceType),
+	}
+
+	if schema.IsEdge {
+		edge := reflect.New(t).Interface().(gogm.IEdge)
+		schema.Start = edge.GetStartNodeType().Elem()
+		schema.End = edge.GetEndNodeType().Elem()
+	}
+
+	err := schema.readFields(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaCache.schemas[t] = schema
+	return schema, nil
+}
+
+func (n *nodeSchema) readFields(t reflect.Type, parent []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		tag, ok := field.Tag.Lookup("gogm")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := n.readFields(field.Type, index); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if n.IsEdge && field.Type.Kind() == reflect.Ptr {
+				switch field.Type.Elem() {
+				case n.Start:
+					n.StartIndex = index
+				case n.End:
+					n.EndIndex = index
+				}
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		params := parseTag(tag)
+		rel, isRel := params["relationship"]
+		if !isRel {
+			_, unique := params["unique"]
+			_, isTime := params["time"]
+			_, isProps := params["properties"]
+			n.Properties = append(n.Properties, &propertyField{
+				FieldName:  field.Name,
+				Name:       params["name"],
+				Index:      index,
+				Unique:     unique,
+				Time:       isTime,
+				Properties: isProps,
+			})
+			continue
+		}
+
+		relField := &relationshipField{
+			FieldName:    field.Name,
+			Index:        index,
+			Relationship: rel,
+			Direction:    params["direction"],
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Slice {
+			relField.Many = true
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() != reflect.Ptr || fieldType.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("relationship field %s.%s must be a pointer or slice of pointers to a struct", n.Label, field.Name)
+		}
+
+		if fieldType.Implements(edgeInterfaceType) {
+			relField.Edge = fieldType.Elem()
+			edge := reflect.New(relField.Edge).Interface().(gogm.IEdge)
+			// the target of an edge field is whichever end of the edge is not this node
+			if relField.Direction == directionIncoming {
+				relField.Target = edge.GetStartNodeType().Elem()
+			} else {
+				relField.Target = edge.GetEndNodeType().Elem()
+			}
+		} else {
+			relField.Target = fieldType.Elem()
+		}
+
+		n.Relationships = append(n.Relationships, relField)
+	}
+
+	return nil
+}
+
+// parseTag splits a gogm struct tag into its parameters, flags like unique have an empty value
+func parseTag(tag string) map[string]string {
+	params := map[string]string{}
+	for _, part := range strings.Split(tag, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		} else {
+			params[kv[0]] = ""
+		}
+	}
+
+	return params
+}
+
+// SchemaError lists every problem found by ValidateSchema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%v problems in the schema:\n%s", len(e.Problems), strings.Join(e.Problems, "\n"))
+}
+
+// schemaRelationship is a relationship field found by ValidateSchema
+type schemaRelationship struct {
+	Owner        reflect.Type
+	Field        string
+	Relationship string
+	Direction    string
+	Target       reflect.Type
+	Edge         reflect.Type
+}
+
+func (r *schemaRelationship) String() string {
+	return r.Owner.Name() + "." + r.Field
+}
+
+// schemaValidator collects the problems of the types passed to ValidateSchema
+type schemaValidator struct {
+	problems   []string
+	registered map[reflect.Type]bool
+	// edges maps the valid edge types to the node types they start and end at
+	edges map[reflect.Type][2]reflect.Type
+	// used are the edge types referenced by a relationship field
+	used map[reflect.Type]bool
+}
+
+func (v *schemaValidator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// ValidateSchema checks the gogm tags of types, the pointers passed to gogm.Init, without connecting to neo4j.
+// every relationship field needs exactly one field with the same relationship and the opposite direction on the
+// other node, and edges like Enrollment have to connect the nodes whose fields reference them.
+// all problems are returned at once as a *SchemaError
+func ValidateSchema(types ...interface{}) error {
+	v := &schemaValidator{
+		registered: map[reflect.Type]bool{},
+		edges:      map[reflect.Type][2]reflect.Type{},
+		used:       map[reflect.Type]bool{},
+	}
+
+	var structs []reflect.Type
+	for _, typ := range types {
+		t := reflect.TypeOf(typ)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			v.addf("%T is not a pointer to a struct", typ)
+			continue
+		}
+
+		if v.registered[t.Elem()] {
+			v.addf("%s is registered twice", t.Elem().Name())
+			continue
+		}
+
+		v.registered[t.Elem()] = true
+		structs = append(structs, t.Elem())
+	}
+
+	// edges are checked first, the relationship fields referencing them need to know their ends
+	for _, t := range structs {
+		if reflect.PtrTo(t).Implements(edgeInterfaceType) {
+			v.checkEdge(t)
+		}
+	}
+
+	var rels []*schemaRelationship
+	for _, t := range structs {
+		if field, ok := t.FieldByName("BaseNode"); !ok || !field.Anonymous || field.Type != reflect.TypeOf(gogm.BaseNode{}) {
+			v.addf("%s does not embed gogm.BaseNode", t.Name())
+		}
+
+		rels = append(rels, v.checkFields(t, t, map[string]string{})...)
+	}
+
+	for _, t := range structs {
+		if _, ok := v.edges[t]; ok && !v.used[t] {
+			v.addf("%s is not used by any relationship field", t.Name())
+		}
+	}
+
+	for _, rel := range rels {
+		v.checkCounterpart(rel, rels)
+	}
+
+	if len(v.problems) != 0 {
+		return &SchemaError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+// checkEdge checks that the edge t connects registered nodes and keeps the nodes it is given
+func (v *schemaValidator) checkEdge(t reflect.Type) {
+	var ends [2]reflect.Type
+	for i, end := range []struct {
+		name                 string
+		typeMethod, set, get string
+		getType              func(gogm.IEdge) reflect.Type
+		setNode              func(gogm.IEdge, interface{}) error
+		getNode              func(gogm.IEdge) interface{}
+	}{
+		{"start", "GetStartNodeType", "SetStartNode", "GetStartNode", gogm.IEdge.GetStartNodeType, gogm.IEdge.SetStartNode, gogm.IEdge.GetStartNode},
+		{"end", "GetEndNodeType", "SetEndNode", "GetEndNode", gogm.IEdge.GetEndNodeType, gogm.IEdge.SetEndNode, gogm.IEdge.GetEndNode},
+	} {
+		edge := reflect.New(t).Interface().(gogm.IEdge)
+
+		var nodeType reflect.Type
+		err := recoverPanic(func() error {
+			nodeType = end.getType(edge)
+			return nil
+		})
+		if err == nil && (nodeType == nil || nodeType.Kind() != reflect.Ptr || nodeType.Elem().Kind() != reflect.Struct) {
+			err = fmt.Errorf("returned %v instead of a pointer to a struct", nodeType)
+		}
+		if err != nil {
+			v.addf("%s.%s: %v", t.Name(), end.typeMethod, err)
+			return
+		}
+
+		if !v.registered[nodeType.Elem()] {
+			v.addf("%s %ss at %s, which is not registered", t.Name(), end.name, nodeType.Elem().Name())
+			return
+		}
+
+		if _, ok := fieldOfType(t, nodeType); !ok {
+			v.addf("%s has no field of type %s for its %s node", t.Name(), nodeType, end.name)
+		}
+
+		node := reflect.New(nodeType.Elem()).Interface()
+		err = recoverPanic(func() error { return end.setNode(edge, node) })
+		if err != nil {
+			v.addf("%s.%s refuses a %s: %v", t.Name(), end.set, nodeType, err)
+			return
+		}
+
+		var got interface{}
+		_ = recoverPanic(func() error {
+			got = end.getNode(edge)
+			return nil
+		})
+		if got != node {
+			v.addf("%s.%s does not return the node given to %s", t.Name(), end.get, end.set)
+		}
+
+		err = recoverPanic(func() error { return end.setNode(edge, &struct{}{}) })
+		if err == nil {
+			v.addf("%s.%s accepts nodes that are not a %s", t.Name(), end.set, nodeType)
+		}
+
+		ends[i] = nodeType.Elem()
+	}
+
+	v.edges[t] = ends
+}
+
+// checkFields checks the properties and relationship fields of owner declared in t and its embedded structs.
+// names maps the neo4j property names already seen to their fields
+func (v *schemaValidator) checkFields(owner, t reflect.Type, names map[string]string) []*schemaRelationship {
+	var rels []*schemaRelationship
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup("gogm")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				rels = append(rels, v.checkFields(owner, field.Type, names)...)
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		params := parseTag(tag)
+		relationship, isRel := params["relationship"]
+		if !isRel {
+			name := params["name"]
+			if name == "" {
+				v.addf("%s.%s has no property name", owner.Name(), field.Name)
+			} else if other, ok := names[name]; ok {
+				v.addf("%s.%s and %s.%s are both stored as %s", owner.Name(), other, owner.Name(), field.Name, name)
+			} else {
+				names[name] = field.Name
+			}
+			continue
+		}
+
+		rel := &schemaRelationship{Owner: owner, Field: field.Name, Relationship: relationship, Direction: params["direction"]}
+		if relationship == "" {
+			v.addf("%s has no relationship name", rel)
+			continue
+		}
+
+		switch rel.Direction {
+		case directionIncoming, directionOutgoing, directionBoth, directionNone:
+		default:
+			v.addf("%s: direction of %s must be incoming, outgoing, both or none, found %q", rel, relationship, rel.Direction)
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() != reflect.Ptr || fieldType.Elem().Kind() != reflect.Struct {
+			v.addf("%s must be a pointer or a slice of pointers to a struct", rel)
+			continue
+		}
+
+		if !v.registered[fieldType.Elem()] {
+			v.addf("%s: %s is not registered", rel, fieldType.Elem().Name())
+			continue
+		}
+
+		rel.Target = fieldType.Elem()
+		if fieldType.Implements(edgeInterfaceType) {
+			ends, ok := v.edges[fieldType.Elem()]
+			if !ok {
+				// the problem with the edge itself has already been reported
+				continue
+			}
+
+			rel.Edge = fieldType.Elem()
+			v.used[rel.Edge] = true
+
+			// incoming edges end at the node of the field and outgoing ones start there
+			switch {
+			case rel.Direction == directionIncoming && ends[1] == owner:
+				rel.Target = ends[0]
+			case rel.Direction != directionIncoming && ends[0] == owner:
+				rel.Target = ends[1]
+			case rel.Direction == directionBoth && ends[1] == owner:
+				rel.Target = ends[0]
+			default:
+				v.addf("%s: %s %s edges go from %s to %s, not %s", rel, rel.Direction, rel.Edge.Name(), ends[0].Name(), ends[1].Name(), describeEnd(rel.Direction, owner))
+				continue
+			}
+		}
+
+		rels = append(rels, rel)
+	}
+
+	return rels
+}
+
+// checkCounterpart checks that exactly one field of the other node holds rel in the opposite direction
+func (v *schemaValidator) checkCounterpart(rel *schemaRelationship, rels []*schemaRelationship) {
+	var matches, nearMisses []string
+	for _, other := range rels {
+		if other == rel || other.Owner != rel.Target || other.Target != rel.Owner || other.Relationship != rel.Relationship {
+			continue
+		}
+
+		switch {
+		case other.Edge != rel.Edge:
+			nearMisses = append(nearMisses, fmt.Sprintf("%s uses %s", other, edgeName(other.Edge)))
+		case other.Direction != oppositeDirection(rel.Direction):
+			nearMisses = append(nearMisses, fmt.Sprintf("%s is %s", other, other.Direction))
+		default:
+			matches = append(matches, other.String())
+		}
+	}
+
+	switch {
+	case len(matches) > 1:
+		v.addf("%s: %s %s matches more than one field, %s", rel, rel.Direction, rel.Relationship, strings.Join(matches, ", "))
+	case len(matches) == 0 && rel.Target == rel.Owner && rel.Direction == oppositeDirection(rel.Direction):
+		// a both or none relationship between nodes of the same type can be held by the field alone
+	case len(matches) == 0 && len(nearMisses) != 0:
+		v.addf("%s: %s %s has no %s field on %s, %s", rel, rel.Direction, rel.Relationship,
+			oppositeDirection(rel.Direction), rel.Target.Name(), strings.Join(nearMisses, ", "))
+	case len(matches) == 0:
+		v.addf("%s: %s %s has no %s field on %s", rel, rel.Direction, rel.Relationship, oppositeDirection(rel.Direction), rel.Target.Name())
+	}
+}
+
+// oppositeDirection is the direction the other end of a relationship in direction has
+func oppositeDirection(direction string) string {
+	switch direction {
+	case directionIncoming:
+		return directionOutgoing
+	case directionOutgoing:
+		return directionIncoming
+	}
+
+	return direction
+}
+
+// describeEnd names the end of an edge a node with a field in direction would have to be
+func describeEnd(direction string, node reflect.Type) string {
+	if direction == directionIncoming {
+		return "to " + node.Name()
+	}
+	return "from " + node.Name()
+}
+
+// edgeName names the edge type of a relationship for problems
+func edgeName(edge reflect.Type) string {
+	if edge == nil {
+		return "no edge"
+	}
+	return "the edge " + edge.Name()
+}
+
+// fieldOfType returns the untagged field of t holding a value of typ, like the Start and End fields of edges
+func fieldOfType(t, typ reflect.Type) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, tagged := field.Tag.Lookup("gogm"); !tagged && field.Type == typ {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// recoverPanic calls f and turns a panic into an error, edge methods assert the types they are given
+func recoverPanic(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return f()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// the types below are only registered with ValidateSchema, never saved
+
+type schemaTestParent struct {
+	gogm.BaseNode
+
+	Name     string             `gogm:"name=name"`
+	Children []*schemaTestChild `gogm:"direction=outgoing;relationship=PARENT_OF"`
+}
+
+type schemaTestChild struct {
+	gogm.BaseNode
+
+	Parent *schemaTestParent `gogm:"direction=incoming;relationship=PARENT_OF"`
+}
+
+type schemaTestBroken struct {
+	gogm.BaseNode
+
+	Name     string             `gogm:"name=name"`
+	Title    string             `gogm:"name=name"`
+	Untitled string             `gogm:"index"`
+	Ignored  string             `gogm:"-"`
+	Sideways *schemaTestChild   `gogm:"direction=sideways;relationship=SIDEWAYS"`
+	Value    schemaTestChild    `gogm:"direction=outgoing;relationship=VALUE"`
+	Orphan   *schemaTestOrphan  `gogm:"direction=outgoing;relationship=ORPHAN"`
+	Lonely   []*schemaTestChild `gogm:"direction=outgoing;relationship=LONELY"`
+	Nameless []*schemaTestChild `gogm:"direction=outgoing;relationship="`
+}
+
+type schemaTestOrphan struct {
+	gogm.BaseNode
+}
+
+type schemaTestNoBase struct {
+	Name string `gogm:"name=name"`
+}
+
+// schemaTestEdge accepts any node as its start
+type schemaTestEdge struct {
+	gogm.BaseNode
+
+	Start *schemaTestParent
+	End   *schemaTestChild
+}
+
+func (e *schemaTestEdge) GetStartNode() interface{} { return e.Start }
+
+func (e *schemaTestEdge) GetStartNodeType() reflect.Type { return reflect.TypeOf(&schemaTestParent{}) }
+
+func (e *schemaTestEdge) SetStartNode(v interface{}) error {
+	e.Start, _ = v.(*schemaTestParent)
+	return nil
+}
+
+func (e *schemaTestEdge) GetEndNode() interface{} { return e.End }
+
+func (e *schemaTestEdge) GetEndNodeType() reflect.Type { return reflect.TypeOf(&schemaTestChild{}) }
+
+func (e *schemaTestEdge) SetEndNode(v interface{}) error {
+	end, ok := v.(*schemaTestChild)
+	if !ok {
+		return ErrEdgeTypeMismatch
+	}
+	e.End = end
+	return nil
+}
+
+func TestValidateSchema(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		types []interface{}
+		want  []string
+	}{
+		{
+			name:  "registered types",
+			types: registeredTypes,
+		},
+		{
+			name:  "parent and child",
+			types: []interface{}{&schemaTestParent{}, &schemaTestChild{}},
+		},
+		{
+			name:  "registration",
+			types: []interface{}{schemaTestOrphan{}, &schemaTestOrphan{}, &schemaTestOrphan{}, &schemaTestNoBase{}},
+			want: []string{
+				"main.schemaTestOrphan is not a pointer to a struct",
+				"schemaTestOrphan is registered twice",
+				"schemaTestNoBase does not embed gogm.BaseNode",
+			},
+		},
+		{
+			name:  "unregistered targets",
+			types: []interface{}{&schemaTestParent{}, &schemaTestOrphan{}, &Student{}},
+			want: []string{
+				"schemaTestParent.Children: schemaTestChild is not registered",
+				"Student.Enrollments: Enrollment is not registered",
+			},
+		},
+		{
+			name:  "fields",
+			types: []interface{}{&schemaTestParent{}, &schemaTestChild{}, &schemaTestBroken{}},
+			want: []string{
+				"schemaTestBroken.Name and schemaTestBroken.Title are both stored as name",
+				"schemaTestBroken.Untitled has no property name",
+				`schemaTestBroken.Sideways: direction of SIDEWAYS must be incoming, outgoing, both or none, found "sideways"`,
+				"schemaTestBroken.Value must be a pointer or a slice of pointers to a struct",
+				"schemaTestBroken.Orphan: schemaTestOrphan is not registered",
+				"schemaTestBroken.Nameless has no relationship name",
+				"schemaTestBroken.Lonely: outgoing LONELY has no incoming field on schemaTestChild",
+			},
+		},
+		{
+			name:  "edges",
+			types: []interface{}{&schemaTestParent{}, &schemaTestChild{}, &schemaTestEdge{}, &Enrollment{}},
+			want: []string{
+				"schemaTestEdge.SetStartNode accepts nodes that are not a *main.schemaTestParent",
+				"Enrollment starts at Student, which is not registered",
+				"schemaTestEdge is not used by any relationship field",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema(tt.types...)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("got %v, want a *SchemaError", err)
+			}
+
+			if !reflect.DeepEqual(schemaErr.Problems, tt.want) {
+				t.Errorf("got problems:\n%s\nwant:\n%s", strings.Join(schemaErr.Problems, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// snapshotVersion is written to every snapshot, snapshots of other versions are rejected
+const snapshotVersion = 1
+
+// ErrNotEmpty is returned when a snapshot is restored into a graph that already has nodes
+var ErrNotEmpty = errors.New("graph is not empty")
+
+// Snapshot is a copy of a whole graph, nodes and relationships refer to each other by uuid
+type Snapshot struct {
+	Version       int                     `json:"version"`
+	Nodes         []*SnapshotNode         `json:"nodes"`
+	Relationships []*SnapshotRelationship `json:"relationships"`
+}
+
+// SnapshotNode is one node, Type is its label
+type SnapshotNode struct {
+	Type       string                 `json:"type"`
+	UUID       string                 `json:"uuid"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SnapshotRelationship is one relationship, Edge is the label of the special edge storing its properties
+type SnapshotRelationship struct {
+	Type       string                 `json:"type"`
+	Start      string                 `json:"start"`
+	End        string                 `json:"end"`
+	Edge       string                 `json:"edge,omitempty"`
+	UUID       string                 `json:"uuid,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// snapshotLine is one line of a snapshot written as ndjson, the first line only holds the version
+type snapshotLine struct {
+	Version      int                   `json:"version,omitempty"`
+	Node         *SnapshotNode         `json:"node,omitempty"`
+	Relationship *SnapshotRelationship `json:"relationship,omitempty"`
+}
+
+// ExportGraph loads every node of types, passed as pointers like to gogm.Init, along with their relationships.
+// nodes are sorted by type and uuid and relationships by type, start, end and uuid so equal graphs give equal snapshots
+func ExportGraph(sess gogm.ISession, types ...interface{}) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Version:       snapshotVersion,
+		Nodes:         []*SnapshotNode{},
+		Relationships: []*SnapshotRelationship{},
+	}
+
+	// relationships are seen from both of their ends
+	seen := map[string]bool{}
+
+	for _, typ := range types {
+		schema, err := schemaFor(reflect.TypeOf(typ))
+		if err != nil {
+			return nil, err
+		}
+
+		if schema.IsEdge {
+			continue
+		}
+
+		nodes := reflect.New(reflect.SliceOf(reflect.PtrTo(schema.Type)))
+		err = sess.LoadAllDepth(nodes.Interface(), 1)
+		if errors.Is(err, gogm.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < nodes.Elem().Len(); i++ {
+			node := nodes.Elem().Index(i)
+			uuid := baseNodeOf(node).UUID
+
+			snapshot.Nodes = append(snapshot.Nodes, &SnapshotNode{
+				Type:       schema.Label,
+				UUID:       uuid,
+				Properties: snapshotProperties(schema, node.Elem()),
+			})
+
+			for _, field := range schema.Relationships {
+				for _, target := range relationshipTargets(node.Elem(), field) {
+					rel := &SnapshotRelationship{Type: field.Relationship}
+
+					other := target
+					if field.Edge != nil {
+						edgeSchema, err := schemaFor(field.Edge)
+						if err != nil {
+							return nil, err
+						}
+
+						rel.Edge = edgeSchema.Label
+						rel.UUID = baseNodeOf(target).UUID
+						rel.Properties = snapshotProperties(edgeSchema, target.Elem())
+
+						edge := target.Interface().(gogm.IEdge)
+						if field.Direction == directionIncoming {
+							other = reflect.ValueOf(edge.GetStartNode())
+						} else {
+							other = reflect.ValueOf(edge.GetEndNode())
+						}
+					}
+
+					if field.Direction == directionIncoming {
+						rel.Start, rel.End = baseNodeOf(other).UUID, uuid
+					} else {
+						rel.Start, rel.End = uuid, baseNodeOf(other).UUID
+					}
+
+					key := rel.Type + "/" + rel.Start + "/" + rel.End + "/" + rel.UUID
+					if !seen[key] {
+						seen[key] = true
+						snapshot.Relationships = append(snapshot.Relationships, rel)
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(snapshot.Nodes, func(i, j int) bool {
+		a, b := snapshot.Nodes[i], snapshot.Nodes[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.UUID < b.UUID
+	})
+
+	sort.SliceStable(snapshot.Relationships, func(i, j int) bool {
+		a, b := snapshot.Relationships[i], snapshot.Relationships[j]
+		switch {
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		case a.End != b.End:
+			return a.End < b.End
+		default:
+			return a.UUID < b.UUID
+		}
+	})
+
+	return snapshot, nil
+}
+
+// snapshotProperties reads the properties of a node or edge, the graph id and uuid are left out
+func snapshotProperties(schema *nodeSchema, val reflect.Value) map[string]interface{} {
+	props := map[string]interface{}{}
+	for _, prop := range schema.Properties {
+		if prop.Name == "id" || prop.Name == "uuid" {
+			continue
+		}
+
+		value := val.FieldByIndex(prop.Index)
+		if value.IsZero() {
+			continue
+		}
+		props[prop.Name] = value.Interface()
+	}
+
+	if len(props) == 0 {
+		return nil
+	}
+
+	return props
+}
+
+// WriteJSON writes the snapshot as one indented json document
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteNDJSON writes the snapshot as newline delimited json, a version line followed by one line per node and relationship
+func (s *Snapshot) WriteNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	err := enc.Encode(&snapshotLine{Version: s.Version})
+	if err != nil {
+		return err
+	}
+
+	for _, node := range s.Nodes {
+		err = enc.Encode(&snapshotLine{Node: node})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range s.Relationships {
+		err = enc.Encode(&snapshotLine{Relationship: rel})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteJSON or WriteNDJSON
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	dec := json.NewDecoder(r)
+
+	var first json.RawMessage
+	err := dec.Decode(&first)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot, %w", err)
+	}
+
+	var keys map[string]json.RawMessage
+	err = json.Unmarshal(first, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot, %w", err)
+	}
+
+	snapshot := &Snapshot{}
+
+	_, hasNodes := keys["nodes"]
+	_, hasRelationships := keys["relationships"]
+	if hasNodes || hasRelationships {
+		err = json.Unmarshal(first, snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot, %w", err)
+		}
+	} else {
+		var line snapshotLine
+		err = json.Unmarshal(first, &line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot, %w", err)
+		}
+		snapshot.Version = line.Version
+
+		for n := 2; ; n++ {
+			var line snapshotLine
+			err = dec.Decode(&line)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to read line %v of snapshot, %w", n, err)
+			}
+
+			switch {
+			case line.Node != nil:
+				snapshot.Nodes = append(snapshot.Nodes, line.Node)
+			case line.Relationship != nil:
+				snapshot.Relationships = append(snapshot.Relationships, line.Relationship)
+			default:
+				return nil, fmt.Errorf("line %v of snapshot is neither a node nor a relationship", n)
+			}
+		}
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("snapshot version %v is not supported, expected %v", snapshot.Version, snapshotVersion)
+	}
+
+	return snapshot, nil
+}
+
+// RestoreGraph saves the snapshot into an empty graph in one transaction, keeping the uuids of every node and edge.
+// types are the node and edge types, passed as pointers like to gogm.Init. invalid nodes and edges are returned as
+// ValidationErrors before anything is saved
+func RestoreGraph(sess gogm.ISession, snapshot *Snapshot, types ...interface{}) error {
+	schemas := map[string]*nodeSchema{}
+	for _, typ := range types {
+		schema, err := schemaFor(reflect.TypeOf(typ))
+		if err != nil {
+			return err
+		}
+		schemas[schema.Label] = schema
+	}
+
+	nodes, err := restoreNodes(snapshot, schemas)
+	if err != nil {
+		return err
+	}
+
+	err = restoreRelationships(snapshot, schemas, nodes)
+	if err != nil {
+		return err
+	}
+
+	// like the repositories, nothing invalid is saved, every problem in the snapshot is reported at once
+	err = validateRestored(snapshot, nodes)
+	if err != nil {
+		return err
+	}
+
+	err = sess.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, schema := range schemas {
+		if schema.IsEdge {
+			continue
+		}
+
+		existing := reflect.New(reflect.SliceOf(reflect.PtrTo(schema.Type)))
+		err = sess.LoadAllDepth(existing.Interface(), 0)
+		if err == nil && existing.Elem().Len() != 0 {
+			return sess.RollbackWithError(fmt.Errorf("found %s nodes, %w", schema.Label, ErrNotEmpty))
+		} else if err != nil && !errors.Is(err, gogm.ErrNotFound) {
+			return sess.RollbackWithError(err)
+		}
+	}
+
+	// every relationship is written by its ends when they are saved to a depth of one
+	for _, node := range snapshot.Nodes {
+		err = sess.SaveDepth(nodes[node.UUID].Interface(), 1)
+		if err != nil {
+			return sess.RollbackWithError(fmt.Errorf("failed to save %s [%s], %w", node.Type, node.UUID, err))
+		}
+	}
+
+	err = sess.Commit()
+	if err != nil {
+		return sess.RollbackWithError(err)
+	}
+
+	return nil
+}
+
+// validateRestored validates every restored node and edge on its own, each edge from the node it starts at.
+// the paths of the failures start with the type and uuid of the node or edge, like Student[uuid].Name
+func validateRestored(snapshot *Snapshot, nodes map[string]reflect.Value) error {
+	var errs ValidationErrors
+	validate := func(val reflect.Value) error {
+		err := ValidateGraph(val.Interface(), 0)
+		var fieldErrs ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
+
+		label := val.Elem().Type().Name()
+		for _, fieldErr := range fieldErrs {
+			path := fmt.Sprintf("%s[%s]%s", label, baseNodeOf(val).UUID, strings.TrimPrefix(fieldErr.Path, label))
+			errs = append(errs, &FieldError{Path: path, Err: fieldErr.Err})
+		}
+		return nil
+	}
+
+	for _, node := range snapshot.Nodes {
+		val := nodes[node.UUID]
+		err := validate(val)
+		if err != nil {
+			return err
+		}
+
+		schema, _ := schemaFor(val.Type())
+		for _, field := range schema.Relationships {
+			if field.Edge == nil || field.Direction != directionOutgoing {
+				continue
+			}
+
+			for _, edge := range relationshipTargets(val.Elem(), field) {
+				err = validate(edge)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// restoreNodes creates the node structs of a snapshot by uuid
+func restoreNodes(snapshot *Snapshot, schemas map[string]*nodeSchema) (map[string]reflect.Value, error) {
+	nodes := map[string]reflect.Value{}
+
+	for _, node := range snapshot.Nodes {
+		schema, ok := schemas[node.Type]
+		if !ok || schema.IsEdge {
+			return nil, fmt.Errorf("node [%s] has unknown type %s", node.UUID, node.Type)
+		}
+
+		if node.UUID == "" {
+			return nil, fmt.Errorf("%s node without uuid", node.Type)
+		}
+
+		if _, ok := nodes[node.UUID]; ok {
+			return nil, fmt.Errorf("uuid [%s] is used by more than one node", node.UUID)
+		}
+
+		val, err := restoreStruct(schema, node.UUID, node.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("node [%s]: %w", node.UUID, err)
+		}
+
+		nodes[node.UUID] = val
+	}
+
+	return nodes, nil
+}
+
+// restoreRelationships links the restored nodes, relationships with an edge get a new edge struct
+func restoreRelationships(snapshot *Snapshot, schemas map[string]*nodeSchema, nodes map[string]reflect.Value) error {
+	for _, rel := range snapshot.Relationships {
+		start, ok := nodes[rel.Start]
+		if !ok {
+			return fmt.Errorf("%s relationship starts at unknown node [%s]", rel.Type, rel.Start)
+		}
+
+		end, ok := nodes[rel.End]
+		if !ok {
+			return fmt.Errorf("%s relationship ends at unknown node [%s]", rel.Type, rel.End)
+		}
+
+		startSchema, _ := schemaFor(start.Type())
+		endSchema, _ := schemaFor(end.Type())
+
+		startField := startSchema.relationshipTo(rel.Type, directionOutgoing, endSchema.Type)
+		endField := endSchema.relationshipTo(rel.Type, directionIncoming, startSchema.Type)
+		if startField == nil || endField == nil {
+			return fmt.Errorf("there is no %s relationship from %s to %s", rel.Type, startSchema.Label, endSchema.Label)
+		}
+
+		startTarget, endTarget := end, start
+
+		if startField.Edge != nil {
+			edgeSchema, ok := schemas[rel.Edge]
+			if !ok || edgeSchema.Type != startField.Edge {
+				return fmt.Errorf("%s relationship [%s] has edge %q, expected %s", rel.Type, rel.UUID, rel.Edge, startField.Edge.Name())
+			}
+
+			edge, err := restoreStruct(edgeSchema, rel.UUID, rel.Properties)
+			if err != nil {
+				return fmt.Errorf("%s relationship [%s]: %w", rel.Type, rel.UUID, err)
+			}
+
+			iedge := edge.Interface().(gogm.IEdge)
+			err = iedge.SetStartNode(start.Interface())
+			if err != nil {
+				return err
+			}
+
+			err = iedge.SetEndNode(end.Interface())
+			if err != nil {
+				return err
+			}
+
+			startTarget, endTarget = edge, edge
+		}
+
+		setRelationship(start.Elem(), startField, startTarget)
+		setRelationship(end.Elem(), endField, endTarget)
+	}
+
+	return nil
+}
+
+// restoreStruct creates a node or edge with uuid and the snapshot properties
+func restoreStruct(schema *nodeSchema, uuid string, props map[string]interface{}) (reflect.Value, error) {
+	val := reflect.New(schema.Type)
+	baseNodeOf(val).UUID = uuid
+
+	for name, value := range props {
+		prop := schema.property(name)
+		if prop == nil || prop.Name == "id" || prop.Name == "uuid" {
+			return reflect.Value{}, fmt.Errorf("%s has no property %s", schema.Label, name)
+		}
+
+		// properties went through json once already, going through it again restores times and maps
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		err = json.Unmarshal(raw, val.Elem().FieldByIndex(prop.Index).Addr().Interface())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("property %s: %w", name, err)
+		}
+	}
+
+	return val, nil
+}
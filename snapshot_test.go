@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		write  func(s *Snapshot, buf *bytes.Buffer) error
+	}{
+		{"json", func(s *Snapshot, buf *bytes.Buffer) error { return s.WriteJSON(buf) }},
+		{"ndjson", func(s *Snapshot, buf *bytes.Buffer) error { return s.WriteNDJSON(buf) }},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			sess, _ := seedTestSchool(t)
+
+			exported, err := ExportGraph(sess, registeredTypes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(exported.Nodes) != 24 {
+				t.Fatalf("exported %v nodes, want 24", len(exported.Nodes))
+			}
+
+			var want bytes.Buffer
+			err = tt.write(exported, &want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			read, err := ReadSnapshot(bytes.NewReader(want.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			restored := newTestSession(t)
+			err = RestoreGraph(restored, read, registeredTypes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := len(restored.read().rels), len(sess.read().rels); got != want {
+				t.Errorf("restored %v relationships, want %v", got, want)
+			}
+
+			again, err := ExportGraph(restored, registeredTypes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			err = tt.write(again, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("the restored graph exports differently, got:\n%s\nwant:\n%s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestRestoreGraphNotEmpty(t *testing.T) {
+	sess, _ := seedTestSchool(t)
+
+	snapshot, err := ExportGraph(sess, registeredTypes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := len(sess.read().nodes)
+	err = RestoreGraph(sess, snapshot, registeredTypes...)
+	if !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("got %v, want ErrNotEmpty", err)
+	}
+	if n := len(sess.read().nodes); n != nodes {
+		t.Errorf("the failed restore saved %v nodes", n-nodes)
+	}
+}
+
+func TestRestoreGraphErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		snapshot string
+		want     string
+	}{
+		{
+			name:     "unknown type",
+			snapshot: `{"version": 1, "nodes": [{"type": "Widget", "uuid": "w"}]}`,
+			want:     "node [w] has unknown type Widget",
+		},
+		{
+			name:     "duplicate uuid",
+			snapshot: `{"version": 1, "nodes": [{"type": "Student", "uuid": "s"}, {"type": "Room", "uuid": "s"}]}`,
+			want:     "uuid [s] is used by more than one node",
+		},
+		{
+			name:     "unknown property",
+			snapshot: `{"version": 1, "nodes": [{"type": "Student", "uuid": "s", "properties": {"age": 7}}]}`,
+			want:     "node [s]: Student has no property age",
+		},
+		{
+			name:     "dangling relationship",
+			snapshot: `{"version": 1, "nodes": [{"type": "Student", "uuid": "s"}], "relationships": [{"type": "ENROLLED", "start": "s", "end": "o"}]}`,
+			want:     "ENROLLED relationship ends at unknown node [o]",
+		},
+		{
+			name:     "wrong direction",
+			snapshot: `{"version": 1, "nodes": [{"type": "Student", "uuid": "s"}, {"type": "Room", "uuid": "r"}], "relationships": [{"type": "ENROLLED", "start": "r", "end": "s"}]}`,
+			want:     "there is no ENROLLED relationship from Room to Student",
+		},
+		{
+			name:     "missing edge",
+			snapshot: `{"version": 1, "nodes": [{"type": "Student", "uuid": "s"}, {"type": "CourseOffering", "uuid": "o"}], "relationships": [{"type": "ENROLLED", "start": "s", "end": "o"}]}`,
+			want:     `ENROLLED relationship [] has edge "", expected Enrollment`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := ReadSnapshot(strings.NewReader(tt.snapshot))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sess := newTestSession(t)
+			err = RestoreGraph(sess, snapshot, registeredTypes...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+			if n := len(sess.read().nodes); n != 0 {
+				t.Errorf("the failed restore saved %v nodes", n)
+			}
+		})
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		snapshot string
+		want     string
+	}{
+		{"empty", "", "failed to read snapshot"},
+		{"not json", "nodes", "failed to read snapshot"},
+		{"other version", `{"version": 2, "nodes": []}`, "snapshot version 2 is not supported, expected 1"},
+		{"ndjson other version", `{"version": 2}`, "snapshot version 2 is not supported, expected 1"},
+		{"ndjson empty line", "{\"version\": 1}\n{\"node\": {\"type\": \"Student\", \"uuid\": \"s\"}}\n{}\n", "line 3 of snapshot is neither a node nor a relationship"},
+		{"ndjson broken line", "{\"version\": 1}\n{\"node\": \n", "failed to read line 2 of snapshot"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(strings.NewReader(tt.snapshot))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreGraphValidates(t *testing.T) {
+	snapshot, err := ReadSnapshot(strings.NewReader(`{"version": 1, "nodes": [
+		{"type": "Student", "uuid": "s"},
+		{"type": "CourseOffering", "uuid": "o", "properties": {"name": "cs341-0 Fall 2026", "section": "0", "capacity": 30}},
+		{"type": "Room", "uuid": "r", "properties": {"name": "Gates 101"}}
+	], "relationships": [
+		{"type": "ENROLLED", "start": "s", "end": "o", "edge": "Enrollment", "uuid": "e", "properties": {"status": "expelled"}},
+		{"type": "MEETS_IN", "start": "o", "end": "r", "edge": "Meeting", "uuid": "m", "properties": {"day": "monday", "start_time": "10:00", "end_time": "09:00"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := newTestSession(t)
+	err = RestoreGraph(sess, snapshot, registeredTypes...)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+
+	var got []string
+	for _, fieldErr := range errs {
+		got = append(got, fieldErr.Error())
+	}
+	want := []string{
+		"Student[s].Name: required",
+		`Enrollment[e].Status: invalid enrollment status "expelled", expected one of enrolled waitlisted dropped completed withdrawn`,
+		"Enrollment[e].EnrolledDate: required",
+		"Meeting[m].EndTime: invalid meeting time, monday 10:00-09:00 does not end after it starts",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if n := len(sess.read().nodes); n != 0 {
+		t.Errorf("the invalid snapshot saved %v nodes", n)
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mindstand/gogm"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrInvalid is matched by the ValidationErrors returned when a node or edge fails its Validate
+	ErrInvalid = errors.New("invalid")
+	// ErrRequired is returned for fields that must not be empty
+	ErrRequired = errors.New("required")
+	// ErrInvalidStatus is returned for enrollments with a status that is not one of the Enrollment* statuses
+	ErrInvalidStatus = errors.New("invalid enrollment status")
+)
+
+// enrollmentStatuses are the statuses an enrollment can have, empty counts as enrolled
+var enrollmentStatuses = []string{EnrollmentEnrolled, EnrollmentWaitlisted, EnrollmentDropped, EnrollmentCompleted, EnrollmentWithdrawn}
+
+// Validator is implemented by the nodes and edges in models.go, SaveValidated calls Validate
+// on everything it is about to save. Validate only checks properties and the ends of edges,
+// relationships of nodes at the edge of the save depth are not loaded
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a problem with one field, Path leads to it from the saved node like Student.Enrollments[0].EnrolledDate
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every invalid field found before a save, nothing is saved when there are any
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return fmt.Sprintf("%v invalid fields:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// Is makes errors.Is(err, ErrInvalid) true
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// SaveValidated validates node and everything SaveDepth would write at depth, then saves it.
+// the repositories save through it so invalid nodes never reach neo4j
+func SaveValidated(sess gogm.ISession, node interface{}, depth int) error {
+	err := ValidateGraph(node, depth)
+	if err != nil {
+		return err
+	}
+
+	return sess.SaveDepth(node, depth)
+}
+
+// ValidateGraph runs Validate on node and every node and edge reachable from it within depth relationships,
+// the nodes SaveDepth writes. all failures are returned at once as ValidationErrors with the path to each field
+func ValidateGraph(node interface{}, depth int) error {
+	root := reflect.ValueOf(node)
+	if root.Kind() != reflect.Ptr || root.IsNil() {
+		return fmt.Errorf("can not validate %T, expected a pointer to a node", node)
+	}
+
+	schema, err := schemaFor(root.Type())
+	if err != nil {
+		return err
+	}
+
+	type queued struct {
+		val   reflect.Value
+		path  string
+		depth int
+	}
+
+	var errs ValidationErrors
+	visited := map[uintptr]bool{}
+	validate := func(val reflect.Value, path string) {
+		if visited[val.Pointer()] {
+			return
+		}
+		visited[val.Pointer()] = true
+
+		validator, ok := val.Interface().(Validator)
+		if !ok {
+			return
+		}
+
+		err := validator.Validate()
+		var fieldErrs ValidationErrors
+		switch {
+		case err == nil:
+		case errors.As(err, &fieldErrs):
+			for _, fieldErr := range fieldErrs {
+				errs = append(errs, &FieldError{Path: path + "." + fieldErr.Path, Err: fieldErr.Err})
+			}
+		default:
+			errs = append(errs, &FieldError{Path: path, Err: err})
+		}
+	}
+
+	// walk the graph like SaveDepth, nodes at depth are written but their relationships are not
+	validate(root, schema.Label)
+	queue := []queued{{val: root, path: schema.Label}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if cur.depth >= depth {
+			continue
+		}
+
+		schema, err := schemaFor(cur.val.Type())
+		if err != nil {
+			return err
+		}
+
+		for _, field := range schema.Relationships {
+			for _, target := range indexedTargets(cur.val.Elem(), field, cur.path) {
+				other, path := target.val, target.path
+				if field.Edge != nil {
+					validate(target.val, target.path)
+
+					edge := target.val.Interface().(gogm.IEdge)
+					if field.Direction == directionIncoming {
+						other, path = reflect.ValueOf(edge.GetStartNode()), path+".Start"
+					} else {
+						other, path = reflect.ValueOf(edge.GetEndNode()), path+".End"
+					}
+
+					// edges without the node are reported by their own Validate
+					if !other.IsValid() || other.IsNil() {
+						continue
+					}
+				}
+
+				if visited[other.Pointer()] {
+					continue
+				}
+
+				validate(other, path)
+				queue = append(queue, queued{val: other, path: path, depth: cur.depth + 1})
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// indexedTarget is a node or edge on a relationship field with the path to it
+type indexedTarget struct {
+	val  reflect.Value
+	path string
+}
+
+// indexedTargets returns the nodes or edges on field of node with their paths, like Enrollments[2]
+func indexedTargets(node reflect.Value, field *relationshipField, path string) []indexedTarget {
+	fieldVal := node.FieldByIndex(field.Index)
+	path += "." + field.FieldName
+
+	if !field.Many {
+		if fieldVal.IsNil() {
+			return nil
+		}
+		return []indexedTarget{{val: fieldVal, path: path}}
+	}
+
+	var targets []indexedTarget
+	for i := 0; i < fieldVal.Len(); i++ {
+		if !fieldVal.Index(i).IsNil() {
+			targets = append(targets, indexedTarget{val: fieldVal.Index(i), path: fmt.Sprintf("%s[%v]", path, i)})
+		}
+	}
+
+	return targets
+}
+
+// fieldChecks collects the problems of one node or edge in its Validate
+type fieldChecks ValidationErrors
+
+// check records err for field, nil errors are ignored
+func (c *fieldChecks) check(field string, err error) {
+	if err != nil {
+		*c = append(*c, &FieldError{Path: field, Err: err})
+	}
+}
+
+// err returns the recorded problems as ValidationErrors, or nil if there are none
+func (c fieldChecks) err() error {
+	if len(c) == 0 {
+		return nil
+	}
+
+	return ValidationErrors(c)
+}
+
+// requiredName checks that a name is not blank
+func requiredName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrRequired
+	}
+
+	return nil
+}
+
+// Validate checks the department has a name
+func (d *Department) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(d.Name))
+	return checks.err()
+}
+
+// Validate checks the subject has a name
+func (s *Subject) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(s.Name))
+	return checks.err()
+}
+
+// Validate checks the teacher has a name, that it is unique is enforced by neo4j
+func (t *Teacher) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(t.Name))
+	return checks.err()
+}
+
+// Validate checks the course has a name
+func (c *Course) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(c.Name))
+	return checks.err()
+}
+
+// Validate checks the term has a name, that it is unique is enforced by neo4j
+func (t *Term) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(t.Name))
+	return checks.err()
+}
+
+// Validate checks the offering has a name, a section and no negative capacity
+func (o *CourseOffering) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(o.Name))
+	checks.check("Section", requiredName(o.Section))
+	if o.Capacity < 0 {
+		checks.check("Capacity", fmt.Errorf("%w, found %v", ErrInvalidCapacity, o.Capacity))
+	}
+	return checks.err()
+}
+
+// Validate checks the student has a name, that it is unique is enforced by neo4j
+func (s *Student) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(s.Name))
+	return checks.err()
+}
+
+// Validate checks the room has a name, that it is unique is enforced by neo4j
+func (r *Room) Validate() error {
+	var checks fieldChecks
+	checks.check("Name", requiredName(r.Name))
+	return checks.err()
+}
+
+// Validate checks the enrollment connects a student and an offering, has a known status with the dates
+// that status needs and a valid letter grade if it is graded
+func (e *Enrollment) Validate() error {
+	var checks fieldChecks
+	if e.Start == nil {
+		checks.check("Start", ErrRequired)
+	}
+	if e.End == nil {
+		checks.check("End", ErrRequired)
+	}
+
+	known := false
+	for _, status := range enrollmentStatuses {
+		known = known || e.CurrentStatus() == status
+	}
+	if !known {
+		checks.check("Status", fmt.Errorf("%w %q, expected one of %s", ErrInvalidStatus, e.Status, strings.Join(enrollmentStatuses, " ")))
+	}
+
+	// waitlisted students are only given an enrolled date once they get a seat
+	switch {
+	case e.Waitlisted() && e.WaitlistedDate.IsZero():
+		checks.check("WaitlistedDate", ErrRequired)
+	case !e.Waitlisted() && e.EnrolledDate.IsZero() && e.WaitlistedDate.IsZero():
+		checks.check("EnrolledDate", ErrRequired)
+	}
+
+	if (e.Status == EnrollmentDropped || e.Status == EnrollmentWithdrawn) && e.DroppedDate.IsZero() {
+		checks.check("DroppedDate", ErrRequired)
+	}
+
+	if e.FinalGrade != "" {
+		if grade, err := ParseGrade(e.FinalGrade); err != nil {
+			checks.check("FinalGrade", err)
+		} else if grade != e.FinalGrade {
+			checks.check("FinalGrade", fmt.Errorf("%w %q, write it as %s", ErrInvalidGrade, e.FinalGrade, grade))
+		}
+
+		if e.GradedDate.IsZero() {
+			checks.check("GradedDate", ErrRequired)
+		}
+	}
+
+	return checks.err()
+}
+
+// Validate checks the meeting connects an offering and a room on a weekday and ends after it starts
+func (m *Meeting) Validate() error {
+	var checks fieldChecks
+	if m.Start == nil {
+		checks.check("Start", ErrRequired)
+	}
+	if m.End == nil {
+		checks.check("End", ErrRequired)
+	}
+	checks.check("Day", checkWeekday(m.Day))
+
+	start, startErr := clockMinute(m.StartTime)
+	checks.check("StartTime", startErr)
+
+	end, endErr := clockMinute(m.EndTime)
+	checks.check("EndTime", endErr)
+
+	if startErr == nil && endErr == nil && end <= start {
+		checks.check("EndTime", fmt.Errorf("%w, %s does not end after it starts", ErrInvalidMeeting, m))
+	}
+
+	return checks.err()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newValidationGraph links a student to an offering of a course meeting in a room, the nodes are valid until changed
+func newValidationGraph(t *testing.T) (*Student, *Enrollment, *CourseOffering, *Meeting) {
+	t.Helper()
+
+	course, term := &Course{Name: "cs341"}, &Term{Name: exampleTerm}
+	offering, err := NewCourseOffering(course, term, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meeting := &Meeting{Day: "monday", StartTime: "09:00", EndTime: "10:30"}
+	err = offering.LinkToRoomOnFieldMeetings(&Room{Name: "Gates 101"}, meeting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	student, enrollment := &Student{Name: "eric"}, NewEnrollment(testDate)
+	err = student.LinkToCourseOfferingOnFieldEnrollments(offering, enrollment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return student, enrollment, offering, meeting
+}
+
+func TestValidateGraph(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		// change breaks the graph and returns the node to validate
+		change func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{}
+		depth  int
+		want   []string
+	}{
+		{
+			name: "valid",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				return student
+			},
+			depth: 3,
+		},
+		{
+			name: "the node itself",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				student.Name = " "
+				return student
+			},
+			want: []string{"Student.Name: required"},
+		},
+		{
+			name: "edge and its end",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				enrollment.Status, enrollment.FinalGrade = EnrollmentDropped, "b+"
+				offering.Section, offering.Capacity = "", -1
+				return student
+			},
+			depth: 1,
+			want: []string{
+				"Student.Enrollments[0].DroppedDate: required",
+				`Student.Enrollments[0].FinalGrade: invalid letter grade "b+", write it as B+`,
+				"Student.Enrollments[0].GradedDate: required",
+				"Student.Enrollments[0].End.Section: required",
+				"Student.Enrollments[0].End.Capacity: capacity can not be negative, found -1",
+			},
+		},
+		{
+			name: "beyond the depth",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				offering.Course.Name = ""
+				meeting.Day = "someday"
+				return student
+			},
+			depth: 1,
+		},
+		{
+			name: "within the depth",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				offering.Course.Name = ""
+				meeting.Day = "someday"
+				return student
+			},
+			depth: 2,
+			want: []string{
+				"Student.Enrollments[0].End.Course.Name: required",
+				`Student.Enrollments[0].End.Meetings[0].Day: invalid meeting time, "someday" is not one of monday tuesday wednesday thursday friday saturday sunday`,
+			},
+		},
+		{
+			name: "incoming edges lead to their start",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				student.Name = ""
+				meeting.EndTime = "08:00"
+				return offering
+			},
+			depth: 1,
+			want: []string{
+				"CourseOffering.Enrollments[0].Start.Name: required",
+				"CourseOffering.Meetings[0].EndTime: invalid meeting time, monday 09:00-08:00 does not end after it starts",
+			},
+		},
+		{
+			name: "edge without its node",
+			change: func(student *Student, enrollment *Enrollment, offering *CourseOffering, meeting *Meeting) interface{} {
+				enrollment.End = nil
+				return student
+			},
+			depth: 1,
+			want:  []string{"Student.Enrollments[0].End: required"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			node := tt.change(newValidationGraph(t))
+
+			err := ValidateGraph(node, tt.depth)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || !errors.Is(err, ErrInvalid) {
+				t.Fatalf("got %v, want ValidationErrors", err)
+			}
+
+			var got []string
+			for _, fieldErr := range errs {
+				got = append(got, fieldErr.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	err := ValidateGraph(Student{Name: "eric"}, 0)
+	if err == nil {
+		t.Error("validated a node that is not a pointer")
+	}
+}
+
+func TestSaveValidated(t *testing.T) {
+	sess := newTestSession(t)
+	student, enrollment, _, _ := newValidationGraph(t)
+	enrollment.Status = "expelled"
+
+	err := SaveValidated(sess, student, 2)
+	var errs ValidationErrors
+	if !errors.Is(err, ErrInvalid) || !errors.As(err, &errs) || errs[0].Path != "Student.Enrollments[0].Status" || !errors.Is(errs[0], ErrInvalidStatus) {
+		t.Errorf("got %v, want an invalid Student.Enrollments[0].Status", err)
+	}
+	if n := len(sess.read().nodes); n != 0 {
+		t.Errorf("the invalid graph saved %v nodes", n)
+	}
+
+	enrollment.Status = EnrollmentEnrolled
+	err = SaveValidated(sess, student, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sess.read().nodes); n != 5 {
+		t.Errorf("saved %v nodes, want 5", n)
+	}
+
+	// the repositories save through SaveValidated
+	err = NewStudentRepo(sess).Save(&Student{})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("saving a student without a name got %v, want ErrInvalid", err)
+	}
+}